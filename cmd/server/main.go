@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "go-backend/docs" // 导入生成的swagger文档
+	"go-backend/internal/algorithm"
+	"go-backend/internal/algorithm/define"
 	"go-backend/internal/api"
 	"go-backend/internal/config"
 	"go-backend/pkg/database"
@@ -32,18 +42,66 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
-// @description 请在此输入 'Bearer {token}' 格式的 JWT token
+// @description 请在此输入 'Bearer {token}' 格式的 JWT token 或 API 密钥 (也可通过 X-API-Key 头传递 API 密钥)
 
 func main() {
+	restorePath := flag.String("restore", "", "启动时从指定的系统快照文件恢复")
+	flag.Parse()
+
 	// 加载配置文件
 	cfg := config.InitConfig()
 
 	// 初始化 JWT 密钥
 	utils.InitJWTSecret(cfg.JWT.Secret)
+	accessTTL, err := time.ParseDuration(cfg.JWT.Expiration)
+	if err != nil {
+		log.Fatalf("访问令牌有效期无效: %v", err)
+	}
+	refreshTTL, err := time.ParseDuration(cfg.JWT.RefreshExpiration)
+	if err != nil {
+		log.Fatalf("刷新令牌有效期无效: %v", err)
+	}
+	if err := utils.SetTokenExpiration(accessTTL, refreshTTL); err != nil {
+		log.Fatalf("令牌有效期配置错误: %v", err)
+	}
+
+	// 设置Lyapunov默认参数 (需在创建算法系统之前)
+	if err := algorithm.SetDefaultLyapunovParams(lyapunovParams(cfg.Lyapunov)); err != nil {
+		log.Fatalf("Lyapunov参数无效: %v", err)
+	}
+
+	// 设置状态历史容量
+	algorithm.SetDefaultHistoryCapacity(cfg.History.Capacity)
+
+	// 设置仿真加速比
+	if err := algorithm.SetDefaultSpeedUp(cfg.Simulation.SpeedUp); err != nil {
+		log.Fatalf("仿真加速比无效: %v", err)
+	}
+
+	// 设置每个账号的任务配额
+	if err := algorithm.SetDefaultTaskQuota(define.TaskQuota{
+		MaxActiveTasks: cfg.Quota.MaxActiveTasks,
+		MaxActiveData:  cfg.Quota.MaxActiveData,
+	}); err != nil {
+		log.Fatalf("任务配额无效: %v", err)
+	}
 
 	// 初始化数据库连接
 	database.InitDB("./data.db")
 
+	// 从快照恢复算法系统 (需在设置路由之前)
+	if *restorePath != "" {
+		snap, err := algorithm.LoadSnapshot(*restorePath)
+		if err != nil {
+			log.Fatalf("读取快照失败: %v", err)
+		}
+		system, err := algorithm.RestoreSystem(snap)
+		if err != nil {
+			log.Fatalf("恢复快照失败: %v", err)
+		}
+		algorithm.ReplaceSystemInstance(system)
+	}
+
 	// 设置Gin模式
 	gin.SetMode(gin.ReleaseMode)
 
@@ -51,7 +109,7 @@ func main() {
 	router := gin.Default()
 
 	// 设置路由
-	api.SetupRoutes(router)
+	shutdown := api.SetupRoutes(router, cfg)
 
 	// 添加Swagger文档路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -63,9 +121,53 @@ func main() {
 	log.Println("Swagger文档地址: http://localhost:" + cfg.Port + "/swagger/index.html")
 
 	// 启动服务器
-	log.Printf("启动服务器，监听端口 :%s\n", cfg.Port)
-	if err := router.Run(":" + cfg.Port); err != nil {
-		log.Fatalf("无法启动服务器: %s\n", err)
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: router}
+	go func() {
+		log.Printf("启动服务器，监听端口 :%s\n", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("无法启动服务器: %s\n", err)
+		}
+	}()
+
+	// 收到中断信号后停止接收新请求，等待进行中的请求和后台任务完成
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("正在关闭服务器...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  等待进行中的请求超时: %v", err)
 	}
+	shutdown(ctx)
+	log.Println("✓ 服务器已关闭")
+}
 
+// 关闭服务器时等待进行中的请求和后台任务的最长时间
+const shutdownTimeout = 10 * time.Second
+
+// lyapunovParams 用配置文件中设置的字段覆盖默认Lyapunov参数
+func lyapunovParams(cfg config.LyapunovConfig) define.LyapunovParams {
+	params := algorithm.GetDefaultLyapunovParams()
+	setFloat := func(dst *float64, src *float64) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setFloat(&params.V, cfg.V)
+	setFloat(&params.Alpha, cfg.Alpha)
+	setFloat(&params.Beta, cfg.Beta)
+	setFloat(&params.Gamma, cfg.Gamma)
+	setFloat(&params.Shrink, cfg.Shrink)
+	setFloat(&params.CommEnergyBudget, cfg.CommEnergyBudget)
+	setFloat(&params.UserEnergyBudget, cfg.UserEnergyBudget)
+	setFloat(&params.EnergyWeight, cfg.EnergyWeight)
+	if cfg.Iters != nil {
+		params.Iters = *cfg.Iters
+	}
+	if cfg.Solver != nil {
+		params.Solver = define.SolverType(*cfg.Solver)
+	}
+	return params
 }
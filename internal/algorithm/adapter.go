@@ -16,21 +16,27 @@ func NewSystemAdapter(sys *System) *SystemAdapter {
 	return &SystemAdapter{System: sys}
 }
 
-// SubmitBatchTasks 适配批量提交任务 (兼容旧API)
-func (sa *SystemAdapter) SubmitBatchTasks(requests []define.TaskBase) ([]*define.TaskWithMetrics, error) {
-	tasks := make([]*define.TaskWithMetrics, 0, len(requests))
-
-	for _, req := range requests {
-		task, err := sa.System.SubmitTask(req.UserID, req.DataSize, req.Type)
-		if err != nil {
-			return nil, err
+// SubmitBatchTasks 适配批量提交任务 (兼容旧API)，owner为提交账号
+// 整批任务一起校验和检查配额，任一任务无效或超出配额时不提交任何任务
+func (sa *SystemAdapter) SubmitBatchTasks(owner define.TaskOwner, requests []define.TaskBase) ([]*define.TaskWithMetrics, error) {
+	normalized := make([]define.TaskBase, len(requests))
+	for i, req := range requests {
+		if req.Priority == 0 {
+			req.Priority = define.PriorityNormal
 		}
+		normalized[i] = req
+	}
 
-		// 转换Task为TaskWithMetrics (兼容层)
-		taskWithMetrics := taskToTaskWithMetrics(task)
-		tasks = append(tasks, taskWithMetrics)
+	submitted, err := sa.System.SubmitTasksAs(owner, normalized)
+	if err != nil {
+		return nil, err
 	}
 
+	// 转换Task为TaskWithMetrics (兼容层)
+	tasks := make([]*define.TaskWithMetrics, 0, len(submitted))
+	for _, task := range submitted {
+		tasks = append(tasks, taskToTaskWithMetrics(task))
+	}
 	return tasks, nil
 }
 
@@ -40,14 +46,18 @@ func (sa *SystemAdapter) StopAlgorithm() {
 }
 
 // ClearHistory 清除历史 (兼容旧API)
+// 时隙计数不重置: 任务的等待、超时和饥饿计时都以提交时隙为基准
 func (sa *SystemAdapter) ClearHistory() {
+	sa.System.mutex.Lock()
+	defer sa.System.mutex.Unlock()
+
 	sa.System.AssignmentManager.Clear()
-	sa.System.TimeSlot = 0
+	sa.System.History.Clear()
 }
 
 // GetTasksWithPage 分页获取任务 (兼容旧API)
-func (sa *SystemAdapter) GetTasksWithPage(offset, limit int, userID *uint, status *define.TaskStatus) ([]*define.TaskWithMetrics, int64) {
-	tasks, total := sa.System.TaskManager.GetTasksWithPage(offset, limit, userID, status)
+func (sa *SystemAdapter) GetTasksWithPage(offset, limit int, userID *uint, status *define.TaskStatus, scope *define.TaskScope) ([]*define.TaskWithMetrics, int64) {
+	tasks, total := sa.System.TaskManager.GetTasksWithPage(offset, limit, userID, status, scope)
 
 	// 转换Task为TaskWithMetrics
 	tasksWithMetrics := make([]*define.TaskWithMetrics, len(tasks))
@@ -112,7 +122,7 @@ func (sa *SystemAdapter) fillTaskAssignmentInfo(task *define.TaskWithMetrics) {
 
 // taskToTaskWithMetrics 转换Task为TaskWithMetrics
 func taskToTaskWithMetrics(t *define.Task) *define.TaskWithMetrics {
-	return &define.TaskWithMetrics{
+	result := &define.TaskWithMetrics{
 		TaskBase: define.TaskBase{
 			ID:        t.ID,
 			Name:      t.Name,
@@ -123,9 +133,20 @@ func taskToTaskWithMetrics(t *define.Task) *define.TaskWithMetrics {
 			Status:    t.Status,
 			CreatedAt: t.CreatedAt,
 		},
+		OwnerID:       t.OwnerID,
 		ScheduledTime: t.ScheduledTime,
 		CompleteTime:  t.CompleteTime,
+		CreatedSlot:   t.CreatedSlot,
+		ScheduledSlot: t.ScheduledSlot,
+		CompletedSlot: t.CompletedSlot,
+	}
+	if t.ScheduledSlot != 0 {
+		result.WaitTime = t.GetWaitTime(t.ScheduledSlot).Seconds()
+	}
+	if t.CompletedSlot != 0 {
+		result.CompletionTime = t.GetElapsedTime(t.CompletedSlot).Seconds()
 	}
+	return result
 }
 
 // computeMetrics 根据Assignment计算性能指标
@@ -173,20 +194,36 @@ func (sa *SystemAdapter) CancelTask(taskID string) error {
 	return sa.System.CancelTask(taskID)
 }
 
-// 全局System实例
+// 全局System实例 (可通过快照恢复替换)
 var (
-	globalSystem     *System
-	globalSystemOnce sync.Once
+	globalSystem      *System
+	globalSystemMutex sync.Mutex
 )
 
-// GetSystemInstance 获取全局System实例
+// GetSystemInstance 获取全局System实例 (首次调用时从数据库创建)
 func GetSystemInstance() *System {
-	globalSystemOnce.Do(func() {
+	globalSystemMutex.Lock()
+	defer globalSystemMutex.Unlock()
+	if globalSystem == nil {
 		globalSystem = NewSystem()
-	})
+	}
 	return globalSystem
 }
 
+// ReplaceSystemInstance 替换全局System实例 (用于快照恢复)
+// 旧实例的调度循环会被停止，告警监控器、事件订阅和历史持久化转移到新实例后再发布新实例，
+// 获取全局实例的请求不会看到尚未接管这些服务的新实例
+func ReplaceSystemInstance(sys *System) {
+	globalSystemMutex.Lock()
+	defer globalSystemMutex.Unlock()
+
+	if old := globalSystem; old != nil && old != sys {
+		old.Stop()
+		sys.adoptServices(old)
+	}
+	globalSystem = sys
+}
+
 // GetAdaptedSystem 获取适配后的系统 (供API handler使用)
 func GetAdaptedSystem() *SystemAdapter {
 	return NewSystemAdapter(GetSystemInstance())
@@ -6,222 +6,86 @@ import (
 	"go-backend/internal/models"
 	"go-backend/internal/service"
 	"log"
-	"sync"
-	"time"
 )
 
-// AlarmThresholds 告警阈值配置
-type AlarmThresholds struct {
-	// 延迟阈值 (秒)
-	MaxDelay float64
-	// 能耗阈值 (焦耳)
-	MaxEnergy float64
-	// 系统负载阈值
-	MaxLoad float64
-	// 队列积压阈值 (bits)
-	MaxQueueData float64
-	// 传输速率下限 (bits/s)
-	MinTransferSpeed float64
-}
-
-// DefaultAlarmThresholds 默认告警阈值
-var DefaultAlarmThresholds = AlarmThresholds{
-	MaxDelay:         10.0,  // 10秒
-	MaxEnergy:        100.0, // 100焦耳
-	MaxLoad:          5.0,   // 负载5倍
-	MaxQueueData:     1e8,   // 100MB
-	MinTransferSpeed: 1e5,   // 100 Kbps
-}
-
 // AlarmMonitor 告警监控器
+// 告警按指纹 (规则+对象、失败的任务) 去重，状态持久化在告警记录中，重启后继续跟踪
 type AlarmMonitor struct {
 	alarmService *service.AlarmService
-	thresholds   AlarmThresholds
-	mutex        sync.RWMutex
-
-	// 告警去重: 相同类型的告警在一定时间内只产生一次
-	lastAlarmTime map[string]time.Time
-	cooldown      time.Duration // 告警冷却时间
+	rules        AlarmRuleSource
+	silences     AlarmSilenceSource
+	evaluator    *ruleEvaluator
+}
 
-	// 活跃告警记录: 用于自动解决
-	activeAlarms map[string]uint // alarmKey -> alarmID
+// AlarmSilenceSource 告警静默规则来源 (由 service.AlarmSilenceService 提供)
+type AlarmSilenceSource interface {
+	MatchSilence(eventType models.AlarmEvent, name, target string) *models.AlarmSilence
 }
 
-// NewAlarmMonitor 创建告警监控器
-func NewAlarmMonitor(alarmService *service.AlarmService) *AlarmMonitor {
+// NewAlarmMonitor 创建告警监控器，每个时隙按 rules 中启用的告警规则检查系统状态，
+// 匹配 silences 中生效的静默规则的告警被抑制或标记为静默 (silences 可为nil)
+func NewAlarmMonitor(alarmService *service.AlarmService, rules AlarmRuleSource, silences AlarmSilenceSource) *AlarmMonitor {
 	return &AlarmMonitor{
-		alarmService:  alarmService,
-		thresholds:    DefaultAlarmThresholds,
-		lastAlarmTime: make(map[string]time.Time),
-		activeAlarms:  make(map[string]uint),
-		cooldown:      5 * time.Minute, // 同类型告警5分钟内只触发一次
+		alarmService: alarmService,
+		rules:        rules,
+		silences:     silences,
+		evaluator:    newRuleEvaluator(),
 	}
 }
 
-// SetThresholds 设置自定义阈值
-func (m *AlarmMonitor) SetThresholds(thresholds AlarmThresholds) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	m.thresholds = thresholds
-}
-
-// CheckSystemState 检查系统状态并产生告警
+// CheckSystemState 按告警规则检查系统状态并产生告警
+// 规则连续满足条件达到持续时隙数时产生告警，条件恢复后自动解决 (所有规则和对象一致处理)
 func (m *AlarmMonitor) CheckSystemState(state *define.StateMetrics, tasks []*define.Task) {
-	if state == nil {
+	if state == nil || m.rules == nil {
 		return
 	}
 
-	m.mutex.RLock()
-	thresholds := m.thresholds
-	m.mutex.RUnlock()
-
-	// 1. 检查系统延迟
-	delayKey := "performance_delay"
-	if state.TotalDelay > thresholds.MaxDelay {
-		m.createAlarm(
-			delayKey,
-			"系统延迟过高",
-			models.AlarmEventPerformance,
-			fmt.Sprintf("系统总延迟 %.2f 秒超过阈值 %.2f 秒 (传输延迟: %.2f秒, 计算延迟: %.2f秒)",
-				state.TotalDelay, thresholds.MaxDelay, state.TransferDelay, state.ComputeDelay),
-		)
-	} else {
-		// 延迟恢复正常，自动解决告警
-		m.autoResolveAlarm(delayKey)
-	}
-
-	// 2. 检查系统能耗
-	energyKey := "performance_energy"
-	if state.TotalEnergy > thresholds.MaxEnergy {
-		m.createAlarm(
-			energyKey,
-			"系统能耗过高",
-			models.AlarmEventPerformance,
-			fmt.Sprintf("系统总能耗 %.2f 焦耳超过阈值 %.2f 焦耳 (传输能耗: %.2f J, 计算能耗: %.2f J)",
-				state.TotalEnergy, thresholds.MaxEnergy, state.TransferEnergy, state.ComputeEnergy),
-		)
-	} else {
-		m.autoResolveAlarm(energyKey)
+	alerts, holding := m.evaluator.evaluate(m.rules.EnabledRules(), state, tasks)
+	for _, alert := range alerts {
+		m.raiseAlarm(alert.key, alert.target, alert.name, alert.rule.EventType, alert.rule.Severity, alert.description)
 	}
-
-	// 3. 检查系统负载
-	loadKey := "performance_load"
-	if state.Load > thresholds.MaxLoad {
-		m.createAlarm(
-			loadKey,
-			"系统负载过高",
-			models.AlarmEventPerformance,
-			fmt.Sprintf("系统负载 %.2f 倍超过阈值 %.2f 倍，可能导致任务处理缓慢",
-				state.Load, thresholds.MaxLoad),
-		)
-	} else {
-		m.autoResolveAlarm(loadKey)
-	}
-
-	// 4. 检查队列积压
-	queueKey := "network_queue"
-	if state.TotalQueue > thresholds.MaxQueueData {
-		m.createAlarm(
-			queueKey,
-			"网络队列积压严重",
-			models.AlarmEventNetwork,
-			fmt.Sprintf("总队列数据量 %.2f MB超过阈值 %.2f MB，通信设备处理能力不足",
-				state.TotalQueue/1e6, thresholds.MaxQueueData/1e6),
-		)
-	} else {
-		m.autoResolveAlarm(queueKey)
-	}
-
-	// 5. 检查单个通信设备队列
-	for commID, queueData := range state.CommQueues {
-		if queueData > thresholds.MaxQueueData*0.5 {
-			m.createAlarm(
-				fmt.Sprintf("network_queue_comm_%s", commID),
-				fmt.Sprintf("通信设备 %s 队列积压", commID),
-				models.AlarmEventNetwork,
-				fmt.Sprintf("通信设备 %s 队列数据量 %.2f MB，可能存在传输瓶颈",
-					commID, queueData/1e6),
-			)
-		}
+	if err := m.alarmService.ClearAlarms(ruleAlarmPrefix, holding); err != nil {
+		log.Printf("[AlarmMonitor] 检查告警恢复失败: %v", err)
 	}
 }
 
-// CheckTaskFailures 检查任务失败
+// CheckTaskFailures 检查任务失败 (失败是一次性事件，告警需手动解决)
 func (m *AlarmMonitor) CheckTaskFailures(task *define.Task) {
 	if task.Status == define.TaskFailed {
-		m.createAlarm(
+		m.raiseAlarm(
 			fmt.Sprintf("task_failed_%s", task.ID),
+			task.ID,
 			fmt.Sprintf("任务失败: %s", task.Name),
 			models.AlarmEventSystem,
+			models.AlarmSeverityWarning,
 			fmt.Sprintf("任务 %s (用户ID: %d) 执行失败，数据大小: %.2f MB",
 				task.Name, task.UserID, task.DataSize/1e6),
 		)
 	}
 }
 
-// createAlarm 创建告警（带去重）
-func (m *AlarmMonitor) createAlarm(alarmKey, name string, eventType models.AlarmEvent, description string) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	// 检查冷却时间
-	if lastTime, exists := m.lastAlarmTime[alarmKey]; exists {
-		if time.Since(lastTime) < m.cooldown {
-			// 在冷却期内，跳过
-			return
-		}
-	}
-
-	// 创建告警
-	alarm := &models.Alarm{
+// raiseAlarm 按指纹触发告警 (已有未解决的同指纹告警时只累加发生次数)
+// 匹配抑制类静默规则时不产生告警 (已存在的告警不受影响，条件恢复后照常解决)
+func (m *AlarmMonitor) raiseAlarm(fingerprint, target, name string, eventType models.AlarmEvent, severity models.AlarmSeverity, description string) {
+	signal := service.AlarmSignal{
+		Fingerprint: fingerprint,
 		Name:        name,
 		EventType:   eventType,
-		Status:      models.AlarmStatusActive,
+		Severity:    severity,
 		Description: description,
+		Target:      target,
 	}
-
-	if err := m.alarmService.CreateAlarm(alarm); err != nil {
-		log.Printf("[AlarmMonitor] 创建告警失败: %v", err)
-		return
-	}
-
-	// 记录告警时间和ID
-	m.lastAlarmTime[alarmKey] = time.Now()
-	m.activeAlarms[alarmKey] = alarm.ID
-	log.Printf("[AlarmMonitor] 告警已创建: ID=%d, %s - %s", alarm.ID, name, description)
-}
-
-// autoResolveAlarm 自动解决告警（当状态恢复正常时）
-func (m *AlarmMonitor) autoResolveAlarm(alarmKey string) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	// 检查是否有对应的活跃告警
-	alarmID, exists := m.activeAlarms[alarmKey]
-	if !exists {
-		return
-	}
-
-	// 尝试解决告警
-	if err := m.alarmService.ResolveAlarm(alarmID); err != nil {
-		log.Printf("[AlarmMonitor] 自动解决告警失败: ID=%d, %v", alarmID, err)
-		return
+	if m.silences != nil {
+		if silence := m.silences.MatchSilence(eventType, name, target); silence != nil {
+			if silence.Action == models.AlarmSilenceSuppress {
+				return
+			}
+			signal.SilenceID = &silence.ID
+		}
 	}
 
-	// 从活跃告警中移除
-	delete(m.activeAlarms, alarmKey)
-	log.Printf("[AlarmMonitor] 告警已自动解决: ID=%d, key=%s", alarmID, alarmKey)
-}
-
-// CleanupOldAlarms 清理旧的告警时间记录（定期清理，避免内存泄漏）
-func (m *AlarmMonitor) CleanupOldAlarms() {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	now := time.Now()
-	for key, lastTime := range m.lastAlarmTime {
-		if now.Sub(lastTime) > m.cooldown*2 {
-			delete(m.lastAlarmTime, key)
-		}
+	_, err := m.alarmService.RaiseAlarm(signal)
+	if err != nil {
+		log.Printf("[AlarmMonitor] 创建告警失败: %v", err)
 	}
 }
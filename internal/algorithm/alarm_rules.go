@@ -0,0 +1,207 @@
+package algorithm
+
+import (
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// AlarmRuleSource 告警规则来源 (由 service.AlarmRuleService 提供，规则修改后下一个时隙生效)
+type AlarmRuleSource interface {
+	EnabledRules() []models.AlarmRule
+}
+
+// ruleSample 指标在某个对象上的取值 (系统指标的对象为空)
+type ruleSample struct {
+	target string
+	value  float64
+}
+
+// ruleAlert 连续满足条件达到持续时隙数的规则对象
+type ruleAlert struct {
+	key         string
+	target      string
+	name        string
+	description string
+	rule        models.AlarmRule
+}
+
+// ruleAlarmPrefix 规则告警的指纹前缀
+const ruleAlarmPrefix = "rule_"
+
+// ruleEvaluator 告警规则求值器
+// 记录每个规则/对象连续满足条件的时隙数
+type ruleEvaluator struct {
+	mutex   sync.Mutex
+	streaks map[string]int // alarmKey -> 连续满足条件的时隙数
+}
+
+func newRuleEvaluator() *ruleEvaluator {
+	return &ruleEvaluator{
+		streaks: make(map[string]int),
+	}
+}
+
+// evaluate 对本时隙的状态求值所有规则
+// 返回连续满足条件达到持续时隙数的告警，以及本时隙条件满足的所有告警键 (含未达到持续时隙数的)；
+// 不在后者中的告警键 (条件不再满足、规则被删除/禁用或对象消失) 视为恢复
+func (e *ruleEvaluator) evaluate(rules []models.AlarmRule, state *define.StateMetrics, tasks []*define.Task) ([]ruleAlert, map[string]bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var alerts []ruleAlert
+	holding := make(map[string]bool)
+
+	for _, rule := range rules {
+		metric, ok := models.FindAlarmMetric(rule.Metric)
+		if !ok {
+			continue
+		}
+		for _, sample := range ruleSamples(rule.Metric, state, tasks) {
+			if rule.Target != "" && sample.target != rule.Target {
+				continue
+			}
+			key := ruleAlarmKey(rule.ID, sample.target)
+			if !rule.Comparator.Compare(sample.value, rule.Threshold) {
+				continue
+			}
+
+			holding[key] = true
+			e.streaks[key]++
+			if e.streaks[key] < rule.ForSlots {
+				continue
+			}
+			alerts = append(alerts, ruleAlert{
+				key:         key,
+				target:      sample.target,
+				name:        ruleAlarmName(rule, metric.Scope, sample.target),
+				description: ruleAlarmDescription(rule, metric, sample, e.streaks[key]),
+				rule:        rule,
+			})
+		}
+	}
+
+	// 条件中断后重新计数持续时隙
+	for key := range e.streaks {
+		if !holding[key] {
+			delete(e.streaks, key)
+		}
+	}
+	return alerts, holding
+}
+
+// ruleSamples 取出指标在本时隙的所有取值
+func ruleSamples(metric string, state *define.StateMetrics, tasks []*define.Task) []ruleSample {
+	switch metric {
+	case models.MetricTotalDelay:
+		return systemSample(state.TotalDelay)
+	case models.MetricTransferDelay:
+		return systemSample(state.TransferDelay)
+	case models.MetricComputeDelay:
+		return systemSample(state.ComputeDelay)
+	case models.MetricTotalEnergy:
+		return systemSample(state.TotalEnergy)
+	case models.MetricTransferEnergy:
+		return systemSample(state.TransferEnergy)
+	case models.MetricComputeEnergy:
+		return systemSample(state.ComputeEnergy)
+	case models.MetricLoad:
+		return systemSample(state.Load)
+	case models.MetricTotalQueue:
+		return systemSample(state.TotalQueue)
+	case models.MetricCost:
+		return systemSample(state.Cost)
+	case models.MetricDrift:
+		return systemSample(state.Drift)
+	case models.MetricPenalty:
+		return systemSample(state.Penalty)
+	case models.MetricCommQueue:
+		return mapSamples(state.CommQueues)
+	case models.MetricBatteryLevel:
+		return mapSamples(state.BatteryLevels)
+	case models.MetricCommEnergyQueue:
+		return mapSamples(state.CommEnergyQueues)
+	case models.MetricUserEnergyQueue:
+		return mapSamples(state.UserEnergyQueues)
+	case models.MetricTaskWaitSlots, models.MetricTaskDataSize:
+		samples := make([]ruleSample, 0, len(tasks))
+		for _, task := range tasks {
+			if task.Status == define.TaskCompleted || task.Status == define.TaskFailed || task.CancelledAt != nil {
+				continue
+			}
+			value := task.DataSize
+			if metric == models.MetricTaskWaitSlots {
+				value = 0
+				if state.TimeSlot > task.CreatedSlot {
+					value = float64(state.TimeSlot - task.CreatedSlot)
+				}
+			}
+			samples = append(samples, ruleSample{target: task.ID, value: value})
+		}
+		return samples
+	}
+	return nil
+}
+
+func systemSample(value float64) []ruleSample {
+	return []ruleSample{{value: value}}
+}
+
+// mapSamples 按对象ID排序，保证告警产生顺序稳定
+func mapSamples(values map[string]float64) []ruleSample {
+	samples := make([]ruleSample, 0, len(values))
+	for target, value := range values {
+		samples = append(samples, ruleSample{target: target, value: value})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].target < samples[j].target })
+	return samples
+}
+
+// ruleAlarmKey 告警去重键 (每个规则的每个对象一个)
+func ruleAlarmKey(ruleID uint, target string) string {
+	if target == "" {
+		return fmt.Sprintf("%s%d", ruleAlarmPrefix, ruleID)
+	}
+	return fmt.Sprintf("%s%d_%s", ruleAlarmPrefix, ruleID, target)
+}
+
+// ruleTargetLabel 对象的显示名称
+func ruleTargetLabel(scope models.AlarmMetricScope, target string) string {
+	switch scope {
+	case models.MetricScopeComm:
+		return "通信设备 " + target
+	case models.MetricScopeUser:
+		return "用户设备 " + target
+	case models.MetricScopeTask:
+		return "任务 " + target
+	}
+	return target
+}
+
+func ruleAlarmName(rule models.AlarmRule, scope models.AlarmMetricScope, target string) string {
+	if target == "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("%s (%s)", rule.Name, ruleTargetLabel(scope, target))
+}
+
+func ruleAlarmDescription(rule models.AlarmRule, metric models.AlarmMetricInfo, sample ruleSample, streak int) string {
+	subject := metric.Description
+	if sample.target != "" {
+		subject = ruleTargetLabel(metric.Scope, sample.target) + ": " + subject
+	}
+	description := fmt.Sprintf("%s 当前值 %s %s 阈值 %s，已连续 %d 个时隙 (级别: %s)",
+		subject, formatRuleValue(sample.value), rule.Comparator.Symbol(), formatRuleValue(rule.Threshold),
+		streak, rule.Severity)
+	if rule.Description != "" {
+		description = rule.Description + "。" + description
+	}
+	return description
+}
+
+func formatRuleValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
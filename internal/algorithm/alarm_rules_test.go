@@ -0,0 +1,99 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/models"
+	"testing"
+)
+
+func TestRuleEvaluator(t *testing.T) {
+	rules := []models.AlarmRule{
+		{ID: 1, Name: "延迟", Metric: models.MetricTotalDelay, Comparator: models.ComparatorGT, Threshold: 10, ForSlots: 3, EventType: models.AlarmEventPerformance},
+		{ID: 2, Name: "电量", Metric: models.MetricBatteryLevel, Comparator: models.ComparatorLT, Threshold: 0.2, ForSlots: 1, EventType: models.AlarmEventHardware},
+		{ID: 3, Name: "队列", Metric: models.MetricCommQueue, Target: "2", Comparator: models.ComparatorGTE, Threshold: 100, ForSlots: 1, EventType: models.AlarmEventNetwork},
+	}
+	e := newRuleEvaluator()
+
+	slot := func(delay float64, battery, queues map[string]float64) ([]ruleAlert, map[string]bool) {
+		state := define.NewStateMetrics()
+		state.TotalDelay = delay
+		state.BatteryLevels = battery
+		state.CommQueues = queues
+		return e.evaluate(rules, state, nil)
+	}
+	keys := func(alerts []ruleAlert) map[string]bool {
+		set := make(map[string]bool)
+		for _, a := range alerts {
+			set[a.key] = true
+		}
+		return set
+	}
+
+	// 延迟需连续3个时隙超过阈值；队列规则只匹配通信设备2
+	for i := 0; i < 2; i++ {
+		alerts, _ := slot(12, map[string]float64{"1": 0.1, "2": 0.9}, map[string]float64{"1": 500, "2": 50})
+		got := keys(alerts)
+		if got["rule_1"] || !got["rule_2_1"] || got["rule_2_2"] || got["rule_3_1"] || got["rule_3_2"] {
+			t.Fatalf("第%d个时隙告警不正确: %v", i+1, got)
+		}
+	}
+	alerts, _ := slot(12, map[string]float64{"1": 0.1}, map[string]float64{"2": 100})
+	got := keys(alerts)
+	if !got["rule_1"] || !got["rule_3_2"] {
+		t.Fatalf("达到持续时隙数后应产生告警: %v", got)
+	}
+
+	// 延迟恢复、无人机1消失、队列仍超过阈值
+	alerts, holding := slot(5, map[string]float64{}, map[string]float64{"2": 200})
+	if got := keys(alerts); len(got) != 1 || !got["rule_3_2"] {
+		t.Fatalf("恢复后的告警不正确: %v", got)
+	}
+	if len(holding) != 1 || !holding["rule_3_2"] {
+		t.Fatalf("条件满足的告警键不正确: %v", holding)
+	}
+
+	// 延迟再次超过阈值时重新计数，未达到持续时隙数时同样计入条件满足
+	alerts, holding = slot(12, nil, nil)
+	if keys(alerts)["rule_1"] {
+		t.Fatal("中断后应重新计数持续时隙")
+	}
+	if !holding["rule_1"] {
+		t.Fatalf("未达到持续时隙数的告警键应计入条件满足: %v", holding)
+	}
+
+	// 规则被禁用后没有条件满足的告警键
+	if _, holding = e.evaluate(nil, define.NewStateMetrics(), nil); len(holding) != 0 {
+		t.Fatalf("规则禁用后不应有条件满足的告警键: %v", holding)
+	}
+}
+
+func TestRuleSamplesTasks(t *testing.T) {
+	state := define.NewStateMetrics()
+	state.TimeSlot = 10
+	tasks := []*define.Task{
+		{ID: "a", CreatedSlot: 4, DataSize: 1e6, Status: define.TaskPending},
+		{ID: "b", CreatedSlot: 2, Status: define.TaskCompleted},
+	}
+
+	samples := ruleSamples(models.MetricTaskWaitSlots, state, tasks)
+	if len(samples) != 1 || samples[0].target != "a" || samples[0].value != 6 {
+		t.Fatalf("任务等待时隙不正确: %+v", samples)
+	}
+}
+
+// TestRuleSamplesCostTerms 测试告警规则的Drift/Penalty/Cost取值与调度器导出的指标一致
+func TestRuleSamplesCostTerms(t *testing.T) {
+	sys := newTestSystem(t)
+	seedTasks(t, sys, 2)
+
+	drift, penalty, cost := sys.LyapunovScheduler.LastCostTerms()
+	if cost == 0 {
+		t.Fatal("调度后Cost不应为0")
+	}
+	for metric, want := range map[string]float64{models.MetricDrift: drift, models.MetricPenalty: penalty, models.MetricCost: cost} {
+		samples := ruleSamples(metric, sys.CurrentState, nil)
+		if len(samples) != 1 || samples[0].value != want {
+			t.Errorf("%s 应为调度器所选方案的值 %g, got %+v", metric, want, samples)
+		}
+	}
+}
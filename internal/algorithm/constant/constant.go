@@ -12,7 +12,7 @@ const (
 	Eta = 0.9
 )
 
-// 控制参数
+// 控制参数 (Lyapunov参数的默认值，可在 configs/config.yaml 的 lyapunov 段覆盖，运行时通过管理接口修改)
 const (
 	// 迭代次数
 	Iters = 20
@@ -28,6 +28,12 @@ const (
 	Beta = 0.3
 	// 权重参数γ
 	Gamma = 0.4
+	// 通信设备平均功率预算，单位：W（0表示不约束）
+	CommEnergyBudget = 0
+	// 用户设备平均功率预算，单位：W（0表示不约束）
+	UserEnergyBudget = 0
+	// 能耗虚拟队列drift权重
+	EnergyWeight = 1
 	// 最大成本（用于无效分配）
 	MaxCost = 1e10
 )
@@ -58,6 +64,10 @@ const (
 const (
 	// 计算能耗参数
 	Kappa = 10e-28
-	// 保持状态能耗，单位：W/s
+	// 无人机悬停功率，单位：W
 	E_hold = 220
+	// 无人机默认电池容量，单位：J（约15分钟悬停）
+	BatteryCapacity = 2e5
+	// 低电量告警比例
+	LowBattery = 0.2
 )
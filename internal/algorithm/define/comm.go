@@ -1,13 +1,79 @@
 package define
 
-import "go-backend/internal/models"
+import (
+	"go-backend/internal/algorithm/constant"
+	"go-backend/internal/algorithm/utils"
+	"go-backend/internal/models"
+	pkgutils "go-backend/pkg/utils"
+)
 
 type CommDevice struct {
 	models.Node
+
+	CoverageRadius float64 // 覆盖半径，单位：米 (Properties["coverage_radius"]，默认constant.Radius)
+	EnergyBudget   float64 // 平均功率预算，单位：W (Properties["energy_budget"]，0表示使用全局配置)
+
+	// 无人机电池模型 (仅IsUAV为true时生效)
+	IsUAV           bool    // 是否为无人机 (Properties["is_uav"])
+	BatteryCapacity float64 // 电池容量，单位：焦耳 (Properties["battery_capacity"])
+	BatteryLevel    float64 // 剩余电量，单位：焦耳
 }
 
 func NewCommDevice(node models.Node) *CommDevice {
-	return &CommDevice{
-		Node: node,
+	comm := &CommDevice{
+		Node:           node,
+		CoverageRadius: constant.Radius,
+		IsUAV:          pkgutils.PropertyBool(node.Properties, "is_uav"),
+	}
+
+	if budget, ok := pkgutils.PropertyFloat(node.Properties, "energy_budget"); ok && budget > 0 {
+		comm.EnergyBudget = budget
+	}
+
+	if node.Properties != nil {
+		if radius, ok := pkgutils.ParseDistance(node.Properties["coverage_radius"]); ok {
+			comm.CoverageRadius = radius
+		}
+	}
+
+	if comm.IsUAV {
+		comm.BatteryCapacity = constant.BatteryCapacity
+		if capacity, ok := pkgutils.PropertyFloat(node.Properties, "battery_capacity"); ok && capacity > 0 {
+			comm.BatteryCapacity = capacity
+		}
+		comm.BatteryLevel = comm.BatteryCapacity
+	}
+
+	return comm
+}
+
+// Covers 判断坐标(x, y)是否在设备覆盖范围内
+func (c *CommDevice) Covers(x, y float64) bool {
+	return utils.Distance(c.X, c.Y, x, y) <= c.CoverageRadius
+}
+
+// IsAvailable 设备是否可参与调度 (无人机电量耗尽后不可用)
+func (c *CommDevice) IsAvailable() bool {
+	return !c.IsUAV || c.BatteryLevel > 0
+}
+
+// BatteryRatio 剩余电量比例 (0~1)，非无人机恒为1
+func (c *CommDevice) BatteryRatio() float64 {
+	if !c.IsUAV || c.BatteryCapacity <= 0 {
+		return 1
+	}
+	return c.BatteryLevel / c.BatteryCapacity
+}
+
+// Drain 扣减电量，返回扣减后是否刚好耗尽
+func (c *CommDevice) Drain(energy float64) bool {
+	if !c.IsUAV || c.BatteryLevel <= 0 || energy <= 0 {
+		return false
+	}
+	c.BatteryLevel -= energy
+	if c.BatteryLevel <= 0 {
+		c.BatteryLevel = 0
+		return true
 	}
+	return false
 }
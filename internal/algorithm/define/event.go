@@ -0,0 +1,24 @@
+package define
+
+import "time"
+
+// TaskTransition 任务状态变化
+type TaskTransition struct {
+	TaskID string     `json:"task_id"` // 任务ID
+	UserID uint       `json:"user_id"` // 用户设备ID
+	From   TaskStatus `json:"from"`    // 变化前状态
+	To     TaskStatus `json:"to"`      // 变化后状态
+}
+
+// SlotEvent 单个时隙的执行事件 (推送给实时订阅者)
+type SlotEvent struct {
+	TimeSlot    uint             `json:"time_slot"`   // 时隙编号
+	Timestamp   time.Time        `json:"timestamp"`   // 时隙完成时间
+	State       *StateMetrics    `json:"state"`       // 时隙结束后的系统状态
+	Transitions []TaskTransition `json:"transitions"` // 本时隙的任务状态变化
+	Assignments []*Assignment    `json:"assignments"` // 本时隙的调度分配
+	Dropped     uint64           `json:"dropped"`     // 该订阅者因处理过慢累计丢弃的事件数
+
+	TaskUsers  map[string]uint `json:"-"` // 任务ID → 用户设备ID (用于按用户过滤分配)
+	TaskOwners map[string]uint `json:"-"` // 任务ID → 提交账号ID (用于按访问范围过滤)
+}
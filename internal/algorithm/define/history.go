@@ -0,0 +1,44 @@
+package define
+
+import "time"
+
+// 聚合方式
+const (
+	AggAvg = "avg" // 桶内平均值
+	AggMax = "max" // 桶内最大值
+)
+
+// 历史数据来源
+const (
+	HistorySourceMemory = "memory" // 内存环形缓冲 (最近若干时隙)
+	HistorySourceDB     = "db"     // SQLite持久化记录
+)
+
+// HistoryQuery 状态历史查询条件
+type HistoryQuery struct {
+	FromSlot *uint      // 起始时隙 (含)
+	ToSlot   *uint      // 结束时隙 (含)
+	From     *time.Time // 起始时间 (含)
+	To       *time.Time // 结束时间 (含)
+	Bucket   int        // 每个桶包含的时隙数 (0表示不降采样，数据点过多时自动降采样)
+	Agg      string     // 聚合方式: avg | max
+	Fields   []string   // 返回的字段 (为空返回全部标量字段)
+	Source   string     // 数据来源: memory | db
+}
+
+// HistoryPoint 状态历史数据点 (降采样时表示一个桶)
+type HistoryPoint struct {
+	TimeSlot  uint               `json:"time_slot"` // 时隙 (桶内第一个时隙)
+	Timestamp time.Time          `json:"timestamp"` // 时间 (桶内第一个时隙的时间)
+	Count     int                `json:"count"`     // 桶内时隙数
+	Values    map[string]float64 `json:"values"`    // 字段值
+}
+
+// HistoryResult 状态历史查询结果
+type HistoryResult struct {
+	Source string         `json:"source"` // 数据来源
+	Bucket int            `json:"bucket"` // 实际使用的桶大小 (时隙数)
+	Agg    string         `json:"agg"`    // 聚合方式
+	Fields []string       `json:"fields"` // 返回的字段
+	Points []HistoryPoint `json:"points"` // 数据点 (按时隙升序)
+}
@@ -0,0 +1,30 @@
+package define
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DefaultInstanceID 默认实例ID (全局共享系统，所有用户可访问)
+const DefaultInstanceID = "default"
+
+// InstanceOptions 创建仿真实例的选项
+type InstanceOptions struct {
+	Name      string          // 实例名称
+	Scheduler string          // 调度器类型: lyapunov | simple (为空使用lyapunov)
+	Params    json.RawMessage // Lyapunov参数JSON (未提供的字段使用默认参数)
+	SpeedUp   float64         // 仿真加速比 (0使用默认值)
+}
+
+// InstanceInfo 仿真实例信息
+type InstanceInfo struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	OwnerID      uint      `json:"owner_id"` // 创建者 (默认实例为0)
+	Owner        string    `json:"owner"`
+	Scheduler    string    `json:"scheduler"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastAccessAt time.Time `json:"last_access_at"`
+
+	LoopStatus
+}
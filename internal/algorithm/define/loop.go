@@ -0,0 +1,20 @@
+package define
+
+// LoopState 调度循环状态
+type LoopState string
+
+const (
+	LoopStopped LoopState = "stopped" // 未启动/已停止
+	LoopRunning LoopState = "running" // 按时隙间隔自动执行
+	LoopPaused  LoopState = "paused"  // 已暂停 (可单步执行)
+)
+
+// LoopStatus 调度循环状态信息
+type LoopStatus struct {
+	State          LoopState `json:"state"`            // 循环状态
+	TimeSlot       uint      `json:"time_slot"`        // 当前时隙
+	TickIntervalMs int64     `json:"tick_interval_ms"` // 时隙间隔 (毫秒, 真实时间)
+	SpeedUp        float64   `json:"speed_up"`         // 仿真加速比 (时隙间隔 = 时隙长度 / 加速比)
+	SimulatedTime  float64   `json:"simulated_time"`   // 已仿真时长 (秒) = 时隙数 × 时隙长度
+	ActiveTasks    int       `json:"active_tasks"`     // 活跃任务数
+}
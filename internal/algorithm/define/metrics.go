@@ -33,4 +33,9 @@ type SystemInfo struct {
 	ActiveTasks    int               `json:"active_tasks"`    // 活跃任务数
 	CompletedTasks int               `json:"completed_tasks"` // 已完成任务数
 	State          interface{}       `json:"state"` // 当前状态
+
+	DisconnectedUsers []uint    `json:"disconnected_users"` // 不在覆盖范围内的用户设备ID
+	LoopState         LoopState `json:"loop_state"`         // 调度循环状态: stopped | running | paused
+	TickIntervalMs    int64     `json:"tick_interval_ms"`   // 时隙间隔 (毫秒)
+	SpeedUp           float64   `json:"speed_up"`           // 仿真加速比
 }
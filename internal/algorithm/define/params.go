@@ -0,0 +1,109 @@
+package define
+
+import (
+	"fmt"
+	"go-backend/internal/algorithm/constant"
+	"math"
+	"time"
+)
+
+// SolverType Lyapunov drift-plus-penalty 求解策略
+type SolverType string
+
+const (
+	SolverGreedy      SolverType = "greedy"       // 贪心: 按优先级逐个任务选择边际cost最小的设备
+	SolverLocalSearch SolverType = "local_search" // 贪心 + 迁移/交换局部搜索 (默认)
+	SolverAnnealing   SolverType = "annealing"    // 贪心 + 局部搜索 + 模拟退火
+	SolverRandom      SolverType = "random"       // 随机搜索 (旧实现，仅用于对比)
+)
+
+// IsValid 求解策略是否受支持
+func (t SolverType) IsValid() bool {
+	switch t {
+	case SolverGreedy, SolverLocalSearch, SolverAnnealing, SolverRandom:
+		return true
+	}
+	return false
+}
+
+// 参数取值上限 (防止误配置导致单个时隙计算过久)
+const MaxLyapunovIters = 10000
+
+// LyapunovParams Lyapunov优化参数 (可通过配置文件加载、运行时修改)
+type LyapunovParams struct {
+	V      float64    `json:"v" yaml:"v"`           // 控制参数V (权衡队列稳定性与性能)
+	Alpha  float64    `json:"alpha" yaml:"alpha"`   // 延迟权重α
+	Beta   float64    `json:"beta" yaml:"beta"`     // 能耗权重β
+	Gamma  float64    `json:"gamma" yaml:"gamma"`   // 负载权重γ
+	Shrink float64    `json:"shrink" yaml:"shrink"` // 收缩参数 (drift/penalty归一化)
+	Iters  int        `json:"iters" yaml:"iters"`   // 迭代预算
+	Solver SolverType `json:"solver" yaml:"solver"` // 求解策略
+
+	// 长期平均能耗约束 (能耗虚拟队列)，节点属性 energy_budget 可单独覆盖
+	CommEnergyBudget float64 `json:"comm_energy_budget" yaml:"comm_energy_budget"` // 通信设备平均功率预算 W (0表示不约束)
+	UserEnergyBudget float64 `json:"user_energy_budget" yaml:"user_energy_budget"` // 用户设备平均功率预算 W (0表示不约束)
+	EnergyWeight     float64 `json:"energy_weight" yaml:"energy_weight"`           // 能耗虚拟队列在drift中的权重
+}
+
+// DefaultLyapunovParams 默认参数 (取自constant)
+func DefaultLyapunovParams() LyapunovParams {
+	return LyapunovParams{
+		V:      constant.V,
+		Alpha:  constant.Alpha,
+		Beta:   constant.Beta,
+		Gamma:  constant.Gamma,
+		Shrink: constant.Shrink,
+		Iters:  constant.Iters,
+		Solver: SolverLocalSearch,
+
+		CommEnergyBudget: constant.CommEnergyBudget,
+		UserEnergyBudget: constant.UserEnergyBudget,
+		EnergyWeight:     constant.EnergyWeight,
+	}
+}
+
+// Validate 校验参数合法性
+func (p LyapunovParams) Validate() error {
+	for name, value := range map[string]float64{
+		"v": p.V, "alpha": p.Alpha, "beta": p.Beta, "gamma": p.Gamma, "shrink": p.Shrink,
+		"comm_energy_budget": p.CommEnergyBudget, "user_energy_budget": p.UserEnergyBudget, "energy_weight": p.EnergyWeight,
+	} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("参数%s不是有效数值", name)
+		}
+	}
+
+	if p.V < 0 {
+		return fmt.Errorf("参数v不能为负数: %g", p.V)
+	}
+	if p.Alpha < 0 || p.Beta < 0 || p.Gamma < 0 {
+		return fmt.Errorf("权重α/β/γ不能为负数: %g/%g/%g", p.Alpha, p.Beta, p.Gamma)
+	}
+	if p.Alpha+p.Beta+p.Gamma == 0 {
+		return fmt.Errorf("权重α/β/γ不能全部为0")
+	}
+	if p.Shrink <= 0 {
+		return fmt.Errorf("参数shrink必须大于0: %g", p.Shrink)
+	}
+	if p.Iters < 1 || p.Iters > MaxLyapunovIters {
+		return fmt.Errorf("参数iters必须在1~%d之间: %d", MaxLyapunovIters, p.Iters)
+	}
+	if p.CommEnergyBudget < 0 || p.UserEnergyBudget < 0 {
+		return fmt.Errorf("能耗预算不能为负数: %g/%g", p.CommEnergyBudget, p.UserEnergyBudget)
+	}
+	if p.EnergyWeight < 0 {
+		return fmt.Errorf("参数energy_weight不能为负数: %g", p.EnergyWeight)
+	}
+	if !p.Solver.IsValid() {
+		return fmt.Errorf("未知的求解策略: %s (支持: greedy, local_search, annealing, random)", p.Solver)
+	}
+	return nil
+}
+
+// ParamsChange 参数变更记录
+type ParamsChange struct {
+	TimeSlot  uint           `json:"time_slot"`  // 生效时隙 (从该时隙开始使用新参数)
+	ChangedAt time.Time      `json:"changed_at"` // 变更时间
+	ChangedBy string         `json:"changed_by"` // 操作人
+	Params    LyapunovParams `json:"params"`     // 变更后的参数
+}
@@ -0,0 +1,33 @@
+package define
+
+import "errors"
+
+// TaskQuota 每个账号的任务配额 (0表示不限制)
+type TaskQuota struct {
+	MaxActiveTasks int     `json:"max_active_tasks" yaml:"max_active_tasks"` // 未结束任务数上限
+	MaxActiveData  float64 `json:"max_active_data" yaml:"max_active_data"`   // 未结束任务的数据总量上限 (与data_size同单位)
+}
+
+// Validate 校验配额
+func (q TaskQuota) Validate() error {
+	if q.MaxActiveTasks < 0 || q.MaxActiveData < 0 {
+		return errors.New("任务配额不能为负数")
+	}
+	return nil
+}
+
+// TaskOwner 提交任务的账号
+type TaskOwner struct {
+	ID        uint // 账号ID (0表示不记录)
+	Unlimited bool // 不受配额限制 (管理员)
+}
+
+// QuotaUsage 账号的任务配额使用情况
+type QuotaUsage struct {
+	OwnerID     uint      `json:"owner_id"`     // 账号ID
+	ActiveTasks int       `json:"active_tasks"` // 未结束任务数
+	ActiveData  float64   `json:"active_data"`  // 未结束任务的数据总量
+	Quota       TaskQuota `json:"quota"`        // 配额
+	Unlimited   bool      `json:"unlimited"`    // 是否不受配额限制
+	UserIDs     []uint    `json:"user_ids"`     // 名下可提交任务的用户设备ID
+}
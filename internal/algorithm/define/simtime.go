@@ -0,0 +1,22 @@
+package define
+
+import (
+	"go-backend/internal/algorithm/constant"
+	"time"
+)
+
+// SlotDuration 单个时隙对应的仿真时长
+const SlotDuration = time.Duration(constant.Slot * float64(time.Second))
+
+// SlotsToDuration 将时隙数换算为仿真时长
+func SlotsToDuration(slots uint) time.Duration {
+	return time.Duration(slots) * SlotDuration
+}
+
+// SlotsBetween 计算两个时隙之间的时隙数 (to早于from时返回0)
+func SlotsBetween(from, to uint) uint {
+	if to < from {
+		return 0
+	}
+	return to - from
+}
@@ -0,0 +1,53 @@
+package define
+
+import (
+	"go-backend/internal/models"
+	"time"
+)
+
+// SnapshotVersion 当前快照格式版本 (格式不兼容变更时递增)
+const SnapshotVersion = 1
+
+// Snapshot 系统完整状态快照 (用于实验断点、共享状态和崩溃恢复)
+type Snapshot struct {
+	Version   int       `json:"version"`    // 快照格式版本
+	CreatedAt time.Time `json:"created_at"` // 生成时间
+
+	// 运行状态
+	TimeSlot    uint          `json:"time_slot"`       // 当前时隙
+	UseLyapunov bool          `json:"use_lyapunov"`    // 是否使用Lyapunov调度器
+	SpeedUp     float64       `json:"speed_up"`        // 仿真加速比
+	State       *StateMetrics `json:"state"`           // 最近一个时隙的状态指标
+	Quota       *TaskQuota    `json:"quota,omitempty"` // 任务配额 (旧快照没有时使用默认配额)
+
+	// 拓扑 (恢复时据此重建设备和Floyd路径)
+	Nodes         []models.Node    `json:"nodes"`
+	Links         []models.Link    `json:"links"`
+	BatteryLevels map[uint]float64 `json:"battery_levels,omitempty"` // 无人机剩余电量 (CommID -> 焦耳)
+
+	// 任务与分配历史
+	Tasks       []*Task                  `json:"tasks"`       // 按提交顺序
+	Assignments map[string][]*Assignment `json:"assignments"` // TaskID -> 分配历史
+
+	// 调度器内部状态
+	Scheduler SchedulerSnapshot `json:"scheduler"`
+}
+
+// SchedulerSnapshot Lyapunov调度器内部状态
+type SchedulerSnapshot struct {
+	Params           LyapunovParams     `json:"params"`
+	ParamsHistory    []ParamsChange     `json:"params_history"`
+	Seed             int64              `json:"seed"`
+	LastCommQueues   map[string]float64 `json:"last_comm_queues"`   // 上一时隙的队列 (用于计算drift)
+	CommEnergyQueues map[string]float64 `json:"comm_energy_queues"` // 能耗虚拟队列
+	UserEnergyQueues map[string]float64 `json:"user_energy_queues"`
+}
+
+// SnapshotInfo 快照文件信息
+type SnapshotInfo struct {
+	Name      string    `json:"name"`                 // 文件名 (不含扩展名)
+	Size      int64     `json:"size"`                 // 文件大小 (字节)
+	CreatedAt time.Time `json:"created_at"`           // 文件修改时间
+	TimeSlot  uint      `json:"time_slot,omitempty"`  // 快照时隙 (仅保存时返回)
+	TaskCount int       `json:"task_count,omitempty"` // 任务数 (仅保存时返回)
+}
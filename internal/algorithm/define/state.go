@@ -1,24 +1,37 @@
 package define
 
+import "time"
+
 // StateMetrics 系统全局状态指标
 type StateMetrics struct {
-	CommQueues     map[string]float64 `json:"comm_queues"`     // 每个通信设备的队列长度
-	TotalQueue     float64            `json:"total_queue"`     // 总队列长度
-	TransferDelay  float64            `json:"transfer_delay"`  // 传输延迟
-	ComputeDelay   float64            `json:"compute_delay"`   // 计算延迟
-	TotalDelay     float64            `json:"total_delay"`     // 总延迟
-	TransferEnergy float64            `json:"transfer_energy"` // 传输能耗
-	ComputeEnergy  float64            `json:"compute_energy"`  // 计算能耗
-	TotalEnergy    float64            `json:"total_energy"`    // 总能耗
-	Load           float64            `json:"load"`            // 系统负载
-	Cost           float64            `json:"cost"`            // 总成本
-	Drift          float64            `json:"drift"`           // 漂移值
-	Penalty        float64            `json:"penalty"`         // 惩罚项
+	TimeSlot       uint               `json:"time_slot"`        // 时隙编号
+	Timestamp      time.Time          `json:"timestamp"`        // 时隙完成时间
+	CommQueues     map[string]float64 `json:"comm_queues"`      // 每个通信设备的队列长度
+	TotalQueue     float64            `json:"total_queue"`      // 总队列长度
+	TransferDelay  float64            `json:"transfer_delay"`   // 传输延迟
+	ComputeDelay   float64            `json:"compute_delay"`    // 计算延迟
+	TotalDelay     float64            `json:"total_delay"`      // 总延迟
+	TransferEnergy float64            `json:"transfer_energy"`  // 传输能耗
+	ComputeEnergy  float64            `json:"compute_energy"`   // 计算能耗
+	TotalEnergy    float64            `json:"total_energy"`     // 总能耗
+	Load           float64            `json:"load"`             // 系统负载
+	Cost           float64            `json:"cost"`             // 总成本
+	Drift          float64            `json:"drift"`            // 漂移值
+	Penalty        float64            `json:"penalty"`          // 惩罚项
+	BatteryLevels  map[string]float64 `json:"battery_levels"`   // 每个无人机的剩余电量比例 (0~1)
+	Params         *LyapunovParams    `json:"params,omitempty"` // 本时隙生效的Lyapunov参数 (使用Lyapunov调度器时记录)
+
+	CommEnergyQueues map[string]float64 `json:"comm_energy_queues"` // 通信设备能耗虚拟队列 (累计超出预算的能耗，单位：J)
+	UserEnergyQueues map[string]float64 `json:"user_energy_queues"` // 用户设备能耗虚拟队列 (累计超出预算的能耗，单位：J)
 }
 
 // NewStateMetrics 创建空的状态指标
 func NewStateMetrics() *StateMetrics {
 	return &StateMetrics{
-		CommQueues: make(map[string]float64),
+		CommQueues:    make(map[string]float64),
+		BatteryLevels: make(map[string]float64),
+
+		CommEnergyQueues: make(map[string]float64),
+		UserEnergyQueues: make(map[string]float64),
 	}
 }
@@ -17,13 +17,14 @@ func NewTaskStateMachine(task *Task) *TaskStateMachine {
 
 // 状态转换方法
 
-// ToQueued 转换到Queued状态（任务被分配到通信设备）
-func (sm *TaskStateMachine) ToQueued() error {
+// ToQueued 转换到Queued状态（任务在timeSlot被分配到通信设备）
+func (sm *TaskStateMachine) ToQueued(timeSlot uint) error {
 	if sm.task.Status != TaskPending {
 		return fmt.Errorf("invalid state transition: %s -> Queued", sm.statusName())
 	}
 	sm.task.Status = TaskQueued
 	sm.task.ScheduledTime = time.Now()
+	sm.task.ScheduledSlot = timeSlot
 	return nil
 }
 
@@ -36,13 +37,14 @@ func (sm *TaskStateMachine) ToComputing() error {
 	return nil
 }
 
-// ToCompleted 转换到Completed状态（任务完成）
-func (sm *TaskStateMachine) ToCompleted() error {
+// ToCompleted 转换到Completed状态（任务在timeSlot完成）
+func (sm *TaskStateMachine) ToCompleted(timeSlot uint) error {
 	if sm.task.Status != TaskComputing && sm.task.Status != TaskQueued {
 		return fmt.Errorf("invalid state transition: %s -> Completed", sm.statusName())
 	}
 	sm.task.Status = TaskCompleted
 	sm.task.CompleteTime = time.Now()
+	sm.task.CompletedSlot = timeSlot
 	return nil
 }
 
@@ -14,13 +14,20 @@ const (
 	PriorityCritical = 20 // 关键优先级
 )
 
+// 任务保持Pending的原因
+const (
+	ReasonOutOfCoverage   = "用户设备不在任何通信设备覆盖范围内"
+	ReasonNoReachableComm = "暂无可达的通信设备"
+)
+
 // Task 简化的任务对象 (纯持久化,不包含调度状态)
 type Task struct {
 	// 基本信息
 	ID        string    `json:"id"`
 	Name      string    `json:"name,omitempty"`
 	Type      string    `json:"type,omitempty"`
-	UserID    uint      `json:"user_id"`
+	UserID    uint      `json:"user_id"`            // 用户设备ID (拓扑节点)
+	OwnerID   uint      `json:"owner_id,omitempty"` // 提交任务的账号ID (0表示未记录)
 	DataSize  float64   `json:"data_size"`
 	Priority  int       `json:"priority,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
@@ -28,14 +35,20 @@ type Task struct {
 	// 状态
 	Status TaskStatus `json:"status"`
 
-	// 时间戳
+	// 时间戳 (真实时间，仅用于展示)
 	ScheduledTime time.Time  `json:"scheduled_time,omitempty"` // 首次分配时间
 	CompleteTime  time.Time  `json:"complete_time,omitempty"`  // 完成时间
 	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`   // 取消时间
 
+	// 仿真时间 (时隙)，等待/超时/饥饿/完成时间均据此计算，与调度循环的实际运行速度无关
+	CreatedSlot   uint `json:"created_slot"`             // 提交时的时隙
+	ScheduledSlot uint `json:"scheduled_slot,omitempty"` // 首次分配的时隙
+	CompletedSlot uint `json:"completed_slot,omitempty"` // 完成的时隙
+
 	// 超时和取消
-	Timeout       time.Duration `json:"timeout,omitempty"`        // 超时时长 (0表示无超时)
+	Timeout       time.Duration `json:"timeout,omitempty"`        // 超时时长 (仿真时间, 0表示无超时)
 	FailureReason string        `json:"failure_reason,omitempty"` // 失败原因
+	PendingReason string        `json:"pending_reason,omitempty"` // 保持等待的原因 (如不在覆盖范围内)
 }
 
 // NewTask 创建新任务
@@ -68,42 +81,41 @@ func (t *Task) IsCancelled() bool {
 	return t.CancelledAt != nil
 }
 
-// IsTimedOut 检查任务是否超时
-func (t *Task) IsTimedOut() bool {
+// IsTimedOut 检查任务在当前时隙是否超时 (按仿真时间计算)
+func (t *Task) IsTimedOut(currentSlot uint) bool {
 	if t.Timeout == 0 {
 		return false // 无超时限制
 	}
 	if t.Status == TaskCompleted || t.Status == TaskFailed {
 		return false // 已结束的任务不算超时
 	}
-	elapsed := time.Since(t.CreatedAt)
-	return elapsed > t.Timeout
+	return t.GetElapsedTime(currentSlot) > t.Timeout
 }
 
-// GetElapsedTime 获取任务已运行时间
-func (t *Task) GetElapsedTime() time.Duration {
-	if !t.CompleteTime.IsZero() {
-		return t.CompleteTime.Sub(t.CreatedAt)
+// GetElapsedTime 获取任务已运行的仿真时间 (已完成时为从提交到完成的时间)
+func (t *Task) GetElapsedTime(currentSlot uint) time.Duration {
+	if t.CompletedSlot != 0 {
+		return SlotsToDuration(SlotsBetween(t.CreatedSlot, t.CompletedSlot))
 	}
-	return time.Since(t.CreatedAt)
+	return SlotsToDuration(SlotsBetween(t.CreatedSlot, currentSlot))
 }
 
-// GetWaitTime 获取任务等待时间 (从创建到调度)
-func (t *Task) GetWaitTime() time.Duration {
-	if !t.ScheduledTime.IsZero() {
-		return t.ScheduledTime.Sub(t.CreatedAt)
+// GetWaitTime 获取任务等待的仿真时间 (从提交到首次调度)
+func (t *Task) GetWaitTime(currentSlot uint) time.Duration {
+	if t.ScheduledSlot != 0 {
+		return SlotsToDuration(SlotsBetween(t.CreatedSlot, t.ScheduledSlot))
 	}
-	return time.Since(t.CreatedAt) // 还在等待
+	return SlotsToDuration(SlotsBetween(t.CreatedSlot, currentSlot)) // 还在等待
 }
 
-// IsStarving 检查任务是否处于饥饿状态 (等待时间过长)
-// 饥饿阈值: 低优先级10秒, 普通5秒, 高优先级2秒
-func (t *Task) IsStarving() bool {
+// IsStarving 检查任务在当前时隙是否处于饥饿状态 (等待时间过长)
+// 饥饿阈值 (仿真时间): 低优先级10秒, 普通5秒, 高优先级2秒
+func (t *Task) IsStarving(currentSlot uint) bool {
 	if t.Status != TaskPending {
 		return false // 已调度的任务不算饥饿
 	}
 
-	waitTime := t.GetWaitTime()
+	waitTime := t.GetWaitTime(currentSlot)
 	switch {
 	case t.Priority >= PriorityHigh:
 		return waitTime > 2*time.Second
@@ -113,3 +125,23 @@ func (t *Task) IsStarving() bool {
 		return waitTime > 10*time.Second
 	}
 }
+
+// TaskScope 任务访问范围: 账号自己提交的任务，或账号名下用户设备的任务
+// nil表示不限制 (管理员)
+type TaskScope struct {
+	OwnerID uint          // 账号ID
+	UserIDs map[uint]bool // 账号名下的用户设备ID
+}
+
+// Allows 是否可访问指定账号提交、指定用户设备的任务
+func (s *TaskScope) Allows(ownerID, userID uint) bool {
+	if s == nil {
+		return true
+	}
+	return (ownerID != 0 && ownerID == s.OwnerID) || s.UserIDs[userID]
+}
+
+// AllowsTask 是否可访问任务
+func (s *TaskScope) AllowsTask(task *Task) bool {
+	return s.Allows(task.OwnerID, task.UserID)
+}
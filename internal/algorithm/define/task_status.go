@@ -13,6 +13,23 @@ const (
 	TaskFailed                      // 失败
 )
 
+// String 状态名称 (用于日志和监控标签)
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskPending:
+		return "pending"
+	case TaskQueued:
+		return "queued"
+	case TaskComputing:
+		return "computing"
+	case TaskCompleted:
+		return "completed"
+	case TaskFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
 // TaskBase 任务基本信息
 type TaskBase struct {
 	ID        string     `json:"id,omitempty"`
@@ -29,6 +46,8 @@ type TaskBase struct {
 type TaskWithMetrics struct {
 	TaskBase
 
+	OwnerID uint `json:"owner_id,omitempty"` // 提交任务的账号ID
+
 	// 调度结果 (从Assignment填充)
 	AssignedCommID uint          `json:"assigned_comm_id"`
 	TransferPath   *TransferPath `json:"transfer_path"`
@@ -37,6 +56,13 @@ type TaskWithMetrics struct {
 	ScheduledTime time.Time `json:"scheduled_time"`
 	CompleteTime  time.Time `json:"complete_time"`
 
+	// 仿真时间 (由时隙换算，单位: 秒)
+	CreatedSlot    uint    `json:"created_slot"`
+	ScheduledSlot  uint    `json:"scheduled_slot,omitempty"`
+	CompletedSlot  uint    `json:"completed_slot,omitempty"`
+	WaitTime       float64 `json:"wait_time,omitempty"`       // 从提交到首次调度
+	CompletionTime float64 `json:"completion_time,omitempty"` // 从提交到完成
+
 	// 性能指标历史 (从Assignment转换)
 	MetricsHistory []SlotMetrics `json:"metrics_history,omitempty"`
 }
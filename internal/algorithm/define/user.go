@@ -4,29 +4,36 @@ import (
 	"go-backend/internal/algorithm/constant"
 	"go-backend/internal/algorithm/utils"
 	"go-backend/internal/models"
+	pkgutils "go-backend/pkg/utils"
 	"math"
 )
 
 type UserDevice struct {
 	models.Node
 
-	Nearest uint    // 最近的通信设备ID
-	Speed   float64 // 到最近通信设备的传输速率
+	Nearest   uint    // 最近的通信设备ID (覆盖范围内)
+	Speed     float64 // 到最近通信设备的传输速率
+	Connected bool    // 是否处于至少一个通信设备的覆盖范围内
+
+	EnergyBudget float64 // 平均功率预算，单位：W (Properties["energy_budget"]，0表示使用全局配置)
 }
 
 func NewUserDevice(node models.Node) *UserDevice {
-	return &UserDevice{
+	user := &UserDevice{
 		Node: node,
 	}
+	if budget, ok := pkgutils.PropertyFloat(node.Properties, "energy_budget"); ok && budget > 0 {
+		user.EnergyBudget = budget
+	}
+	return user
 }
 
-// CalcNearest 计算最近的通信设备及传输速率
+// CalcNearest 计算覆盖范围内最近的通信设备及传输速率
+// 不在任何设备覆盖范围内的用户标记为未连接
 func (u *UserDevice) CalcNearest(commDevices []*CommDevice) {
-	if len(commDevices) == 0 {
-		u.Nearest = 0
-		u.Speed = 0
-		return
-	}
+	u.Nearest = 0
+	u.Speed = 0
+	u.Connected = false
 
 	minDist := math.Inf(1)
 	nearestID := uint(0)
@@ -34,6 +41,11 @@ func (u *UserDevice) CalcNearest(commDevices []*CommDevice) {
 
 	// 计算到每个通信设备的距离
 	for _, comm := range commDevices {
+		// 超出覆盖半径的设备不能接入
+		if !comm.Covers(u.X, u.Y) {
+			continue
+		}
+
 		// 计算距离
 		d := utils.Distance(u.X, u.Y, comm.X, comm.Y)
 
@@ -48,4 +60,5 @@ func (u *UserDevice) CalcNearest(commDevices []*CommDevice) {
 
 	u.Nearest = nearestID
 	u.Speed = nearestSpeed
+	u.Connected = nearestID != 0
 }
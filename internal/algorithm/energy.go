@@ -0,0 +1,179 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/constant"
+	"go-backend/internal/algorithm/define"
+	"log"
+	"math"
+)
+
+// computeCommEnergy 计算本时隙每个通信设备的能耗 (单位: 焦耳)
+//
+// 能耗组成:
+//   - 悬停: 无人机每时隙固定消耗 E_hold × Slot
+//   - 计算: ResourceFraction × κ × C³ × Slot
+//   - 转发: 以该设备为源的链路段 Power × (TransferredData / Speed)
+func (s *System) computeCommEnergy(assignments []*define.Assignment) map[uint]float64 {
+	energy := make(map[uint]float64, len(s.CommMap))
+
+	for commID, comm := range s.CommMap {
+		if comm.IsUAV {
+			energy[commID] = constant.E_hold * constant.Slot
+		}
+	}
+
+	for _, assign := range assignments {
+		if _, ok := s.CommMap[assign.CommID]; ok && assign.ResourceFraction > 0 {
+			energy[assign.CommID] += assign.ResourceFraction * constant.Kappa * constant.C * constant.C * constant.C * constant.Slot
+		}
+
+		// 第一段由用户发射，从第二段开始由通信设备转发
+		for i := 1; i < len(assign.Path)-1 && i < len(assign.Speeds) && i < len(assign.Powers); i++ {
+			srcID := assign.Path[i]
+			if _, ok := s.CommMap[srcID]; !ok {
+				continue
+			}
+			if assign.Speeds[i] > 0 && assign.TransferredData > 0 {
+				energy[srcID] += assign.Powers[i] * assign.TransferredData / assign.Speeds[i]
+			}
+		}
+	}
+
+	return energy
+}
+
+// computeUserEnergy 计算本时隙每个用户设备的发射能耗 (单位: 焦耳)
+// 用户只负责第一段上行传输: Power × (TransferredData / Speed)
+func (s *System) computeUserEnergy(assignments []*define.Assignment, tasks map[string]*define.Task) map[uint]float64 {
+	energy := make(map[uint]float64)
+
+	for _, assign := range assignments {
+		task := tasks[assign.TaskID]
+		if task == nil || len(assign.Speeds) == 0 || len(assign.Powers) == 0 {
+			continue
+		}
+		if assign.Speeds[0] > 0 && assign.TransferredData > 0 {
+			energy[task.UserID] += assign.Powers[0] * assign.TransferredData / assign.Speeds[0]
+		}
+	}
+
+	return energy
+}
+
+// commEnergyBudget 通信设备每时隙的能耗预算 (单位: 焦耳)，0表示不约束
+func (s *System) commEnergyBudget(commID uint, params define.LyapunovParams) float64 {
+	budget := params.CommEnergyBudget
+	if comm, ok := s.CommMap[commID]; ok && comm.EnergyBudget > 0 {
+		budget = comm.EnergyBudget
+	}
+	return budget * constant.Slot
+}
+
+// userEnergyBudget 用户设备每时隙的能耗预算 (单位: 焦耳)，0表示不约束
+func (s *System) userEnergyBudget(userID uint, params define.LyapunovParams) float64 {
+	budget := params.UserEnergyBudget
+	if user, ok := s.UserMap[userID]; ok && user.EnergyBudget > 0 {
+		budget = user.EnergyBudget
+	}
+	return budget * constant.Slot
+}
+
+// nextEnergyQueues 能耗虚拟队列更新: Z(t+1) = max(Z(t) + E(t) - budget, 0)
+// 只为配置了预算的设备维护队列
+func (ls *LyapunovScheduler) nextEnergyQueues(commEnergy, userEnergy map[uint]float64) (map[string]float64, map[string]float64) {
+	commQueues := make(map[string]float64)
+	for commID := range ls.System.CommMap {
+		if budget := ls.System.commEnergyBudget(commID, ls.current); budget > 0 {
+			key := commKey(commID)
+			commQueues[key] = math.Max(ls.commEnergyQueues[key]+commEnergy[commID]-budget, 0)
+		}
+	}
+
+	userQueues := make(map[string]float64)
+	for userID := range ls.System.UserMap {
+		if budget := ls.System.userEnergyBudget(userID, ls.current); budget > 0 {
+			key := commKey(userID)
+			userQueues[key] = math.Max(ls.userEnergyQueues[key]+userEnergy[userID]-budget, 0)
+		}
+	}
+
+	return commQueues, userQueues
+}
+
+// UpdateEnergyQueues 根据本时隙实际能耗更新能耗虚拟队列
+func (ls *LyapunovScheduler) UpdateEnergyQueues(commEnergy, userEnergy map[uint]float64) {
+	ls.commEnergyQueues, ls.userEnergyQueues = ls.nextEnergyQueues(commEnergy, userEnergy)
+}
+
+// GetEnergyQueues 获取能耗虚拟队列的副本 (通信设备, 用户设备)
+func (ls *LyapunovScheduler) GetEnergyQueues() (map[string]float64, map[string]float64) {
+	commQueues := make(map[string]float64, len(ls.commEnergyQueues))
+	for key, value := range ls.commEnergyQueues {
+		commQueues[key] = value
+	}
+	userQueues := make(map[string]float64, len(ls.userEnergyQueues))
+	for key, value := range ls.userEnergyQueues {
+		userQueues[key] = value
+	}
+	return commQueues, userQueues
+}
+
+// drainBatteries 按本时隙能耗扣减无人机电量
+func (s *System) drainBatteries(energy map[uint]float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	depleted := false
+	for commID, comm := range s.CommMap {
+		if !comm.IsUAV {
+			continue
+		}
+		if comm.Drain(energy[commID]) {
+			log.Printf("⚠️  无人机 %d (%s) 电量耗尽，已退出调度", commID, comm.Name)
+			depleted = true
+		}
+	}
+
+	// 电量耗尽的无人机不再中继，重建最短路径绕开它
+	if depleted {
+		if err := s.buildFloydPaths(); err != nil {
+			log.Printf("⚠️  Floyd路径重建失败: %v", err)
+		}
+	}
+}
+
+// isCommAvailable 通信设备是否可参与调度
+func (s *System) isCommAvailable(commID uint) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	comm, ok := s.CommMap[commID]
+	return ok && comm.IsAvailable()
+}
+
+// isPathAvailable 路径上的通信设备是否都可参与调度 (中继也不能是电量耗尽的无人机)
+func (s *System) isPathAvailable(path []uint) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, nodeID := range path {
+		if comm, ok := s.CommMap[nodeID]; ok && !comm.IsAvailable() {
+			return false
+		}
+	}
+	return true
+}
+
+// availableCommIDs 获取所有可用通信设备ID
+func (s *System) availableCommIDs() []uint {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	commIDs := make([]uint, 0, len(s.CommMap))
+	for commID, comm := range s.CommMap {
+		if comm.IsAvailable() {
+			commIDs = append(commIDs, commID)
+		}
+	}
+	return commIDs
+}
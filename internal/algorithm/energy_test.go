@@ -0,0 +1,55 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/constant"
+	"testing"
+)
+
+// TestDepletedCommExcluded 测试无人机电量耗尽后不再参与任务分配
+func TestDepletedCommExcluded(t *testing.T) {
+	sys := newTestSystem(t)
+
+	// 基站1改为无人机，电量只够悬停2个多时隙
+	drone := sys.CommMap[1]
+	drone.IsUAV = true
+	drone.BatteryCapacity = 2.5 * constant.E_hold * constant.Slot
+	drone.BatteryLevel = drone.BatteryCapacity
+
+	tasks := seedTasks(t, sys, 3)
+	if drone.BatteryLevel != 0 || drone.IsAvailable() {
+		t.Fatalf("3个时隙后无人机电量应耗尽: %.1fJ", drone.BatteryLevel)
+	}
+	for _, commID := range sys.availableCommIDs() {
+		if commID == drone.ID {
+			t.Fatal("电量耗尽的无人机不应在可用设备中")
+		}
+	}
+
+	depleted := sys.TimeSlot
+	for i := 0; i < 3; i++ {
+		sys.executeOneSlot()
+	}
+	assigned := 0
+	for _, task := range tasks {
+		for _, assign := range sys.AssignmentManager.GetHistory(task.ID) {
+			if assign.TimeSlot <= depleted {
+				continue
+			}
+			if assign.CommID == drone.ID {
+				t.Errorf("时隙%d: 任务%s被分配到电量耗尽的无人机", assign.TimeSlot, task.ID)
+			}
+			for _, hop := range assign.Path {
+				if hop == drone.ID {
+					t.Errorf("时隙%d: 任务%s的路径%v经过电量耗尽的无人机", assign.TimeSlot, task.ID, assign.Path)
+				}
+			}
+			assigned++
+		}
+	}
+	if assigned == 0 {
+		t.Error("其他基站应继续分配任务")
+	}
+	if drone.BatteryLevel < 0 {
+		t.Errorf("电量不应低于0: %.1fJ", drone.BatteryLevel)
+	}
+}
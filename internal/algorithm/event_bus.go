@@ -0,0 +1,137 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"sync"
+)
+
+// 订阅者默认缓冲的事件数
+const defaultEventBuffer = 64
+
+// EventFilter 订阅过滤条件 (为空表示接收全部事件)
+type EventFilter struct {
+	UserID *uint
+	TaskID string
+	Scope  *define.TaskScope // 访问范围 (nil表示不限制)
+}
+
+// Subscription 时隙事件订阅
+type Subscription struct {
+	C <-chan *define.SlotEvent
+
+	ch      chan *define.SlotEvent
+	filter  EventFilter
+	dropped uint64
+}
+
+// EventBus 时隙事件总线
+// 发布不阻塞调度循环: 订阅者缓冲区满时丢弃事件并计数
+type EventBus struct {
+	subscribers map[*Subscription]struct{}
+	mutex       sync.Mutex
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe 订阅时隙事件，buffer<=0时使用默认缓冲大小
+func (b *EventBus) Subscribe(filter EventFilter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = defaultEventBuffer
+	}
+	ch := make(chan *define.SlotEvent, buffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+
+	b.mutex.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mutex.Unlock()
+	return sub
+}
+
+// Unsubscribe 取消订阅并关闭通道
+func (b *EventBus) Unsubscribe(sub *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// SubscriberCount 当前订阅者数量
+func (b *EventBus) SubscriberCount() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscribers)
+}
+
+// Publish 向所有订阅者发布事件 (非阻塞)
+func (b *EventBus) Publish(event *define.SlotEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for sub := range b.subscribers {
+		filtered := sub.filter.apply(event)
+		if filtered == nil {
+			continue
+		}
+		filtered.Dropped = sub.dropped
+
+		select {
+		case sub.ch <- filtered:
+		default:
+			// 客户端消费过慢，丢弃本事件，不阻塞调度
+			sub.dropped++
+		}
+	}
+}
+
+// apply 按过滤条件裁剪事件，与订阅者无关时返回nil
+func (f EventFilter) apply(event *define.SlotEvent) *define.SlotEvent {
+	if f.UserID == nil && f.TaskID == "" && f.Scope == nil {
+		copied := *event
+		return &copied
+	}
+
+	match := func(taskID string, userID uint) bool {
+		if f.TaskID != "" && taskID != f.TaskID {
+			return false
+		}
+		if !f.Scope.Allows(event.TaskOwners[taskID], userID) {
+			return false
+		}
+		return f.UserID == nil || userID == *f.UserID
+	}
+
+	filtered := &define.SlotEvent{
+		TimeSlot:    event.TimeSlot,
+		Timestamp:   event.Timestamp,
+		State:       event.State,
+		Transitions: make([]define.TaskTransition, 0),
+		Assignments: make([]*define.Assignment, 0),
+	}
+	// 全局状态包含所有设备的队列和能耗，受限订阅者不可见 (与系统信息、状态历史接口一致)
+	if f.Scope != nil {
+		filtered.State = nil
+	}
+	for _, transition := range event.Transitions {
+		if match(transition.TaskID, transition.UserID) {
+			filtered.Transitions = append(filtered.Transitions, transition)
+		}
+	}
+	for _, assign := range event.Assignments {
+		if match(assign.TaskID, event.TaskUsers[assign.TaskID]) {
+			filtered.Assignments = append(filtered.Assignments, assign)
+		}
+	}
+
+	if len(filtered.Transitions) == 0 && len(filtered.Assignments) == 0 {
+		return nil
+	}
+	return filtered
+}
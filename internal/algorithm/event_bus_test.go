@@ -0,0 +1,94 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"testing"
+	"time"
+)
+
+func TestEventBusSlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(EventFilter{}, 1)
+	defer bus.Unsubscribe(sub)
+
+	done := make(chan struct{})
+	go func() {
+		for slot := uint(1); slot <= 10; slot++ {
+			bus.Publish(&define.SlotEvent{TimeSlot: slot})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("慢订阅者阻塞了发布")
+	}
+
+	first := <-sub.C
+	if first.TimeSlot != 1 {
+		t.Errorf("应收到最早的事件, got slot %d", first.TimeSlot)
+	}
+
+	bus.Publish(&define.SlotEvent{TimeSlot: 11})
+	if next := <-sub.C; next.Dropped != 9 {
+		t.Errorf("丢弃计数应为9, got %d", next.Dropped)
+	}
+}
+
+func TestEventBusFilter(t *testing.T) {
+	bus := NewEventBus()
+	userID := uint(7)
+	sub := bus.Subscribe(EventFilter{UserID: &userID}, 4)
+	defer bus.Unsubscribe(sub)
+
+	bus.Publish(&define.SlotEvent{
+		TimeSlot: 1,
+		Transitions: []define.TaskTransition{
+			{TaskID: "a", UserID: 7, From: define.TaskPending, To: define.TaskQueued},
+			{TaskID: "b", UserID: 8, From: define.TaskPending, To: define.TaskQueued},
+		},
+		Assignments: []*define.Assignment{{TaskID: "a"}, {TaskID: "b"}},
+		TaskUsers:   map[string]uint{"a": 7, "b": 8},
+	})
+	// 与该用户无关的事件不推送
+	bus.Publish(&define.SlotEvent{
+		TimeSlot:    2,
+		Assignments: []*define.Assignment{{TaskID: "b"}},
+		TaskUsers:   map[string]uint{"b": 8},
+	})
+
+	event := <-sub.C
+	if len(event.Transitions) != 1 || len(event.Assignments) != 1 || event.Assignments[0].TaskID != "a" {
+		t.Errorf("过滤结果不正确: %+v", event)
+	}
+	select {
+	case extra := <-sub.C:
+		t.Errorf("不应收到无关事件: slot %d", extra.TimeSlot)
+	default:
+	}
+}
+
+func TestEventBusScopeHidesState(t *testing.T) {
+	bus := NewEventBus()
+	scoped := bus.Subscribe(EventFilter{Scope: &define.TaskScope{OwnerID: 1}}, 4)
+	defer bus.Unsubscribe(scoped)
+	userID := uint(7)
+	filtered := bus.Subscribe(EventFilter{UserID: &userID}, 4)
+	defer bus.Unsubscribe(filtered)
+
+	bus.Publish(&define.SlotEvent{
+		TimeSlot:    1,
+		State:       &define.StateMetrics{},
+		Transitions: []define.TaskTransition{{TaskID: "a", UserID: 7, From: define.TaskPending, To: define.TaskQueued}},
+		TaskUsers:   map[string]uint{"a": 7},
+		TaskOwners:  map[string]uint{"a": 1},
+	})
+
+	if event := <-scoped.C; event.State != nil || len(event.Transitions) != 1 {
+		t.Errorf("受限订阅者应收到自己的任务事件但不含全局状态: %+v", event)
+	}
+	if event := <-filtered.C; event.State == nil {
+		t.Error("不受限的订阅者应收到全局状态")
+	}
+}
@@ -0,0 +1,344 @@
+package algorithm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 实例管理错误
+var (
+	ErrInstanceNotFound  = errors.New("仿真实例不存在")
+	ErrInstanceForbidden = errors.New("无权访问该仿真实例")
+	ErrInstanceLimit     = errors.New("仿真实例数量已达上限")
+)
+
+const (
+	// 默认配额与空闲回收时间
+	defaultMaxInstancesPerUser = 3
+	defaultMaxInstances        = 20
+	defaultInstanceIdleTTL     = 24 * time.Hour
+	// 空闲实例检查间隔
+	instanceJanitorInterval = 10 * time.Minute
+)
+
+// Instance 独立的仿真实例 (拥有独立的拓扑、调度器、任务和时钟)
+type Instance struct {
+	ID        string
+	Name      string
+	OwnerID   uint
+	Owner     string
+	CreatedAt time.Time
+	System    *System
+
+	lastAccess atomic.Int64 // 最近访问时间 (UnixNano)
+}
+
+// InstanceManager 管理用户创建的仿真实例
+// 默认实例即全局System (GetSystemInstance)，不计入配额且不会被回收
+type InstanceManager struct {
+	instances map[string]*Instance
+	mutex     sync.RWMutex
+
+	MaxPerUser int           // 每个用户可创建的实例数
+	MaxTotal   int           // 实例总数上限 (不含默认实例)
+	IdleTTL    time.Duration // 空闲超过该时长的实例自动回收 (0表示不回收)
+
+	janitorOnce sync.Once
+	closeOnce   sync.Once
+	done        chan struct{} // 关闭时停止回收协程
+}
+
+// NewInstanceManager 创建实例管理器 (参数为0时使用默认值)
+func NewInstanceManager(maxPerUser, maxTotal int, idleTTL time.Duration) *InstanceManager {
+	if maxPerUser <= 0 {
+		maxPerUser = defaultMaxInstancesPerUser
+	}
+	if maxTotal <= 0 {
+		maxTotal = defaultMaxInstances
+	}
+	if idleTTL < 0 {
+		idleTTL = defaultInstanceIdleTTL
+	}
+	return &InstanceManager{
+		instances:  make(map[string]*Instance),
+		MaxPerUser: maxPerUser,
+		MaxTotal:   maxTotal,
+		IdleTTL:    idleTTL,
+		done:       make(chan struct{}),
+	}
+}
+
+// Create 从数据库中的当前拓扑创建新实例
+func (m *InstanceManager) Create(opts define.InstanceOptions, ownerID uint, owner string) (*Instance, error) {
+	nodes, links, err := loadTopologyFromDB()
+	if err != nil {
+		return nil, err
+	}
+	sys := NewSystemWithTopology(nodes, links)
+	if !sys.IsInitialized {
+		return nil, errors.New("拓扑无效，系统初始化失败")
+	}
+	return m.Add(sys, opts, ownerID, owner)
+}
+
+// Add 将已构建的系统 (如从快照恢复) 登记为新实例，并应用实例选项
+func (m *InstanceManager) Add(sys *System, opts define.InstanceOptions, ownerID uint, owner string) (*Instance, error) {
+	if opts.Scheduler != "" {
+		if err := sys.SetSchedulerType(opts.Scheduler); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Params) > 0 && string(opts.Params) != "null" {
+		// 在实例当前参数 (新建实例即默认参数) 基础上覆盖提供的字段
+		params, err := sys.GetLyapunovParams()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(opts.Params, &params); err != nil {
+			return nil, fmt.Errorf("无效的Lyapunov参数: %w", err)
+		}
+		if err := sys.SetLyapunovParams(params, owner); err != nil {
+			return nil, err
+		}
+	}
+	if opts.SpeedUp != 0 {
+		if err := sys.SetSpeedUp(opts.SpeedUp); err != nil {
+			return nil, err
+		}
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s的实验", owner)
+	}
+	id, err := newInstanceID()
+	if err != nil {
+		return nil, err
+	}
+	instance := &Instance{
+		ID:        id,
+		Name:      name,
+		OwnerID:   ownerID,
+		Owner:     owner,
+		CreatedAt: time.Now(),
+		System:    sys,
+	}
+	instance.touch()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.instances) >= m.MaxTotal {
+		return nil, fmt.Errorf("%w (总数上限 %d)", ErrInstanceLimit, m.MaxTotal)
+	}
+	owned := 0
+	for _, inst := range m.instances {
+		if inst.OwnerID == ownerID {
+			owned++
+		}
+	}
+	if owned >= m.MaxPerUser {
+		return nil, fmt.Errorf("%w (每个用户最多 %d 个)", ErrInstanceLimit, m.MaxPerUser)
+	}
+
+	m.instances[instance.ID] = instance
+	sys.mutex.Lock()
+	sys.instanceID = instance.ID
+	sys.mutex.Unlock()
+	m.startJanitor()
+	log.Printf("✓ 仿真实例 %s (%s) 已创建 (所有者: %s)", instance.ID, instance.Name, owner)
+	return instance, nil
+}
+
+// Get 按ID获取实例并检查访问权限 (所有者或管理员；默认实例所有人可访问)
+func (m *InstanceManager) Get(id string, userID uint, isAdmin bool) (*Instance, error) {
+	if id == define.DefaultInstanceID {
+		return m.defaultInstance(), nil
+	}
+
+	m.mutex.RLock()
+	instance, exists := m.instances[id]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, ErrInstanceNotFound
+	}
+	if !isAdmin && instance.OwnerID != userID {
+		return nil, ErrInstanceForbidden
+	}
+	instance.touch()
+	return instance, nil
+}
+
+// List 列出用户可访问的实例 (管理员可见全部)，默认实例排在最前
+func (m *InstanceManager) List(userID uint, isAdmin bool) []define.InstanceInfo {
+	m.mutex.RLock()
+	instances := make([]*Instance, 0, len(m.instances))
+	for _, instance := range m.instances {
+		if isAdmin || instance.OwnerID == userID {
+			instances = append(instances, instance)
+		}
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].CreatedAt.Before(instances[j].CreatedAt) })
+
+	infos := make([]define.InstanceInfo, 0, len(instances)+1)
+	infos = append(infos, m.defaultInstance().Info())
+	for _, instance := range instances {
+		infos = append(infos, instance.Info())
+	}
+	return infos
+}
+
+// Delete 停止并删除实例 (默认实例不可删除)
+func (m *InstanceManager) Delete(id string, userID uint, isAdmin bool) error {
+	if id == define.DefaultInstanceID {
+		return errors.New("默认实例不可删除")
+	}
+
+	m.mutex.Lock()
+	instance, exists := m.instances[id]
+	if !exists {
+		m.mutex.Unlock()
+		return ErrInstanceNotFound
+	}
+	if !isAdmin && instance.OwnerID != userID {
+		m.mutex.Unlock()
+		return ErrInstanceForbidden
+	}
+	delete(m.instances, id)
+	m.mutex.Unlock()
+
+	instance.System.Stop()
+	instance.System.History.Close()
+	forgetInstanceMetrics(instance.ID)
+	log.Printf("✓ 仿真实例 %s (%s) 已删除", instance.ID, instance.Name)
+	return nil
+}
+
+// CleanupIdle 回收空闲超过IdleTTL且调度循环未运行的实例，返回回收的实例ID
+func (m *InstanceManager) CleanupIdle(now time.Time) []string {
+	if m.IdleTTL == 0 {
+		return nil
+	}
+
+	m.mutex.Lock()
+	expired := make([]*Instance, 0)
+	for id, instance := range m.instances {
+		if now.Sub(instance.LastAccess()) > m.IdleTTL && instance.System.GetLoopStatus().State != define.LoopRunning {
+			expired = append(expired, instance)
+			delete(m.instances, id)
+		}
+	}
+	m.mutex.Unlock()
+
+	ids := make([]string, len(expired))
+	for i, instance := range expired {
+		instance.System.Stop()
+		instance.System.History.Close()
+		forgetInstanceMetrics(instance.ID)
+		ids[i] = instance.ID
+	}
+	if len(ids) > 0 {
+		log.Printf("✓ 已回收 %d 个空闲仿真实例: %v", len(ids), ids)
+	}
+	return ids
+}
+
+// startJanitor 启动空闲实例回收协程 (首次创建实例时启动，调用方持有mutex)
+func (m *InstanceManager) startJanitor() {
+	if m.IdleTTL == 0 {
+		return
+	}
+	m.janitorOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(instanceJanitorInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case now := <-ticker.C:
+					m.CleanupIdle(now)
+				case <-m.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Close 停止回收协程，并停止所有实例的调度循环 (关闭服务时调用，不含默认实例)
+func (m *InstanceManager) Close() {
+	m.closeOnce.Do(func() { close(m.done) })
+
+	m.mutex.Lock()
+	instances := make([]*Instance, 0, len(m.instances))
+	for id, instance := range m.instances {
+		instances = append(instances, instance)
+		delete(m.instances, id)
+	}
+	m.mutex.Unlock()
+
+	for _, instance := range instances {
+		instance.System.Stop()
+		instance.System.History.Close()
+	}
+	if len(instances) > 0 {
+		log.Printf("✓ 已停止 %d 个仿真实例", len(instances))
+	}
+}
+
+// defaultInstance 包装全局System为默认实例
+func (m *InstanceManager) defaultInstance() *Instance {
+	return &Instance{
+		ID:     define.DefaultInstanceID,
+		Name:   "默认实例",
+		System: GetSystemInstance(),
+	}
+}
+
+// Info 实例信息
+func (i *Instance) Info() define.InstanceInfo {
+	return define.InstanceInfo{
+		ID:           i.ID,
+		Name:         i.Name,
+		OwnerID:      i.OwnerID,
+		Owner:        i.Owner,
+		Scheduler:    i.System.GetSchedulerType(),
+		CreatedAt:    i.CreatedAt,
+		LastAccessAt: i.LastAccess(),
+		LoopStatus:   i.System.GetLoopStatus(),
+	}
+}
+
+// touch 记录访问时间
+func (i *Instance) touch() {
+	i.lastAccess.Store(time.Now().UnixNano())
+}
+
+// LastAccess 最近访问时间
+func (i *Instance) LastAccess() time.Time {
+	if nanos := i.lastAccess.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// newInstanceID 生成实例ID
+func newInstanceID() (string, error) {
+	bytes := make([]byte, 6)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("生成实例ID失败: %w", err)
+	}
+	return "inst-" + hex.EncodeToString(bytes), nil
+}
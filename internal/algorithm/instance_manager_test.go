@@ -0,0 +1,90 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"errors"
+	"go-backend/internal/algorithm/define"
+	"testing"
+	"time"
+)
+
+func TestInstanceManager(t *testing.T) {
+	// 默认实例使用测试系统 (不依赖数据库)
+	prev := globalSystem
+	globalSystem = newTestSystem(t)
+	t.Cleanup(func() { globalSystem = prev })
+
+	m := NewInstanceManager(1, 10, time.Hour)
+
+	// 只提供部分参数，其余字段保持默认值
+	alice, err := m.Add(newTestSystem(t), define.InstanceOptions{Name: "alice-exp", Params: json.RawMessage(`{"v":300}`)}, 1, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GetDefaultLyapunovParams()
+	want.V = 300
+	if got := alice.System.LyapunovScheduler.GetParams(); got != want {
+		t.Errorf("实例参数未按默认值合并: %+v", got)
+	}
+	if _, err := m.Add(newTestSystem(t), define.InstanceOptions{}, 1, "alice"); !errors.Is(err, ErrInstanceLimit) {
+		t.Errorf("超出每用户配额应返回ErrInstanceLimit, got %v", err)
+	}
+	bob, err := m.Add(newTestSystem(t), define.InstanceOptions{Scheduler: "simple"}, 2, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 实例之间互相隔离
+	if _, err := alice.System.Step(2); err != nil {
+		t.Fatal(err)
+	}
+	if bob.System.TimeSlot != 0 || alice.System.TimeSlot != 2 {
+		t.Errorf("实例时钟未隔离: alice=%d bob=%d", alice.System.TimeSlot, bob.System.TimeSlot)
+	}
+
+	// 访问控制
+	if _, err := m.Get(alice.ID, 2, false); !errors.Is(err, ErrInstanceForbidden) {
+		t.Errorf("非所有者访问应被拒绝, got %v", err)
+	}
+	if _, err := m.Get(alice.ID, 2, true); err != nil {
+		t.Errorf("管理员应可访问所有实例: %v", err)
+	}
+	if infos := m.List(2, false); len(infos) != 2 || infos[0].ID != define.DefaultInstanceID || infos[1].ID != bob.ID {
+		t.Errorf("bob的实例列表不正确: %+v", infos)
+	}
+	if err := m.Delete(bob.ID, 1, false); !errors.Is(err, ErrInstanceForbidden) {
+		t.Errorf("非所有者删除应被拒绝, got %v", err)
+	}
+
+	// 空闲回收
+	bob.lastAccess.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	if removed := m.CleanupIdle(time.Now()); len(removed) != 1 || removed[0] != bob.ID {
+		t.Errorf("应回收空闲实例 %s, got %v", bob.ID, removed)
+	}
+	if err := m.Delete(alice.ID, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get(alice.ID, 1, false); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("删除后应返回ErrInstanceNotFound, got %v", err)
+	}
+}
+
+func TestInstanceManagerClose(t *testing.T) {
+	m := NewInstanceManager(0, 0, time.Hour)
+	instance, err := m.Add(newTestSystem(t), define.InstanceOptions{}, 1, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := instance.System.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Close()
+	if state := instance.System.GetLoopStatus().State; state != define.LoopStopped {
+		t.Errorf("关闭后实例调度循环应停止, state=%s", state)
+	}
+	if _, err := m.Get(instance.ID, 1, false); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("关闭后实例应被移除, got %v", err)
+	}
+	m.Close() // 重复关闭不应panic
+}
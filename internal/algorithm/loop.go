@@ -0,0 +1,254 @@
+package algorithm
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// 默认仿真加速比 (1表示与真实时间同步: 每个时隙间隔 constant.Slot 秒)
+	defaultSpeedUpFactor = 1.0
+	// 时隙间隔 (真实时间) 取值范围
+	minTickInterval = 10 * time.Millisecond
+	maxTickInterval = 1 * time.Minute
+	// 单次单步执行的最大时隙数
+	maxStepSlots = 1000
+)
+
+// 新建系统使用的仿真加速比 (启动时由配置文件覆盖)
+var defaultSpeedUp = defaultSpeedUpFactor
+
+// SetDefaultSpeedUp 设置仿真加速比 (需在创建System之前调用)
+func SetDefaultSpeedUp(speedUp float64) error {
+	if err := validateSpeedUp(speedUp); err != nil {
+		return err
+	}
+	defaultSpeedUp = speedUp
+	return nil
+}
+
+// tickIntervalFor 加速比对应的时隙间隔 (真实时间) = 时隙长度 / 加速比
+func tickIntervalFor(speedUp float64) time.Duration {
+	return time.Duration(float64(define.SlotDuration) / speedUp)
+}
+
+// validateSpeedUp 校验加速比，要求对应的时隙间隔在允许范围内
+func validateSpeedUp(speedUp float64) error {
+	minSpeedUp := float64(define.SlotDuration) / float64(maxTickInterval)
+	maxSpeedUp := float64(define.SlotDuration) / float64(minTickInterval)
+	if math.IsNaN(speedUp) || speedUp < minSpeedUp || speedUp > maxSpeedUp {
+		return fmt.Errorf("仿真加速比必须在%g~%g之间", minSpeedUp, maxSpeedUp)
+	}
+	return nil
+}
+
+// Start 启动调度循环 (已暂停时等同于Resume)
+func (s *System) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.IsInitialized {
+		return errors.New("系统未初始化")
+	}
+
+	switch s.loopState {
+	case define.LoopStopped:
+		s.startLoopLocked()
+	case define.LoopPaused:
+		s.loopState = define.LoopRunning
+		s.IsRunning = true
+		log.Println("✓ 调度循环已恢复")
+	}
+	return nil
+}
+
+// Pause 暂停调度循环 (循环协程保持存活，不再执行时隙)
+func (s *System) Pause() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch s.loopState {
+	case define.LoopStopped:
+		return errors.New("调度循环未启动")
+	case define.LoopRunning:
+		s.loopState = define.LoopPaused
+		s.IsRunning = false
+		log.Println("✓ 调度循环已暂停")
+	}
+	return nil
+}
+
+// Resume 恢复已暂停的调度循环
+func (s *System) Resume() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch s.loopState {
+	case define.LoopStopped:
+		return errors.New("调度循环未启动")
+	case define.LoopPaused:
+		s.loopState = define.LoopRunning
+		s.IsRunning = true
+		log.Println("✓ 调度循环已恢复")
+	}
+	return nil
+}
+
+// Stop 停止调度循环，并等待循环协程退出 (之后可再次Start)
+func (s *System) Stop() {
+	s.mutex.Lock()
+	if s.loopState == define.LoopStopped {
+		s.mutex.Unlock()
+		return
+	}
+	done := s.loopDone
+	s.stopLoopLocked()
+	s.mutex.Unlock()
+
+	// 在锁外等待，循环协程可能正在执行时隙
+	<-done
+	log.Println("✓ 调度循环已停止")
+}
+
+// Step 手动执行n个时隙 (仅在停止或暂停状态下可用)
+func (s *System) Step(n int) (int, error) {
+	if n < 1 || n > maxStepSlots {
+		return 0, fmt.Errorf("单步时隙数必须在1~%d之间", maxStepSlots)
+	}
+
+	s.mutex.RLock()
+	initialized := s.IsInitialized
+	state := s.loopState
+	s.mutex.RUnlock()
+
+	if !initialized {
+		return 0, errors.New("系统未初始化")
+	}
+	if state == define.LoopRunning {
+		return 0, errors.New("调度循环正在运行，请先暂停")
+	}
+
+	for i := 0; i < n; i++ {
+		s.runSlot()
+	}
+	log.Printf("✓ 单步执行了 %d 个时隙", n)
+	return n, nil
+}
+
+// SetSpeedUp 修改仿真加速比 (运行中立即生效)，时隙间隔随之变为 Slot/speedUp
+// 任务的等待/超时/饥饿时间按时隙计算，不受加速比影响
+func (s *System) SetSpeedUp(speedUp float64) error {
+	if err := validateSpeedUp(speedUp); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.setSpeedUpLocked(speedUp, tickIntervalFor(speedUp))
+	log.Printf("✓ 仿真加速比已设置为 %gx (时隙间隔 %v)", speedUp, s.tickInterval)
+	return nil
+}
+
+// SetTickInterval 按时隙间隔修改仿真加速比 (运行中立即生效)
+func (s *System) SetTickInterval(interval time.Duration) error {
+	if interval < minTickInterval || interval > maxTickInterval {
+		return fmt.Errorf("时隙间隔必须在%v~%v之间", minTickInterval, maxTickInterval)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.setSpeedUpLocked(float64(define.SlotDuration)/float64(interval), interval)
+	log.Printf("✓ 时隙间隔已设置为 %v (仿真加速比 %gx)", interval, s.speedUp)
+	return nil
+}
+
+// setSpeedUpLocked 更新加速比与时隙间隔，并通知运行中的循环 (调用方持有mutex)
+func (s *System) setSpeedUpLocked(speedUp float64, interval time.Duration) {
+	s.speedUp = speedUp
+	s.tickInterval = interval
+	if s.tickReset != nil {
+		// 只保留最新的间隔
+		select {
+		case <-s.tickReset:
+		default:
+		}
+		s.tickReset <- interval
+	}
+}
+
+// GetLoopStatus 获取调度循环状态
+func (s *System) GetLoopStatus() define.LoopStatus {
+	s.mutex.RLock()
+	status := define.LoopStatus{
+		State:          s.loopState,
+		TimeSlot:       s.TimeSlot,
+		TickIntervalMs: s.tickInterval.Milliseconds(),
+		SpeedUp:        s.speedUp,
+		SimulatedTime:  define.SlotsToDuration(s.TimeSlot).Seconds(),
+	}
+	s.mutex.RUnlock()
+
+	if s.TaskManager != nil {
+		status.ActiveTasks = len(s.TaskManager.GetActiveTasks())
+	}
+	return status
+}
+
+// startLoopLocked 创建本次运行的信号通道并启动循环协程 (调用方持有mutex)
+func (s *System) startLoopLocked() {
+	s.loopStop = make(chan struct{})
+	s.loopDone = make(chan struct{})
+	s.tickReset = make(chan time.Duration, 1)
+	s.loopState = define.LoopRunning
+	s.IsRunning = true
+
+	go s.runSchedulingLoop(s.tickInterval, s.loopStop, s.loopDone, s.tickReset)
+	log.Println("✓ 调度循环已启动")
+}
+
+// stopLoopLocked 发出停止信号 (调用方持有mutex，不等待协程退出)
+func (s *System) stopLoopLocked() {
+	close(s.loopStop)
+	s.loopState = define.LoopStopped
+	s.IsRunning = false
+	s.tickReset = nil
+}
+
+// runSchedulingLoop 调度循环: 按时隙间隔执行，暂停时跳过
+func (s *System) runSchedulingLoop(interval time.Duration, stop chan struct{}, done chan<- struct{}, tickReset <-chan time.Duration) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			log.Println("调度循环停止")
+			return
+		case interval := <-tickReset:
+			ticker.Reset(interval)
+		case <-ticker.C:
+			// 只执行属于本次运行的时隙 (停止后立即重启时旧协程不再执行)
+			s.mutex.RLock()
+			running := s.loopState == define.LoopRunning && s.loopStop == stop
+			s.mutex.RUnlock()
+			if running {
+				s.runSlot()
+			}
+		}
+	}
+}
+
+// runSlot 串行执行一个时隙 (循环与单步共用)
+func (s *System) runSlot() {
+	s.slotMutex.Lock()
+	defer s.slotMutex.Unlock()
+	s.executeOneSlot()
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"math"
 	"math/rand"
+	"strconv"
+	"sync"
 )
 
 // LyapunovScheduler 真正的Lyapunov drift-plus-penalty调度器
@@ -14,64 +16,71 @@ type LyapunovScheduler struct {
 	System            *System
 	AssignmentManager *AssignmentManager
 
-	// Lyapunov优化参数
-	V float64 // 控制参数 (权衡队列稳定性与性能)
+	// Lyapunov优化参数 (V, α, β, γ, Shrink, Iters, Solver)
+	params        define.LyapunovParams
+	current       define.LyapunovParams // 本次求解使用的参数快照 (仅调度协程访问)
+	paramsHistory []define.ParamsChange
+	paramsMutex   sync.RWMutex
+
+	Seed int64 // 模拟退火随机种子 (固定种子保证结果可复现)
 
 	// 上一时隙的队列状态 (用于计算drift)
-	lastCommQueues map[uint]float64
+	lastCommQueues map[string]float64
+
+	// 能耗虚拟队列 (长期平均能耗约束): Z(t+1) = max(Z(t) + E(t) - budget, 0)
+	commEnergyQueues map[string]float64
+	userEnergyQueues map[string]float64
+
+	// 最近一个时隙所选方案的cost组成
+	lastDrift   float64
+	lastPenalty float64
+	lastCost    float64
+	termsMutex  sync.RWMutex
 }
 
 // NewLyapunovScheduler 创建Lyapunov调度器
 func NewLyapunovScheduler(system *System, assignmentManager *AssignmentManager) *LyapunovScheduler {
-	return &LyapunovScheduler{
+	ls := &LyapunovScheduler{
 		System:            system,
 		AssignmentManager: assignmentManager,
-		V:                 constant.V, // 从常量读取
-		lastCommQueues:    make(map[uint]float64),
+		params:            GetDefaultLyapunovParams(),
+		Seed:              1,
+		lastCommQueues:    make(map[string]float64),
+		commEnergyQueues:  make(map[string]float64),
+		userEnergyQueues:  make(map[string]float64),
 	}
+	ls.recordParamsChange(0, "config")
+	return ls
 }
 
 // Schedule 为所有活跃任务寻找最优分配 (Lyapunov drift-plus-penalty)
 //
-// 算法流程:
-// 1. 迭代 constant.Iters 次，每次生成随机的任务→设备分配
-// 2. 对每个分配方案计算:
-//    - Drift = Σ[(Q_i(t+1))² - (Q_i(t))²] (队列长度变化)
-//    - Penalty = α×Delay + β×Energy + γ×Load (性能指标)
-//    - Cost = Drift + V × Penalty
-// 3. 选择Cost最小的分配方案
+// 对每个分配方案计算:
+//   - Drift = Σ[(Q_i(t+1))² - (Q_i(t))²] + w×Σ[(Z_j(t+1))² - (Z_j(t))²] (数据队列 + 能耗虚拟队列)
+//   - Penalty = α×Delay + β×Energy + γ×Load (性能指标)
+//   - Cost = Drift + V × Penalty
+//
+// 由 Solver 指定的求解器最小化Cost (见 lyapunov_solver.go)
 func (ls *LyapunovScheduler) Schedule(timeSlot uint, tasks []*define.Task) []*define.Assignment {
+	// 参数快照: 本时隙内使用同一组参数，运行时修改从下一时隙开始生效
+	ls.current = ls.GetParams()
+
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	bestCost := math.MaxFloat64
-	var bestAssignments []*define.Assignment
-
-	// 多次迭代寻找最优分配
-	for iter := 0; iter < constant.Iters; iter++ {
-		// 生成一个候选分配方案
-		candidateAssignments := ls.generateCandidateAssignments(timeSlot, tasks, iter)
-
-		// 计算该方案的Lyapunov cost
-		cost := ls.computeLyapunovCost(candidateAssignments, tasks)
-
-		// 更新最优解
-		if cost < bestCost {
-			bestCost = cost
-			bestAssignments = candidateAssignments
-		}
+	bestAssignments, bestCost := ls.solve(timeSlot, tasks)
 
-		// 早停: 如果cost已经很小，提前结束
-		if iter > 5 && bestCost < constant.Bias {
-			break
-		}
-	}
+	// 记录所选方案的drift/penalty (供监控导出)
+	drift, penalty := ls.costTerms(bestAssignments, tasks)
+	ls.termsMutex.Lock()
+	ls.lastDrift, ls.lastPenalty, ls.lastCost = drift, penalty, bestCost
+	ls.termsMutex.Unlock()
 
 	// 计算资源分配比例
 	ls.allocateResources(bestAssignments)
 
-	log.Printf("⚡ Lyapunov调度 (时隙%d): 找到最优cost=%.2f (迭代%d次)", timeSlot, bestCost, constant.Iters)
+	log.Printf("⚡ Lyapunov调度 (时隙%d, %s): 找到最优cost=%.2f", timeSlot, ls.current.Solver, bestCost)
 	return bestAssignments
 }
 
@@ -88,7 +97,8 @@ func (ls *LyapunovScheduler) generateCandidateAssignments(timeSlot uint, tasks [
 		// 第一次迭代: 优先复用上次分配
 		if iter == 0 {
 			lastAssign := ls.AssignmentManager.GetLastAssignment(task.ID)
-			if lastAssign != nil && (task.Status == define.TaskQueued || task.Status == define.TaskComputing) {
+			if lastAssign != nil && (task.Status == define.TaskQueued || task.Status == define.TaskComputing) &&
+				ls.System.isCommAvailable(lastAssign.CommID) && ls.System.isPathAvailable(lastAssign.Path) {
 				assign = ls.reuseAssignment(timeSlot, task, lastAssign)
 			}
 		}
@@ -128,29 +138,30 @@ func (ls *LyapunovScheduler) reuseAssignment(timeSlot uint, task *define.Task, l
 
 // randomAssignment 随机分配任务到某个通信设备
 func (ls *LyapunovScheduler) randomAssignment(timeSlot uint, task *define.Task) *define.Assignment {
-	user, ok := ls.System.UserMap[task.UserID]
-	if !ok {
+	// 随机选择一个可用的通信设备 (排除电量耗尽的无人机)
+	commIDs := ls.System.availableCommIDs()
+	if len(commIDs) == 0 {
 		return nil
 	}
+	commID := commIDs[rand.Intn(len(commIDs))]
 
-	// 随机选择一个通信设备
-	commIDs := make([]uint, 0, len(ls.System.CommMap))
-	for commID := range ls.System.CommMap {
-		commIDs = append(commIDs, commID)
-	}
-	if len(commIDs) == 0 {
+	return ls.buildAssignment(timeSlot, task, commID)
+}
+
+// buildAssignment 构建任务到指定通信设备的分配 (保留任务已有的传输/处理进度)
+func (ls *LyapunovScheduler) buildAssignment(timeSlot uint, task *define.Task, commID uint) *define.Assignment {
+	if _, ok := ls.System.UserMap[task.UserID]; !ok {
 		return nil
 	}
-	commID := commIDs[rand.Intn(len(commIDs))]
 
 	// 计算路径
 	path := ls.getPath(task.UserID, commID)
-	if len(path) < 2 {
+	if len(path) < 2 || !ls.System.isPathAvailable(path) {
 		return nil
 	}
 
 	// 获取路径速率和功率
-	speeds, powers := ls.getPathSpeedsAndPowers(path, user.Speed)
+	speeds, powers := ls.getPathSpeedsAndPowers(path, ls.System.uplinkSpeed(task.UserID, path[1]))
 
 	// 获取当前队列状态
 	lastAssign := ls.AssignmentManager.GetLastAssignment(task.ID)
@@ -181,6 +192,16 @@ func (ls *LyapunovScheduler) randomAssignment(timeSlot uint, task *define.Task)
 
 // computeLyapunovCost 计算Lyapunov cost = Drift + V × Penalty
 func (ls *LyapunovScheduler) computeLyapunovCost(assignments []*define.Assignment, tasks []*define.Task) float64 {
+	drift, penalty := ls.costTerms(assignments, tasks)
+
+	// Lyapunov cost
+	cost := drift + ls.current.V*penalty
+
+	return cost
+}
+
+// costTerms 计算分配方案的Drift和Penalty
+func (ls *LyapunovScheduler) costTerms(assignments []*define.Assignment, tasks []*define.Task) (float64, float64) {
 	// 1. 预测执行后的状态
 	predictedState := ls.predictState(assignments, tasks)
 
@@ -190,10 +211,14 @@ func (ls *LyapunovScheduler) computeLyapunovCost(assignments []*define.Assignmen
 	// 3. 计算Penalty (性能指标: 延迟 + 能耗 + 负载)
 	penalty := ls.computePenalty(predictedState)
 
-	// 4. Lyapunov cost
-	cost := drift + ls.V*penalty
+	return drift, penalty
+}
 
-	return cost
+// LastCostTerms 最近一个时隙所选方案的Drift、Penalty和Cost
+func (ls *LyapunovScheduler) LastCostTerms() (drift, penalty, cost float64) {
+	ls.termsMutex.RLock()
+	defer ls.termsMutex.RUnlock()
+	return ls.lastDrift, ls.lastPenalty, ls.lastCost
 }
 
 // predictState 预测执行assignments后的系统状态
@@ -201,7 +226,7 @@ func (ls *LyapunovScheduler) predictState(assignments []*define.Assignment, task
 	state := define.NewStateMetrics()
 
 	// 为每个assignment计算传输量和处理量
-	taskMap := make(map[string]*define.Task)
+	taskMap := make(map[string]*define.Task, len(tasks))
 	for _, t := range tasks {
 		taskMap[t.ID] = t
 	}
@@ -258,7 +283,7 @@ func (ls *LyapunovScheduler) predictState(assignments []*define.Assignment, task
 		if newQueue < 0 {
 			newQueue = 0
 		}
-		state.CommQueues[assign.CommID] += newQueue
+		state.CommQueues[commKey(assign.CommID)] += newQueue
 		state.TotalQueue += newQueue
 
 		// 累加延迟和能耗
@@ -272,10 +297,16 @@ func (ls *LyapunovScheduler) predictState(assignments []*define.Assignment, task
 	state.TotalEnergy = state.TransferEnergy + state.ComputeEnergy
 	state.Load = state.TotalQueue
 
+	// 预测能耗虚拟队列
+	state.CommEnergyQueues, state.UserEnergyQueues = ls.nextEnergyQueues(
+		ls.System.computeCommEnergy(assignments),
+		ls.System.computeUserEnergy(assignments, taskMap),
+	)
+
 	return state
 }
 
-// computeDrift 计算Lyapunov drift = Σ[(Q_i(t+1))² - (Q_i(t))²]
+// computeDrift 计算Lyapunov drift = Σ[(Q_i(t+1))² - (Q_i(t))²] + w×Σ[(Z_j(t+1))² - (Z_j(t))²]
 func (ls *LyapunovScheduler) computeDrift(predictedState *define.StateMetrics) float64 {
 	drift := 0.0
 
@@ -287,17 +318,29 @@ func (ls *LyapunovScheduler) computeDrift(predictedState *define.StateMetrics) f
 		drift += (newQueue*newQueue - oldQueue*oldQueue)
 	}
 
-	return drift / constant.Shrink // 归一化 (防止数值过大)
+	// 能耗虚拟队列: 队列积压越大，超预算的分配代价越高
+	energyDrift := 0.0
+	for key, newQueue := range predictedState.CommEnergyQueues {
+		oldQueue := ls.commEnergyQueues[key]
+		energyDrift += newQueue*newQueue - oldQueue*oldQueue
+	}
+	for key, newQueue := range predictedState.UserEnergyQueues {
+		oldQueue := ls.userEnergyQueues[key]
+		energyDrift += newQueue*newQueue - oldQueue*oldQueue
+	}
+	drift += ls.current.EnergyWeight * energyDrift
+
+	return drift / ls.current.Shrink // 归一化 (防止数值过大)
 }
 
 // computePenalty 计算penalty = α×Delay + β×Energy + γ×Load
 func (ls *LyapunovScheduler) computePenalty(state *define.StateMetrics) float64 {
-	// 使用constant中定义的权重
-	penalty := constant.Alpha*state.TotalDelay +
-		constant.Beta*state.TotalEnergy +
-		constant.Gamma*state.Load
+	// 使用当前生效的权重
+	penalty := ls.current.Alpha*state.TotalDelay +
+		ls.current.Beta*state.TotalEnergy +
+		ls.current.Gamma*state.Load
 
-	return penalty / constant.Shrink // 归一化
+	return penalty / ls.current.Shrink // 归一化
 }
 
 // computeTransferDelay 计算传输延迟 (使用你的原公式)
@@ -367,8 +410,8 @@ func (ls *LyapunovScheduler) allocateResources(assignments []*define.Assignment)
 			queueFactor := assign.QueueData + 1.0
 
 			// 饥饿提升
-			if task.IsStarving() {
-				waitTime := task.GetWaitTime().Seconds()
+			if task.IsStarving(assign.TimeSlot) {
+				waitTime := task.GetWaitTime(assign.TimeSlot).Seconds()
 				priorityFactor *= (1.0 + waitTime/10.0)
 			}
 
@@ -394,7 +437,7 @@ func (ls *LyapunovScheduler) allocateResources(assignments []*define.Assignment)
 // ExecuteAssignments 执行分配，计算实际传输和处理量，更新队列状态
 func (ls *LyapunovScheduler) ExecuteAssignments(assignments []*define.Assignment, tasks map[string]*define.Task) {
 	// 清空上次队列状态
-	ls.lastCommQueues = make(map[uint]float64)
+	ls.lastCommQueues = make(map[string]float64)
 
 	for _, assign := range assignments {
 		task := tasks[assign.TaskID]
@@ -435,7 +478,7 @@ func (ls *LyapunovScheduler) ExecuteAssignments(assignments []*define.Assignment
 		if newQueue < 0 {
 			newQueue = 0
 		}
-		ls.lastCommQueues[assign.CommID] += newQueue
+		ls.lastCommQueues[commKey(assign.CommID)] += newQueue
 	}
 }
 
@@ -493,7 +536,7 @@ func (ls *LyapunovScheduler) getPathSpeedsAndPowers(path []uint, userSpeed float
 			}
 			speeds[i] = bandwidth
 
-			power := constant.P_b
+			power := float64(constant.P_b)
 			if pw, ok := link.Properties["power"].(float64); ok && pw > 0 {
 				power = pw
 			}
@@ -503,3 +546,8 @@ func (ls *LyapunovScheduler) getPathSpeedsAndPowers(path []uint, userSpeed float
 
 	return speeds, powers
 }
+
+// commKey 设备ID对应的队列map key (与StateMetrics中的队列map保持一致)
+func commKey(commID uint) string {
+	return strconv.FormatUint(uint64(commID), 10)
+}
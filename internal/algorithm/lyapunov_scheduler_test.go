@@ -0,0 +1,237 @@
+package algorithm
+
+import (
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/models"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// newTestSystem 构建不依赖数据库的测试系统
+// 4个基站位于正方形四角 (环形互联)，每个基站覆盖附近的2个用户设备
+func newTestSystem(tb testing.TB) *System {
+	tb.Helper()
+	prev := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(prev) })
+
+	corners := [][2]float64{{0, 0}, {400, 0}, {400, 400}, {0, 400}}
+	nodes := make([]models.Node, 0, 12)
+	links := make([]models.Link, 0, 12)
+
+	for i, c := range corners {
+		nodes = append(nodes, models.Node{
+			ID:         uint(i + 1),
+			Name:       fmt.Sprintf("comm-%d", i+1),
+			NodeType:   models.NodeTypeComm,
+			X:          c[0],
+			Y:          c[1],
+			Properties: models.Properties{"coverage_radius": "300m"},
+		})
+		links = append(links, models.Link{
+			SourceID:   uint(i + 1),
+			TargetID:   uint((i+1)%4 + 1),
+			Properties: models.Properties{"bandwidth": 1e8},
+		})
+	}
+
+	for i, c := range corners {
+		for j := 0; j < 2; j++ {
+			id := uint(5 + i*2 + j)
+			nodes = append(nodes, models.Node{
+				ID:       id,
+				Name:     fmt.Sprintf("user-%d", id),
+				NodeType: models.NodeTypeUser,
+				X:        c[0] + float64(30+j*40),
+				Y:        c[1] + 20,
+			})
+			links = append(links, models.Link{SourceID: uint(i + 1), TargetID: id})
+		}
+	}
+
+	sys := NewSystemWithTopology(nodes, links)
+	if !sys.IsInitialized {
+		tb.Fatal("测试系统初始化失败")
+	}
+	return sys
+}
+
+// seedTasks 提交一批任务并运行若干时隙，使部分任务处于排队/计算中
+func seedTasks(tb testing.TB, sys *System, slots int) []*define.Task {
+	tb.Helper()
+	base := time.Unix(0, 0)
+	for i, user := range sys.Users {
+		task := define.NewTaskWithPriority(user.ID, float64(1+i%3)*2e6, "test", (i%3)*5)
+		task.ID = fmt.Sprintf("task-%02d", i)
+		task.CreatedAt = base.Add(time.Duration(i) * time.Second)
+		sys.TaskManager.AddTask(task)
+	}
+	for i := 0; i < slots; i++ {
+		sys.executeOneSlot()
+	}
+	return sys.TaskManager.GetActiveTasks()
+}
+
+// solveWith 使用指定求解策略对当前状态求解 (不修改任务和分配历史)
+func solveWith(tb testing.TB, ls *LyapunovScheduler, solver define.SolverType, timeSlot uint, tasks []*define.Task) ([]*define.Assignment, float64) {
+	tb.Helper()
+	params := ls.GetParams()
+	params.Solver = solver
+	if err := ls.SetParams(params, "test"); err != nil {
+		tb.Fatal(err)
+	}
+	ls.current = ls.GetParams()
+	return ls.solve(timeSlot, tasks)
+}
+
+func TestLyapunovSolverDeterministic(t *testing.T) {
+	for _, solver := range []define.SolverType{define.SolverGreedy, define.SolverLocalSearch, define.SolverAnnealing} {
+		var first []uint
+		for run := 0; run < 2; run++ {
+			sys := newTestSystem(t)
+			tasks := seedTasks(t, sys, 2)
+
+			assignments, _ := solveWith(t, sys.LyapunovScheduler, solver, sys.TimeSlot+1, tasks)
+			comms := make([]uint, len(assignments))
+			for i, assign := range assignments {
+				comms[i] = assign.CommID
+			}
+
+			if run == 0 {
+				first = comms
+				continue
+			}
+			if fmt.Sprint(first) != fmt.Sprint(comms) {
+				t.Errorf("%s: 两次求解结果不一致: %v vs %v", solver, first, comms)
+			}
+		}
+	}
+}
+
+func TestLyapunovSolverRefinement(t *testing.T) {
+	sys := newTestSystem(t)
+	tasks := seedTasks(t, sys, 2)
+
+	cost := make(map[define.SolverType]float64)
+	for _, solver := range []define.SolverType{define.SolverGreedy, define.SolverLocalSearch, define.SolverAnnealing, define.SolverRandom} {
+		_, cost[solver] = solveWith(t, sys.LyapunovScheduler, solver, sys.TimeSlot+1, tasks)
+	}
+	t.Logf("cost: greedy=%.4f local_search=%.4f annealing=%.4f random=%.4f",
+		cost[define.SolverGreedy], cost[define.SolverLocalSearch], cost[define.SolverAnnealing], cost[define.SolverRandom])
+
+	// 局部搜索从贪心解出发，只接受改进；退火保留历史最优
+	if cost[define.SolverLocalSearch] > cost[define.SolverGreedy] {
+		t.Errorf("局部搜索cost %.4f 劣于贪心 %.4f", cost[define.SolverLocalSearch], cost[define.SolverGreedy])
+	}
+	if cost[define.SolverAnnealing] > cost[define.SolverLocalSearch] {
+		t.Errorf("模拟退火cost %.4f 劣于局部搜索 %.4f", cost[define.SolverAnnealing], cost[define.SolverLocalSearch])
+	}
+}
+
+func TestLyapunovParams(t *testing.T) {
+	sys := newTestSystem(t)
+	ls := sys.LyapunovScheduler
+
+	invalid := ls.GetParams()
+	invalid.Shrink = 0
+	if err := ls.SetParams(invalid, "test"); err == nil {
+		t.Error("shrink=0 应被拒绝")
+	}
+
+	params := ls.GetParams()
+	params.V = 500
+	if err := ls.SetParams(params, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	seedTasks(t, sys, 1)
+
+	history := ls.GetParamsHistory()
+	if len(history) != 2 || history[1].ChangedBy != "admin" || history[1].TimeSlot != 1 {
+		t.Errorf("参数变更历史不正确: %+v", history)
+	}
+	if state := sys.CurrentState; state.Params == nil || state.Params.V != 500 || state.TimeSlot != 1 {
+		t.Errorf("状态指标未记录本时隙生效的参数: %+v", state)
+	}
+}
+
+func TestEnergyVirtualQueues(t *testing.T) {
+	sys := newTestSystem(t)
+	ls := sys.LyapunovScheduler
+
+	params := ls.GetParams()
+	params.CommEnergyBudget = 1e6  // 预算充足，队列应保持为0
+	params.UserEnergyBudget = 1e-6 // 预算极小，有上行传输的用户队列应积压
+	if err := ls.SetParams(params, "test"); err != nil {
+		t.Fatal(err)
+	}
+	seedTasks(t, sys, 2)
+
+	state := sys.CurrentState
+	if len(state.CommEnergyQueues) != len(sys.Comms) || len(state.UserEnergyQueues) != len(sys.Users) {
+		t.Fatalf("虚拟队列数量不正确: comm=%d user=%d", len(state.CommEnergyQueues), len(state.UserEnergyQueues))
+	}
+	for key, z := range state.CommEnergyQueues {
+		if z != 0 {
+			t.Errorf("通信设备%s预算充足，虚拟队列应为0: %g", key, z)
+		}
+	}
+	backlogged := 0
+	for _, z := range state.UserEnergyQueues {
+		if z > 0 {
+			backlogged++
+		}
+	}
+	if backlogged == 0 {
+		t.Error("用户设备超出能耗预算，虚拟队列应大于0")
+	}
+}
+
+// benchmarkSolver 对比各求解器的耗时与cost (go test -bench Lyapunov ./internal/algorithm/)
+func benchmarkSolver(b *testing.B, solver define.SolverType) {
+	sys := newTestSystem(b)
+	benchmarkSolve(b, sys, solver, seedTasks(b, sys, 2))
+}
+
+// benchmarkSolverLoad 在每个用户设备积压多个任务时测量单个时隙的求解耗时
+func benchmarkSolverLoad(b *testing.B, solver define.SolverType, tasksPerUser int) {
+	sys := newTestSystem(b)
+	base := time.Unix(0, 0)
+	for i := 0; i < tasksPerUser*len(sys.Users); i++ {
+		user := sys.Users[i%len(sys.Users)]
+		task := define.NewTaskWithPriority(user.ID, float64(1+i%3)*2e6, "bench", (i%3)*5)
+		task.ID = fmt.Sprintf("task-%03d", i)
+		task.CreatedAt = base.Add(time.Duration(i) * time.Millisecond)
+		sys.TaskManager.AddTask(task)
+	}
+	sys.executeOneSlot()
+	benchmarkSolve(b, sys, solver, sys.TaskManager.GetActiveTasks())
+}
+
+// benchmarkSolve 对给定任务反复求解，报告平均cost
+func benchmarkSolve(b *testing.B, sys *System, solver define.SolverType, tasks []*define.Task) {
+	b.Helper()
+	ls := sys.LyapunovScheduler
+	solveWith(b, ls, solver, sys.TimeSlot+1, tasks)
+
+	total := 0.0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, cost := ls.solve(sys.TimeSlot+1, tasks)
+		total += cost
+	}
+	b.ReportMetric(total/float64(b.N), "cost")
+}
+
+func BenchmarkLyapunovRandom(b *testing.B)      { benchmarkSolver(b, define.SolverRandom) }
+func BenchmarkLyapunovGreedy(b *testing.B)      { benchmarkSolver(b, define.SolverGreedy) }
+func BenchmarkLyapunovLocalSearch(b *testing.B) { benchmarkSolver(b, define.SolverLocalSearch) }
+func BenchmarkLyapunovAnnealing(b *testing.B)   { benchmarkSolver(b, define.SolverAnnealing) }
+
+// 240个任务 (8个用户设备 × 30)
+func BenchmarkLyapunovGreedyLoad(b *testing.B) { benchmarkSolverLoad(b, define.SolverGreedy, 30) }
+func BenchmarkLyapunovLocalSearchLoad(b *testing.B) {
+	benchmarkSolverLoad(b, define.SolverLocalSearch, 30)
+}
@@ -0,0 +1,286 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/constant"
+	"go-backend/internal/algorithm/define"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// taskOptions 单个任务的候选分配 (每个可达通信设备一个)
+type taskOptions struct {
+	task    *define.Task
+	options []*define.Assignment
+}
+
+// solve 按配置的求解策略寻找cost最小的分配方案
+// 使用 Schedule 开始时获取的参数快照 ls.current
+func (ls *LyapunovScheduler) solve(timeSlot uint, tasks []*define.Task) ([]*define.Assignment, float64) {
+	if ls.current.Solver == define.SolverRandom {
+		return ls.randomSearch(timeSlot, tasks)
+	}
+
+	candidates := ls.buildTaskOptions(timeSlot, tasks)
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+
+	choice, cost := ls.greedy(candidates, tasks)
+
+	if ls.current.Solver == define.SolverLocalSearch || ls.current.Solver == define.SolverAnnealing {
+		choice, cost = ls.localSearch(candidates, choice, cost, tasks)
+	}
+	if ls.current.Solver == define.SolverAnnealing {
+		choice, cost = ls.anneal(candidates, choice, cost, tasks)
+	}
+
+	return pickAssignments(candidates, choice), cost
+}
+
+// buildTaskOptions 为每个任务生成所有可行的候选分配
+// 任务按 优先级降序 → 创建时间 → ID 排序，设备按ID升序，保证结果确定
+// 已在执行中的任务把当前设备放在首位，cost相同时优先保持不迁移
+func (ls *LyapunovScheduler) buildTaskOptions(timeSlot uint, tasks []*define.Task) []taskOptions {
+	ordered := make([]*define.Task, len(tasks))
+	copy(ordered, tasks)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		if !ordered[i].CreatedAt.Equal(ordered[j].CreatedAt) {
+			return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	commIDs := ls.System.availableCommIDs()
+	sort.Slice(commIDs, func(i, j int) bool { return commIDs[i] < commIDs[j] })
+
+	candidates := make([]taskOptions, 0, len(ordered))
+	for _, task := range ordered {
+		opts := make([]*define.Assignment, 0, len(commIDs))
+
+		currentComm := uint(0)
+		lastAssign := ls.AssignmentManager.GetLastAssignment(task.ID)
+		if lastAssign != nil && (task.Status == define.TaskQueued || task.Status == define.TaskComputing) &&
+			ls.System.isCommAvailable(lastAssign.CommID) && ls.System.isPathAvailable(lastAssign.Path) {
+			currentComm = lastAssign.CommID
+			opts = append(opts, ls.reuseAssignment(timeSlot, task, lastAssign))
+		}
+
+		for _, commID := range commIDs {
+			if commID == currentComm {
+				continue
+			}
+			if assign := ls.buildAssignment(timeSlot, task, commID); assign != nil {
+				opts = append(opts, assign)
+			}
+		}
+
+		if len(opts) > 0 {
+			candidates = append(candidates, taskOptions{task: task, options: opts})
+		}
+	}
+	return candidates
+}
+
+// greedy 按任务顺序逐个加入，每次选择使当前部分方案cost最小的设备 (边际cost)
+func (ls *LyapunovScheduler) greedy(candidates []taskOptions, tasks []*define.Task) ([]int, float64) {
+	choice := make([]int, len(candidates))
+	partial := make([]*define.Assignment, 0, len(candidates))
+	cost := 0.0
+
+	for i, c := range candidates {
+		bestIdx, bestCost := 0, math.MaxFloat64
+		for k, opt := range c.options {
+			trial := ls.computeLyapunovCost(append(partial, opt), tasks)
+			if k == 0 || improves(trial, bestCost) {
+				bestIdx, bestCost = k, trial
+			}
+		}
+		choice[i] = bestIdx
+		partial = append(partial, c.options[bestIdx])
+		cost = bestCost
+	}
+
+	return choice, cost
+}
+
+// 局部搜索的尝试上限 (每次尝试需重新计算整个方案的cost，任务多时避免单个时隙耗时过长)
+const (
+	maxSwapTrialsPerRound = 256  // 每轮交换尝试的任务对数
+	maxLocalSearchTrials  = 2048 // 单个时隙内迁移和交换的总尝试次数
+)
+
+// localSearch 迁移/交换局部搜索，直到没有改进或用完迭代预算
+//   - 迁移: 把单个任务换到另一个设备
+//   - 交换: 两个位于不同设备的任务互换设备 (每轮最多尝试 maxSwapTrialsPerRound 对，下一轮从上次停下的位置继续)
+//
+// 总尝试次数不超过 maxLocalSearchTrials，用完后返回当前最优方案
+func (ls *LyapunovScheduler) localSearch(candidates []taskOptions, choice []int, cost float64, tasks []*define.Task) ([]int, float64) {
+	n := len(candidates)
+	si, sj := 0, 1     // 交换遍历位置 (跨轮次保持)
+	sinceImproved := 0 // 上次改进以来尝试过的任务对数
+	budget := maxLocalSearchTrials
+
+	for round := 0; round < ls.iterBudget() && budget > 0; round++ {
+		improved := false
+
+		// 迁移
+		for i, c := range candidates {
+			for k := range c.options {
+				if k == choice[i] || budget == 0 {
+					continue
+				}
+				budget--
+				old := choice[i]
+				choice[i] = k
+				if trial := ls.computeLyapunovCost(pickAssignments(candidates, choice), tasks); improves(trial, cost) {
+					cost = trial
+					improved = true
+				} else {
+					choice[i] = old
+				}
+			}
+		}
+
+		// 交换
+		for trials := 0; trials < maxSwapTrialsPerRound && budget > 0 && n > 1 && sinceImproved < n*(n-1)/2; trials++ {
+			i, j := si, sj
+			if sj++; sj >= n {
+				si = (si + 1) % (n - 1)
+				sj = si + 1
+			}
+			sinceImproved++
+
+			commI := candidates[i].options[choice[i]].CommID
+			commJ := candidates[j].options[choice[j]].CommID
+			if commI == commJ {
+				continue
+			}
+			ki := optionIndex(candidates[i], commJ)
+			kj := optionIndex(candidates[j], commI)
+			if ki < 0 || kj < 0 {
+				continue
+			}
+
+			budget--
+			oldI, oldJ := choice[i], choice[j]
+			choice[i], choice[j] = ki, kj
+			if trial := ls.computeLyapunovCost(pickAssignments(candidates, choice), tasks); improves(trial, cost) {
+				cost = trial
+				improved = true
+				sinceImproved = 0
+			} else {
+				choice[i], choice[j] = oldI, oldJ
+			}
+		}
+
+		// 本轮没有改进，且所有任务对自上次改进以来都已尝试过
+		if !improved && sinceImproved >= n*(n-1)/2 {
+			break
+		}
+	}
+
+	return choice, cost
+}
+
+// anneal 模拟退火，在局部最优附近继续探索 (固定种子，结果可复现)
+func (ls *LyapunovScheduler) anneal(candidates []taskOptions, choice []int, cost float64, tasks []*define.Task) ([]int, float64) {
+	rng := rand.New(rand.NewSource(ls.Seed))
+
+	current := append([]int(nil), choice...)
+	currentCost := cost
+	best := append([]int(nil), choice...)
+	bestCost := cost
+
+	// 初始温度取当前cost的量级，按几何级数降温
+	temperature := math.Max(math.Abs(cost)*0.1, 1)
+	steps := ls.iterBudget() * len(candidates)
+	cooling := math.Pow(1e-3, 1/float64(steps+1))
+
+	for step := 0; step < steps; step++ {
+		i := rng.Intn(len(candidates))
+		if len(candidates[i].options) < 2 {
+			continue
+		}
+		k := rng.Intn(len(candidates[i].options))
+		if k == current[i] {
+			continue
+		}
+
+		old := current[i]
+		current[i] = k
+		trial := ls.computeLyapunovCost(pickAssignments(candidates, current), tasks)
+		delta := trial - currentCost
+
+		if delta < 0 || rng.Float64() < math.Exp(-delta/temperature) {
+			currentCost = trial
+			if trial < bestCost {
+				bestCost = trial
+				copy(best, current)
+			}
+		} else {
+			current[i] = old
+		}
+		temperature *= cooling
+	}
+
+	return best, bestCost
+}
+
+// randomSearch 随机搜索 (原实现): 采样 Iters 个随机方案取cost最小者
+func (ls *LyapunovScheduler) randomSearch(timeSlot uint, tasks []*define.Task) ([]*define.Assignment, float64) {
+	bestCost := math.MaxFloat64
+	var bestAssignments []*define.Assignment
+
+	for iter := 0; iter < ls.iterBudget(); iter++ {
+		candidateAssignments := ls.generateCandidateAssignments(timeSlot, tasks, iter)
+		cost := ls.computeLyapunovCost(candidateAssignments, tasks)
+
+		if cost < bestCost {
+			bestCost = cost
+			bestAssignments = candidateAssignments
+		}
+
+		// 早停: 如果cost已经很小，提前结束
+		if iter > 5 && bestCost < constant.Bias {
+			break
+		}
+	}
+
+	return bestAssignments, bestCost
+}
+
+// iterBudget 迭代预算 (至少1次)
+func (ls *LyapunovScheduler) iterBudget() int {
+	if ls.current.Iters < 1 {
+		return 1
+	}
+	return ls.current.Iters
+}
+
+// improves 判断新cost是否显著优于当前cost (相对容差，避免浮点抖动导致来回迁移)
+func improves(trial, current float64) bool {
+	return trial < current-1e-9*math.Max(1, math.Abs(current))
+}
+
+// pickAssignments 根据选择下标组装分配方案
+func pickAssignments(candidates []taskOptions, choice []int) []*define.Assignment {
+	assignments := make([]*define.Assignment, len(candidates))
+	for i, c := range candidates {
+		assignments[i] = c.options[choice[i]]
+	}
+	return assignments
+}
+
+// optionIndex 查找任务在指定设备上的候选下标，不存在返回-1
+func optionIndex(c taskOptions, commID uint) int {
+	for k, opt := range c.options {
+		if opt.CommID == commID {
+			return k
+		}
+	}
+	return -1
+}
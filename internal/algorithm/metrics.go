@@ -0,0 +1,104 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"go-backend/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 调度循环指标 (在executeOneSlot中按实例记录)
+var (
+	slotDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scheduler_slot_duration_seconds",
+		Help:    "单个时隙的调度执行耗时",
+		Buckets: metrics.SlotBuckets,
+	}, []string{"instance"})
+	slotsTotal = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_slots_total",
+		Help: "已执行的时隙总数",
+	}, []string{"instance"})
+)
+
+// forgetInstanceMetrics 删除已回收实例的调度循环指标
+func forgetInstanceMetrics(instanceID string) {
+	slotDuration.DeleteLabelValues(instanceID)
+	slotsTotal.DeleteLabelValues(instanceID)
+}
+
+var (
+	tasksDesc = prometheus.NewDesc("scheduler_tasks", "按状态统计的任务数",
+		[]string{"instance", "status"}, nil)
+	commQueueDesc = prometheus.NewDesc("scheduler_comm_queue_bits", "每个通信设备的队列长度 (比特)",
+		[]string{"instance", "comm_id"}, nil)
+	batteryDesc = prometheus.NewDesc("scheduler_uav_battery_ratio", "无人机剩余电量比例",
+		[]string{"instance", "comm_id"}, nil)
+	timeSlotDesc = prometheus.NewDesc("scheduler_time_slot", "当前时隙",
+		[]string{"instance"}, nil)
+	runningDesc = prometheus.NewDesc("scheduler_running", "调度循环是否运行中 (1=运行)",
+		[]string{"instance"}, nil)
+	driftDesc = prometheus.NewDesc("scheduler_lyapunov_drift", "最近时隙所选方案的Lyapunov drift",
+		[]string{"instance"}, nil)
+	penaltyDesc = prometheus.NewDesc("scheduler_lyapunov_penalty", "最近时隙所选方案的Lyapunov penalty",
+		[]string{"instance"}, nil)
+	costDesc = prometheus.NewDesc("scheduler_lyapunov_cost", "最近时隙所选方案的Lyapunov cost (drift + V×penalty)",
+		[]string{"instance"}, nil)
+)
+
+// systemCollector 采集时实时读取当前系统的状态指标
+type systemCollector struct {
+	current func() *System
+}
+
+// RegisterMetrics 注册系统状态指标 (导出时通过current获取当前实例并实时读取)
+func RegisterMetrics(reg prometheus.Registerer, current func() *System) {
+	reg.MustRegister(&systemCollector{current: current})
+}
+
+func (c *systemCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		tasksDesc, commQueueDesc, batteryDesc, timeSlotDesc, runningDesc, driftDesc, penaltyDesc, costDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *systemCollector) Collect(ch chan<- prometheus.Metric) {
+	sys := c.current()
+
+	sys.mutex.RLock()
+	instance := sys.instanceID
+	timeSlot := float64(sys.TimeSlot)
+	running := 0.0
+	if sys.IsRunning {
+		running = 1
+	}
+	state := sys.CurrentState
+	sys.mutex.RUnlock()
+
+	counts := sys.TaskManager.CountByStatus()
+	for _, status := range []define.TaskStatus{
+		define.TaskPending, define.TaskQueued, define.TaskComputing, define.TaskCompleted, define.TaskFailed,
+	} {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(counts[status]), instance, status.String())
+	}
+
+	if state != nil {
+		for id, value := range state.CommQueues {
+			ch <- prometheus.MustNewConstMetric(commQueueDesc, prometheus.GaugeValue, value, instance, id)
+		}
+		for id, value := range state.BatteryLevels {
+			ch <- prometheus.MustNewConstMetric(batteryDesc, prometheus.GaugeValue, value, instance, id)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(timeSlotDesc, prometheus.GaugeValue, timeSlot, instance)
+	ch <- prometheus.MustNewConstMetric(runningDesc, prometheus.GaugeValue, running, instance)
+
+	if sys.LyapunovScheduler != nil {
+		drift, penalty, cost := sys.LyapunovScheduler.LastCostTerms()
+		ch <- prometheus.MustNewConstMetric(driftDesc, prometheus.GaugeValue, drift, instance)
+		ch <- prometheus.MustNewConstMetric(penaltyDesc, prometheus.GaugeValue, penalty, instance)
+		ch <- prometheus.MustNewConstMetric(costDesc, prometheus.GaugeValue, cost, instance)
+	}
+}
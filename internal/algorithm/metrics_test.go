@@ -0,0 +1,71 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"go-backend/pkg/metrics"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gatherSeries 采集注册表，返回指定指标族中每个instance标签的样本
+func gatherSeries(t *testing.T, reg prometheus.Gatherer, name string) map[string][]*dto.Metric {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "instance" {
+					series[label.GetValue()] = append(series[label.GetValue()], metric)
+				}
+			}
+		}
+	}
+	return series
+}
+
+// TestMetricsInstanceLabel 测试调度指标按实例区分，实例回收后删除对应的指标
+func TestMetricsInstanceLabel(t *testing.T) {
+	sys := newTestSystem(t)
+	reg := prometheus.NewRegistry()
+	RegisterMetrics(reg, func() *System { return sys })
+	seedTasks(t, sys, 1)
+
+	tasks := gatherSeries(t, reg, "scheduler_tasks")[define.DefaultInstanceID]
+	if len(tasks) != 5 {
+		t.Fatalf("默认实例应按5种状态导出任务数, got %d", len(tasks))
+	}
+
+	m := NewInstanceManager(1, 10, time.Hour)
+	instance, err := m.Add(newTestSystem(t), define.InstanceOptions{}, 1, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instance.System.Step(3); err != nil {
+		t.Fatal(err)
+	}
+
+	slots := gatherSeries(t, metrics.Default, "scheduler_slots_total")
+	if got := slots[instance.ID]; len(got) != 1 || got[0].GetCounter().GetValue() != 3 {
+		t.Fatalf("实例 %s 应记录3个时隙: %v", instance.ID, got)
+	}
+	if len(gatherSeries(t, metrics.Default, "scheduler_slot_duration_seconds")[instance.ID]) != 1 {
+		t.Error("应按实例记录时隙耗时")
+	}
+
+	if err := m.Delete(instance.ID, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := gatherSeries(t, metrics.Default, "scheduler_slots_total")[instance.ID]; exists {
+		t.Error("实例删除后应删除其指标")
+	}
+}
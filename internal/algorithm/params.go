@@ -0,0 +1,113 @@
+package algorithm
+
+import (
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"log"
+	"sync"
+	"time"
+)
+
+// 参数变更历史保留条数
+const maxParamsHistory = 200
+
+// 新建调度器使用的默认参数 (启动时由配置文件覆盖)
+var (
+	defaultParams      = define.DefaultLyapunovParams()
+	defaultParamsMutex sync.RWMutex
+)
+
+// SetDefaultLyapunovParams 设置默认Lyapunov参数 (需在创建System之前调用)
+func SetDefaultLyapunovParams(params define.LyapunovParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	defaultParamsMutex.Lock()
+	defaultParams = params
+	defaultParamsMutex.Unlock()
+	return nil
+}
+
+// GetDefaultLyapunovParams 获取默认Lyapunov参数
+func GetDefaultLyapunovParams() define.LyapunovParams {
+	defaultParamsMutex.RLock()
+	defer defaultParamsMutex.RUnlock()
+	return defaultParams
+}
+
+// GetParams 获取当前配置的参数
+func (ls *LyapunovScheduler) GetParams() define.LyapunovParams {
+	ls.paramsMutex.RLock()
+	defer ls.paramsMutex.RUnlock()
+	return ls.params
+}
+
+// SetParams 校验并更新参数，从下一时隙开始生效
+func (ls *LyapunovScheduler) SetParams(params define.LyapunovParams, changedBy string) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	effectiveSlot := uint(1)
+	if ls.System != nil {
+		ls.System.mutex.RLock()
+		effectiveSlot = ls.System.TimeSlot + 1
+		ls.System.mutex.RUnlock()
+	}
+
+	ls.paramsMutex.Lock()
+	ls.params = params
+	ls.recordParamsChange(effectiveSlot, changedBy)
+	ls.paramsMutex.Unlock()
+
+	log.Printf("✓ Lyapunov参数已更新 (时隙%d生效, 操作人: %s): V=%g α=%g β=%g γ=%g Shrink=%g Iters=%d Solver=%s",
+		effectiveSlot, changedBy, params.V, params.Alpha, params.Beta, params.Gamma, params.Shrink, params.Iters, params.Solver)
+	return nil
+}
+
+// GetParamsHistory 获取参数变更历史 (按时间升序)
+func (ls *LyapunovScheduler) GetParamsHistory() []define.ParamsChange {
+	ls.paramsMutex.RLock()
+	defer ls.paramsMutex.RUnlock()
+
+	history := make([]define.ParamsChange, len(ls.paramsHistory))
+	copy(history, ls.paramsHistory)
+	return history
+}
+
+// recordParamsChange 记录参数变更 (调用方持有paramsMutex)
+func (ls *LyapunovScheduler) recordParamsChange(effectiveSlot uint, changedBy string) {
+	ls.paramsHistory = append(ls.paramsHistory, define.ParamsChange{
+		TimeSlot:  effectiveSlot,
+		ChangedAt: time.Now(),
+		ChangedBy: changedBy,
+		Params:    ls.params,
+	})
+	if len(ls.paramsHistory) > maxParamsHistory {
+		ls.paramsHistory = ls.paramsHistory[len(ls.paramsHistory)-maxParamsHistory:]
+	}
+}
+
+// GetLyapunovParams 获取系统当前的Lyapunov参数
+func (s *System) GetLyapunovParams() (define.LyapunovParams, error) {
+	if s.LyapunovScheduler == nil {
+		return define.LyapunovParams{}, fmt.Errorf("系统未初始化")
+	}
+	return s.LyapunovScheduler.GetParams(), nil
+}
+
+// SetLyapunovParams 运行时修改Lyapunov参数
+func (s *System) SetLyapunovParams(params define.LyapunovParams, changedBy string) error {
+	if s.LyapunovScheduler == nil {
+		return fmt.Errorf("系统未初始化")
+	}
+	return s.LyapunovScheduler.SetParams(params, changedBy)
+}
+
+// GetLyapunovParamsHistory 获取Lyapunov参数变更历史
+func (s *System) GetLyapunovParamsHistory() []define.ParamsChange {
+	if s.LyapunovScheduler == nil {
+		return []define.ParamsChange{}
+	}
+	return s.LyapunovScheduler.GetParamsHistory()
+}
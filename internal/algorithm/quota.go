@@ -0,0 +1,74 @@
+package algorithm
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+)
+
+// ErrQuotaExceeded 超出账号任务配额
+var ErrQuotaExceeded = errors.New("超出任务配额")
+
+// 新建系统使用的任务配额 (启动时由配置文件覆盖，默认不限制)
+var defaultTaskQuota define.TaskQuota
+
+// SetDefaultTaskQuota 设置每个账号的任务配额 (需在创建System之前调用)
+func SetDefaultTaskQuota(quota define.TaskQuota) error {
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+	defaultTaskQuota = quota
+	return nil
+}
+
+// TaskQuota 获取当前任务配额
+func (s *System) TaskQuota() define.TaskQuota {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.quota
+}
+
+// SetTaskQuota 修改任务配额 (只影响之后提交的任务)
+func (s *System) SetTaskQuota(quota define.TaskQuota) error {
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.quota = quota
+	return nil
+}
+
+// QuotaUsage 获取账号的配额使用情况
+func (s *System) QuotaUsage(owner define.TaskOwner) define.QuotaUsage {
+	count, data := s.TaskManager.OwnerUsage(owner.ID)
+	return define.QuotaUsage{
+		OwnerID:     owner.ID,
+		ActiveTasks: count,
+		ActiveData:  data,
+		Quota:       s.TaskQuota(),
+		Unlimited:   owner.Unlimited,
+	}
+}
+
+// CheckQuota 检查账号再提交count个、共data数据量的任务是否超出配额
+func (s *System) CheckQuota(owner define.TaskOwner, count int, data float64) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.checkQuotaLocked(owner, count, data)
+}
+
+// checkQuotaLocked 检查配额 (调用方持有s.mutex)
+func (s *System) checkQuotaLocked(owner define.TaskOwner, count int, data float64) error {
+	if owner.ID == 0 || owner.Unlimited {
+		return nil
+	}
+	activeTasks, activeData := s.TaskManager.OwnerUsage(owner.ID)
+	if s.quota.MaxActiveTasks > 0 && activeTasks+count > s.quota.MaxActiveTasks {
+		return fmt.Errorf("%w: 未结束任务数 %d+%d 超过上限 %d", ErrQuotaExceeded, activeTasks, count, s.quota.MaxActiveTasks)
+	}
+	if s.quota.MaxActiveData > 0 && activeData+data > s.quota.MaxActiveData {
+		return fmt.Errorf("%w: 未结束任务数据量 %.2f+%.2f 超过上限 %.2f", ErrQuotaExceeded, activeData, data, s.quota.MaxActiveData)
+	}
+	return nil
+}
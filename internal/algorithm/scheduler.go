@@ -45,7 +45,18 @@ func (s *Scheduler) scheduleTask(timeSlot uint, task *define.Task) *define.Assig
 
 	// 情况1: 任务已经有分配 (Queued/Computing状态) - 复用路径!
 	if lastAssign != nil && (sm.IsQueued() || sm.IsComputing()) {
-		return s.reuseAssignment(timeSlot, task, lastAssign)
+		if s.System.isCommAvailable(lastAssign.CommID) && s.System.isPathAvailable(lastAssign.Path) {
+			return s.reuseAssignment(timeSlot, task, lastAssign)
+		}
+
+		// 原通信设备不可用 (无人机电量耗尽)，迁移到新设备并保留进度
+		assign := s.findBestAssignment(timeSlot, task)
+		if assign != nil {
+			assign.QueueData = s.AssignmentManager.GetCurrentQueue(task.ID, task.DataSize)
+			assign.CumulativeTransferred = lastAssign.CumulativeTransferred
+			assign.CumulativeProcessed = lastAssign.CumulativeProcessed
+		}
+		return assign
 	}
 
 	// 情况2: 新任务 (Pending状态) - 寻找最佳路径
@@ -81,7 +92,7 @@ func (s *Scheduler) reuseAssignment(timeSlot uint, task *define.Task, lastAssign
 
 // findBestAssignment 为新任务寻找最佳分配 (简化的调度算法)
 func (s *Scheduler) findBestAssignment(timeSlot uint, task *define.Task) *define.Assignment {
-	user, ok := s.System.UserMap[task.UserID]
+	_, ok := s.System.UserMap[task.UserID]
 	if !ok {
 		log.Printf("用户不存在: %d", task.UserID)
 		return nil
@@ -90,16 +101,16 @@ func (s *Scheduler) findBestAssignment(timeSlot uint, task *define.Task) *define
 	var bestAssign *define.Assignment
 	bestCost := math.MaxFloat64
 
-	// 遍历所有通信设备,找到最低cost的分配
-	for commID := range s.System.CommMap {
+	// 遍历所有可用通信设备,找到最低cost的分配
+	for _, commID := range s.System.availableCommIDs() {
 		// 计算路径
 		path := s.getPath(task.UserID, commID)
-		if len(path) < 2 {
+		if len(path) < 2 || !s.System.isPathAvailable(path) {
 			continue
 		}
 
 		// 获取路径的速率和功率
-		speeds, powers := s.getPathSpeedsAndPowers(path, user.Speed)
+		speeds, powers := s.getPathSpeedsAndPowers(path, s.System.uplinkSpeed(task.UserID, path[1]))
 
 		// 创建临时分配
 		assign := &define.Assignment{
@@ -182,9 +193,9 @@ func (s *Scheduler) allocateResources(assignments []*define.Assignment) {
 		priorityFactor := float64(task.Priority)/10.0 + 1.0
 
 		// 饥饿提升: 如果任务等待过久,提升优先级
-		if task.IsStarving() {
-			waitTime := task.GetWaitTime().Seconds()
-			starvationBoost := 1.0 + (waitTime / 10.0) // 每10秒增加1倍权重
+		if task.IsStarving(assign.TimeSlot) {
+			waitTime := task.GetWaitTime(assign.TimeSlot).Seconds()
+			starvationBoost := 1.0 + (waitTime / 10.0) // 每10秒(仿真时间)增加1倍权重
 			priorityFactor *= starvationBoost
 			// log.Printf("⚠️  任务 %s 饥饿提升: %.2fx (等待%.1fs)", task.ID, starvationBoost, waitTime)
 		}
@@ -0,0 +1,320 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/models"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 快照文件扩展名
+const snapshotExt = ".json"
+
+// 快照名称只允许字母、数字、点、下划线和连字符 (防止路径穿越)
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Snapshot 导出系统完整状态 (在两个时隙之间获取，保证一致性)
+func (s *System) Snapshot() (*define.Snapshot, error) {
+	// 阻止时隙执行，避免导出过程中状态被修改
+	s.slotMutex.Lock()
+	defer s.slotMutex.Unlock()
+
+	s.mutex.RLock()
+	if !s.IsInitialized {
+		s.mutex.RUnlock()
+		return nil, errors.New("系统未初始化")
+	}
+	quota := s.quota
+	snap := &define.Snapshot{
+		Version:       define.SnapshotVersion,
+		CreatedAt:     time.Now(),
+		TimeSlot:      s.TimeSlot,
+		UseLyapunov:   s.UseLyapunov,
+		SpeedUp:       s.speedUp,
+		State:         s.CurrentState,
+		Quota:         &quota,
+		Nodes:         make([]models.Node, 0, len(s.Users)+len(s.Comms)),
+		Links:         make([]models.Link, 0, len(s.LinkMap)),
+		BatteryLevels: make(map[uint]float64),
+	}
+	for _, comm := range s.Comms {
+		snap.Nodes = append(snap.Nodes, comm.Node)
+		if comm.IsUAV {
+			snap.BatteryLevels[comm.ID] = comm.BatteryLevel
+		}
+	}
+	for _, user := range s.Users {
+		snap.Nodes = append(snap.Nodes, user.Node)
+	}
+	for _, link := range s.LinkMap {
+		l := *link
+		l.Source, l.Target = models.Node{}, models.Node{} // 关联节点已包含在Nodes中
+		snap.Links = append(snap.Links, l)
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(snap.Nodes, func(i, j int) bool { return snap.Nodes[i].ID < snap.Nodes[j].ID })
+	sort.Slice(snap.Links, func(i, j int) bool {
+		if snap.Links[i].SourceID != snap.Links[j].SourceID {
+			return snap.Links[i].SourceID < snap.Links[j].SourceID
+		}
+		return snap.Links[i].TargetID < snap.Links[j].TargetID
+	})
+
+	// 任务 (复制，避免序列化时与调度并发读写)
+	s.TaskManager.mutex.RLock()
+	snap.Tasks = make([]*define.Task, len(s.TaskManager.TaskList))
+	for i, task := range s.TaskManager.TaskList {
+		cp := *task
+		snap.Tasks[i] = &cp
+	}
+	s.TaskManager.mutex.RUnlock()
+
+	// 分配历史
+	s.AssignmentManager.mutex.RLock()
+	snap.Assignments = make(map[string][]*define.Assignment, len(s.AssignmentManager.History))
+	for taskID, history := range s.AssignmentManager.History {
+		copied := make([]*define.Assignment, len(history))
+		for i, assign := range history {
+			copied[i] = assign.Copy()
+		}
+		snap.Assignments[taskID] = copied
+	}
+	s.AssignmentManager.mutex.RUnlock()
+
+	// 调度器内部状态 (持有slotMutex，调度协程不会并发修改)
+	ls := s.LyapunovScheduler
+	snap.Scheduler = define.SchedulerSnapshot{
+		Params:           ls.GetParams(),
+		ParamsHistory:    ls.GetParamsHistory(),
+		Seed:             ls.Seed,
+		LastCommQueues:   copyQueues(ls.lastCommQueues),
+		CommEnergyQueues: copyQueues(ls.commEnergyQueues),
+		UserEnergyQueues: copyQueues(ls.userEnergyQueues),
+	}
+
+	return snap, nil
+}
+
+// RestoreSystem 从快照重建新的系统实例 (调度循环处于停止状态)
+func RestoreSystem(snap *define.Snapshot) (*System, error) {
+	if err := checkSnapshot(snap); err != nil {
+		return nil, err
+	}
+
+	sys := NewSystemWithTopology(snap.Nodes, snap.Links)
+	if !sys.IsInitialized {
+		return nil, errors.New("快照中的拓扑无效，系统初始化失败")
+	}
+
+	sys.TimeSlot = snap.TimeSlot
+	sys.UseLyapunov = snap.UseLyapunov
+	if snap.State != nil {
+		sys.CurrentState = snap.State
+	}
+	if snap.Quota != nil {
+		sys.quota = *snap.Quota
+	}
+	if validateSpeedUp(snap.SpeedUp) == nil {
+		sys.speedUp = snap.SpeedUp
+		sys.tickInterval = tickIntervalFor(snap.SpeedUp)
+	}
+	for commID, level := range snap.BatteryLevels {
+		if comm := sys.CommMap[commID]; comm != nil && comm.IsUAV {
+			comm.BatteryLevel = level
+		}
+	}
+
+	for _, task := range snap.Tasks {
+		if task == nil {
+			continue
+		}
+		sys.TaskManager.AddTask(task)
+	}
+	for _, history := range snap.Assignments {
+		for _, assign := range history {
+			if assign != nil {
+				sys.AssignmentManager.AddAssignment(assign)
+			}
+		}
+	}
+
+	ls := sys.LyapunovScheduler
+	ls.params = snap.Scheduler.Params
+	if len(snap.Scheduler.ParamsHistory) > 0 {
+		ls.paramsHistory = snap.Scheduler.ParamsHistory
+	}
+	ls.Seed = snap.Scheduler.Seed
+	ls.lastCommQueues = copyQueues(snap.Scheduler.LastCommQueues)
+	ls.commEnergyQueues = copyQueues(snap.Scheduler.CommEnergyQueues)
+	ls.userEnergyQueues = copyQueues(snap.Scheduler.UserEnergyQueues)
+
+	log.Printf("✓ 已从快照恢复系统 (时隙:%d, 任务:%d)", snap.TimeSlot, len(snap.Tasks))
+	return sys, nil
+}
+
+// checkSnapshot 校验快照版本和必要字段
+func checkSnapshot(snap *define.Snapshot) error {
+	if snap == nil {
+		return errors.New("快照为空")
+	}
+	if snap.Version < 1 || snap.Version > define.SnapshotVersion {
+		return fmt.Errorf("不支持的快照版本: %d (当前版本: %d)", snap.Version, define.SnapshotVersion)
+	}
+	if len(snap.Nodes) == 0 {
+		return errors.New("快照中没有拓扑节点")
+	}
+	if err := snap.Scheduler.Params.Validate(); err != nil {
+		return fmt.Errorf("快照中的Lyapunov参数无效: %w", err)
+	}
+	if snap.Quota != nil {
+		if err := snap.Quota.Validate(); err != nil {
+			return fmt.Errorf("快照中的任务配额无效: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteSnapshot 以JSON格式写出快照
+func WriteSnapshot(w io.Writer, snap *define.Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snap)
+}
+
+// ReadSnapshot 读取并校验快照
+func ReadSnapshot(r io.Reader) (*define.Snapshot, error) {
+	var snap define.Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("解析快照失败: %w", err)
+	}
+	if err := checkSnapshot(&snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// SaveSnapshot 将系统快照保存到文件 (先写临时文件再重命名，避免写出不完整的快照)
+func (s *System) SaveSnapshot(path string) (*define.SnapshotInfo, error) {
+	snap, err := s.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建快照目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteSnapshot(tmp, snap); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("保存快照失败: %w", err)
+	}
+
+	info, err := snapshotFileInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	info.TimeSlot = snap.TimeSlot
+	info.TaskCount = len(snap.Tasks)
+	log.Printf("✓ 系统快照已保存: %s (时隙:%d, 任务:%d)", path, snap.TimeSlot, len(snap.Tasks))
+	return info, nil
+}
+
+// LoadSnapshot 从文件读取快照
+func LoadSnapshot(path string) (*define.Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开快照文件失败: %w", err)
+	}
+	defer file.Close()
+	return ReadSnapshot(file)
+}
+
+// SnapshotPath 根据名称得到快照目录中的文件路径
+func SnapshotPath(dir, name string) (string, error) {
+	name = strings.TrimSuffix(name, snapshotExt)
+	if !snapshotNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("无效的快照名称: %s", name)
+	}
+	return filepath.Join(dir, name+snapshotExt), nil
+}
+
+// ListSnapshots 列出快照目录中的快照文件 (按时间倒序)
+func ListSnapshots(dir string) ([]define.SnapshotInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []define.SnapshotInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取快照目录失败: %w", err)
+	}
+
+	infos := make([]define.SnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != snapshotExt {
+			continue
+		}
+		info, err := snapshotFileInfo(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.After(infos[j].CreatedAt) })
+	return infos, nil
+}
+
+func snapshotFileInfo(path string) (*define.SnapshotInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &define.SnapshotInfo{
+		Name:      strings.TrimSuffix(filepath.Base(path), snapshotExt),
+		Size:      stat.Size(),
+		CreatedAt: stat.ModTime(),
+	}, nil
+}
+
+// adoptServices 接管旧实例注入的外部依赖 (告警监控器、事件总线、历史持久化)
+func (s *System) adoptServices(old *System) {
+	old.mutex.RLock()
+	monitor := old.AlarmMonitor
+	old.mutex.RUnlock()
+
+	s.mutex.Lock()
+	s.AlarmMonitor = monitor
+	s.Events = old.Events // 已建立的实时推送连接继续接收新实例的事件
+	s.mutex.Unlock()
+
+	s.History.adoptStore(old.History)
+}
+
+// copyQueues 复制队列map (nil时返回空map)
+func copyQueues(queues map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(queues))
+	for key, value := range queues {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,132 @@
+package algorithm
+
+import (
+	"bytes"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"path/filepath"
+	"testing"
+)
+
+// lastAssignments 返回每个任务最后一次分配的通信设备 (用于比较调度结果)
+func lastAssignments(sys *System) string {
+	result := ""
+	for _, task := range sys.TaskManager.GetActiveTasks() {
+		if assign := sys.AssignmentManager.GetLastAssignment(task.ID); assign != nil {
+			result += fmt.Sprintf("%s:%d:%.0f ", task.ID, assign.CommID, assign.CumulativeProcessed)
+		}
+	}
+	return result
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	sys := newTestSystem(t)
+	seedTasks(t, sys, 3)
+	quota := define.TaskQuota{MaxActiveTasks: 7, MaxActiveData: 1e9}
+	if err := sys.SetTaskQuota(quota); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := sys.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snap); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreSystem(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.TimeSlot != sys.TimeSlot || restored.TaskManager.Count() != sys.TaskManager.Count() {
+		t.Fatalf("恢复后时隙/任务数不一致: %d/%d vs %d/%d",
+			restored.TimeSlot, restored.TaskManager.Count(), sys.TimeSlot, sys.TaskManager.Count())
+	}
+	if restored.TaskQuota() != quota {
+		t.Errorf("恢复后任务配额不一致: %+v vs %+v", restored.TaskQuota(), quota)
+	}
+	if fmt.Sprint(restored.LyapunovScheduler.lastCommQueues) != fmt.Sprint(sys.LyapunovScheduler.lastCommQueues) {
+		t.Errorf("恢复后调度器队列不一致: %v vs %v",
+			restored.LyapunovScheduler.lastCommQueues, sys.LyapunovScheduler.lastCommQueues)
+	}
+
+	// 从快照继续运行应与原系统得到相同的调度结果
+	for i := 0; i < 3; i++ {
+		sys.executeOneSlot()
+		restored.executeOneSlot()
+	}
+	if got, want := lastAssignments(restored), lastAssignments(sys); want == "" || got != want {
+		t.Errorf("恢复后继续调度结果不一致:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	sys := newTestSystem(t)
+	seedTasks(t, sys, 1)
+	dir := t.TempDir()
+
+	if _, err := SnapshotPath(dir, "../escape"); err == nil {
+		t.Error("包含路径穿越的快照名称应被拒绝")
+	}
+	path, err := SnapshotPath(dir, "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Fatalf("快照路径不在目录中: %s", path)
+	}
+
+	info, err := sys.SaveSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "checkpoint" || info.TimeSlot != 1 {
+		t.Errorf("快照信息不正确: %+v", info)
+	}
+	if infos, err := ListSnapshots(dir); err != nil || len(infos) != 1 {
+		t.Fatalf("快照列表不正确: %v %v", infos, err)
+	}
+
+	snap, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap.Version = define.SnapshotVersion + 1
+	if _, err := RestoreSystem(snap); err == nil {
+		t.Error("不支持的快照版本应被拒绝")
+	}
+}
+
+func TestReplaceSystemInstance(t *testing.T) {
+	prev := globalSystem
+	t.Cleanup(func() { globalSystem = prev })
+
+	old := newTestSystem(t)
+	globalSystem = old
+	if err := old.Start(); err != nil {
+		t.Fatal(err)
+	}
+	bus := old.Events
+	sub := bus.Subscribe(EventFilter{}, 1)
+	defer bus.Unsubscribe(sub)
+
+	sys := newTestSystem(t)
+	ReplaceSystemInstance(sys)
+
+	current := GetSystemInstance()
+	if current != sys {
+		t.Fatal("全局实例未替换")
+	}
+	if current.Events != bus {
+		t.Error("发布新实例前应接管旧实例的事件总线")
+	}
+	if state := old.GetLoopStatus().State; state != define.LoopStopped {
+		t.Errorf("旧实例的调度循环应已停止, state=%s", state)
+	}
+}
@@ -0,0 +1,428 @@
+package algorithm
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/algorithm/define"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 内存中保留的时隙状态数 (默认约30分钟，Slot=0.5s)
+	defaultHistoryCapacity = 3600
+	// 未指定桶大小时返回的最大数据点数 (超出自动降采样)
+	maxHistoryPoints = 2000
+	// 持久化队列长度与批量写入参数
+	persistQueueSize = 1024
+	persistBatchSize = 100
+	persistInterval  = time.Second
+)
+
+// StateStore 时隙状态持久化存储
+type StateStore interface {
+	SaveStates(states []*define.StateMetrics) error
+	QueryStates(query define.HistoryQuery) ([]*define.StateMetrics, error)
+}
+
+// 新建系统使用的历史容量 (启动时由配置文件覆盖)
+var defaultHistoryCap = defaultHistoryCapacity
+
+// SetDefaultHistoryCapacity 设置状态历史容量 (需在创建System之前调用)
+func SetDefaultHistoryCapacity(capacity int) {
+	if capacity > 0 {
+		defaultHistoryCap = capacity
+	}
+}
+
+// 标量字段 (按StateMetrics的json名称)
+var scalarFields = []string{
+	"total_queue", "transfer_delay", "compute_delay", "total_delay",
+	"transfer_energy", "compute_energy", "total_energy",
+	"load", "cost", "drift", "penalty",
+}
+
+// StateHistory 时隙状态指标的环形缓冲 (可选异步持久化)
+type StateHistory struct {
+	buf   []*define.StateMetrics
+	start int
+	size  int
+	mutex sync.RWMutex
+
+	store   StateStore
+	pending chan *define.StateMetrics
+	done    chan struct{} // 关闭时通知写入协程退出
+	flushed chan struct{} // 写入协程写完剩余记录后关闭
+	dropped uint64
+}
+
+// NewStateHistory 创建状态历史缓冲
+func NewStateHistory(capacity int) *StateHistory {
+	if capacity <= 0 {
+		capacity = defaultHistoryCapacity
+	}
+	return &StateHistory{
+		buf: make([]*define.StateMetrics, capacity),
+	}
+}
+
+// Add 记录一个时隙的状态 (超出容量时覆盖最早的记录)
+func (h *StateHistory) Add(state *define.StateMetrics) {
+	h.mutex.Lock()
+	idx := (h.start + h.size) % len(h.buf)
+	h.buf[idx] = state
+	if h.size < len(h.buf) {
+		h.size++
+	} else {
+		h.start = (h.start + 1) % len(h.buf)
+	}
+	pending := h.pending
+	h.mutex.Unlock()
+
+	// 异步持久化，队列满时丢弃，不阻塞调度
+	if pending != nil {
+		select {
+		case pending <- state:
+		default:
+			h.mutex.Lock()
+			h.dropped++
+			dropped := h.dropped
+			h.mutex.Unlock()
+			if dropped%100 == 1 {
+				log.Printf("⚠️  状态历史持久化队列已满，已丢弃 %d 条记录", dropped)
+			}
+		}
+	}
+}
+
+// Len 当前记录数
+func (h *StateHistory) Len() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.size
+}
+
+// Clear 清空内存中的记录
+func (h *StateHistory) Clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for i := range h.buf {
+		h.buf[i] = nil
+	}
+	h.start, h.size = 0, 0
+}
+
+// Range 按时隙/时间范围获取记录 (按时隙升序)
+func (h *StateHistory) Range(query define.HistoryQuery) []*define.StateMetrics {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	states := make([]*define.StateMetrics, 0, h.size)
+	for i := 0; i < h.size; i++ {
+		state := h.buf[(h.start+i)%len(h.buf)]
+		if matchesRange(state, query) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// SetStore 启用持久化存储，启动后台批量写入协程
+func (h *StateHistory) SetStore(store StateStore) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.store != nil || store == nil {
+		return
+	}
+	h.store = store
+	h.pending = make(chan *define.StateMetrics, persistQueueSize)
+	h.done = make(chan struct{})
+	h.flushed = make(chan struct{})
+	go h.persistLoop(store, h.pending, h.done, h.flushed)
+}
+
+// adoptStore 接管另一个历史缓冲的持久化存储及其写入协程
+func (h *StateHistory) adoptStore(other *StateHistory) {
+	other.mutex.Lock()
+	store, pending, done, flushed := other.store, other.pending, other.done, other.flushed
+	other.store, other.pending, other.done, other.flushed = nil, nil, nil, nil
+	other.mutex.Unlock()
+
+	if store == nil {
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.store, h.pending, h.done, h.flushed = store, pending, done, flushed
+}
+
+// Close 停用持久化，等待写入协程写完队列中剩余的记录后返回 (内存中的记录仍可查询)
+func (h *StateHistory) Close() {
+	h.mutex.Lock()
+	done, flushed := h.done, h.flushed
+	h.store, h.pending, h.done, h.flushed = nil, nil, nil, nil
+	h.mutex.Unlock()
+
+	if done != nil {
+		close(done)
+		<-flushed
+	}
+}
+
+// persistLoop 批量写入持久化存储 (攒够一批或每隔persistInterval写入一次)
+func (h *StateHistory) persistLoop(store StateStore, pending <-chan *define.StateMetrics, done <-chan struct{}, flushed chan<- struct{}) {
+	defer close(flushed)
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+
+	batch := make([]*define.StateMetrics, 0, persistBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := store.SaveStates(batch); err != nil {
+			log.Printf("❌ 状态历史持久化失败: %v", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case state := <-pending:
+			batch = append(batch, state)
+			if len(batch) >= persistBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-done:
+			for {
+				select {
+				case state := <-pending:
+					batch = append(batch, state)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Store 持久化存储 (未启用时为nil)
+func (h *StateHistory) Store() StateStore {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.store
+}
+
+// matchesRange 判断状态是否在查询范围内
+func matchesRange(state *define.StateMetrics, query define.HistoryQuery) bool {
+	if state == nil {
+		return false
+	}
+	if query.FromSlot != nil && state.TimeSlot < *query.FromSlot {
+		return false
+	}
+	if query.ToSlot != nil && state.TimeSlot > *query.ToSlot {
+		return false
+	}
+	if query.From != nil && state.Timestamp.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && state.Timestamp.After(*query.To) {
+		return false
+	}
+	return true
+}
+
+// SetStateStore 启用状态历史持久化（依赖注入）
+func (s *System) SetStateStore(store StateStore) {
+	s.History.SetStore(store)
+	log.Println("✓ 状态历史持久化已启用")
+}
+
+// QueryHistory 查询状态历史，支持降采样和字段选择
+func (s *System) QueryHistory(query define.HistoryQuery) (*define.HistoryResult, error) {
+	if query.Agg == "" {
+		query.Agg = define.AggAvg
+	}
+	if query.Agg != define.AggAvg && query.Agg != define.AggMax {
+		return nil, fmt.Errorf("不支持的聚合方式: %s (支持: avg, max)", query.Agg)
+	}
+	if query.Bucket < 0 {
+		return nil, errors.New("bucket不能为负数")
+	}
+	if len(query.Fields) == 0 {
+		query.Fields = scalarFields
+	}
+	for _, field := range query.Fields {
+		if !isValidHistoryField(field) {
+			return nil, fmt.Errorf("未知字段: %s", field)
+		}
+	}
+
+	var states []*define.StateMetrics
+	switch query.Source {
+	case "", define.HistorySourceMemory:
+		query.Source = define.HistorySourceMemory
+		states = s.History.Range(query)
+	case define.HistorySourceDB:
+		store := s.History.Store()
+		if store == nil {
+			return nil, errors.New("未启用状态历史持久化")
+		}
+		var err error
+		if states, err = store.QueryStates(query); err != nil {
+			return nil, fmt.Errorf("查询持久化记录失败: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的数据来源: %s (支持: memory, db)", query.Source)
+	}
+
+	bucket := query.Bucket
+	if bucket == 0 {
+		bucket = int(math.Ceil(float64(len(states)) / maxHistoryPoints))
+	}
+	if bucket < 1 {
+		bucket = 1
+	}
+
+	return &define.HistoryResult{
+		Source: query.Source,
+		Bucket: bucket,
+		Agg:    query.Agg,
+		Fields: query.Fields,
+		Points: downsample(states, bucket, query.Agg, query.Fields),
+	}, nil
+}
+
+// downsample 按时隙分桶聚合 (桶边界对齐到 bucket 的整数倍)
+func downsample(states []*define.StateMetrics, bucket int, agg string, fields []string) []define.HistoryPoint {
+	points := make([]define.HistoryPoint, 0)
+	if len(states) == 0 {
+		return points
+	}
+
+	// 按时隙排序 (与分桶依据一致；恢复快照后时间戳可能不随时隙单调递增)
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].TimeSlot != states[j].TimeSlot {
+			return states[i].TimeSlot < states[j].TimeSlot
+		}
+		return states[i].Timestamp.Before(states[j].Timestamp)
+	})
+
+	var current *define.HistoryPoint
+	var currentKey uint
+	counts := make(map[string]int)
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		if agg == define.AggAvg {
+			for field, n := range counts {
+				current.Values[field] /= float64(n)
+			}
+		}
+		points = append(points, *current)
+	}
+
+	for _, state := range states {
+		key := state.TimeSlot / uint(bucket)
+		if current == nil || key != currentKey {
+			finish()
+			current = &define.HistoryPoint{
+				TimeSlot:  state.TimeSlot,
+				Timestamp: state.Timestamp,
+				Values:    make(map[string]float64, len(fields)),
+			}
+			currentKey = key
+			counts = make(map[string]int)
+		}
+		current.Count++
+
+		for _, field := range fields {
+			value, ok := historyFieldValue(state, field)
+			if !ok {
+				continue
+			}
+			if counts[field] == 0 {
+				current.Values[field] = value
+			} else if agg == define.AggMax {
+				current.Values[field] = math.Max(current.Values[field], value)
+			} else {
+				current.Values[field] += value
+			}
+			counts[field]++
+		}
+	}
+	finish()
+
+	return points
+}
+
+// isValidHistoryField 校验字段名: 标量字段或 "comm_queues.<id>" 形式的设备字段
+func isValidHistoryField(field string) bool {
+	for _, name := range scalarFields {
+		if field == name {
+			return true
+		}
+	}
+	name, id, ok := strings.Cut(field, ".")
+	if !ok || id == "" {
+		return false
+	}
+	switch name {
+	case "comm_queues", "battery_levels", "comm_energy_queues", "user_energy_queues":
+		return true
+	}
+	return false
+}
+
+// historyFieldValue 读取状态中的字段值，设备字段不存在时返回false
+func historyFieldValue(state *define.StateMetrics, field string) (float64, bool) {
+	switch field {
+	case "total_queue":
+		return state.TotalQueue, true
+	case "transfer_delay":
+		return state.TransferDelay, true
+	case "compute_delay":
+		return state.ComputeDelay, true
+	case "total_delay":
+		return state.TotalDelay, true
+	case "transfer_energy":
+		return state.TransferEnergy, true
+	case "compute_energy":
+		return state.ComputeEnergy, true
+	case "total_energy":
+		return state.TotalEnergy, true
+	case "load":
+		return state.Load, true
+	case "cost":
+		return state.Cost, true
+	case "drift":
+		return state.Drift, true
+	case "penalty":
+		return state.Penalty, true
+	}
+
+	name, id, _ := strings.Cut(field, ".")
+	var values map[string]float64
+	switch name {
+	case "comm_queues":
+		values = state.CommQueues
+	case "battery_levels":
+		values = state.BatteryLevels
+	case "comm_energy_queues":
+		values = state.CommEnergyQueues
+	case "user_energy_queues":
+		values = state.UserEnergyQueues
+	}
+	value, ok := values[id]
+	return value, ok
+}
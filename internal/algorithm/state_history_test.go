@@ -0,0 +1,133 @@
+package algorithm
+
+import (
+	"go-backend/internal/algorithm/define"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStateHistoryQuery(t *testing.T) {
+	sys := newEmptySystem()
+	sys.History = NewStateHistory(8)
+
+	base := time.Unix(0, 0)
+	for slot := uint(1); slot <= 12; slot++ {
+		state := define.NewStateMetrics()
+		state.TimeSlot = slot
+		state.Timestamp = base.Add(time.Duration(slot) * time.Second)
+		state.TotalDelay = float64(slot)
+		state.CommQueues["1"] = float64(slot * 10)
+		sys.History.Add(state)
+	}
+
+	// 容量为8，只保留时隙5~12
+	all, err := sys.QueryHistory(define.HistoryQuery{Fields: []string{"total_delay"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Points) != 8 || all.Points[0].TimeSlot != 5 {
+		t.Fatalf("环形缓冲覆盖不正确: %+v", all.Points)
+	}
+
+	from, to := uint(6), uint(11)
+	result, err := sys.QueryHistory(define.HistoryQuery{
+		FromSlot: &from,
+		ToSlot:   &to,
+		Bucket:   4,
+		Agg:      define.AggMax,
+		Fields:   []string{"total_delay", "comm_queues.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 桶边界对齐到4的倍数: [6,7] [8..11]
+	if len(result.Points) != 2 {
+		t.Fatalf("降采样桶数量不正确: %+v", result.Points)
+	}
+	if p := result.Points[1]; p.Count != 4 || p.Values["total_delay"] != 11 || p.Values["comm_queues.1"] != 110 {
+		t.Errorf("max聚合结果不正确: %+v", p)
+	}
+
+	avg, _ := sys.QueryHistory(define.HistoryQuery{FromSlot: &from, ToSlot: &to, Bucket: 4, Fields: []string{"total_delay"}})
+	if v := avg.Points[0].Values["total_delay"]; v != 6.5 {
+		t.Errorf("avg聚合结果不正确: %g", v)
+	}
+
+	if _, err := sys.QueryHistory(define.HistoryQuery{Fields: []string{"unknown"}}); err == nil {
+		t.Error("未知字段应返回错误")
+	}
+}
+
+// TestStateHistoryDownsampleBySlot 测试时间戳不随时隙单调递增时 (如恢复快照后) 仍按时隙分桶
+func TestStateHistoryDownsampleBySlot(t *testing.T) {
+	base := time.Unix(1000, 0)
+	states := make([]*define.StateMetrics, 0, 4)
+	for slot := uint(1); slot <= 4; slot++ {
+		state := define.NewStateMetrics()
+		state.TimeSlot = slot
+		state.Timestamp = base.Add(-time.Duration(slot) * time.Second)
+		state.TotalDelay = float64(slot)
+		states = append(states, state)
+	}
+
+	points := downsample(states, 2, define.AggMax, []string{"total_delay"})
+	if len(points) != 3 || points[0].TimeSlot != 1 || points[1].TimeSlot != 2 || points[2].TimeSlot != 4 {
+		t.Fatalf("应按时隙分为 [1] [2,3] [4] 三个桶: %+v", points)
+	}
+	if v := points[1].Values["total_delay"]; v != 3 {
+		t.Errorf("max聚合结果不正确: %g", v)
+	}
+}
+
+type memoryStateStore struct {
+	mutex  sync.Mutex
+	states []*define.StateMetrics
+}
+
+func (s *memoryStateStore) SaveStates(states []*define.StateMetrics) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.states = append(s.states, states...)
+	return nil
+}
+
+func (s *memoryStateStore) QueryStates(define.HistoryQuery) ([]*define.StateMetrics, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.states, nil
+}
+
+// TestStateHistoryClose 测试关闭时写入剩余记录并结束写入协程
+func TestStateHistoryClose(t *testing.T) {
+	store := &memoryStateStore{}
+	history := NewStateHistory(8)
+	history.SetStore(store)
+	for slot := uint(1); slot <= 3; slot++ {
+		state := define.NewStateMetrics()
+		state.TimeSlot = slot
+		history.Add(state)
+	}
+
+	history.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if states, _ := store.QueryStates(define.HistoryQuery{}); len(states) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("关闭后应写入队列中剩余的记录")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if history.Store() != nil {
+		t.Error("关闭后应停用持久化")
+	}
+
+	// 关闭后继续记录不再写入，重复关闭无影响
+	history.Add(define.NewStateMetrics())
+	history.Close()
+	if history.Len() != 4 {
+		t.Errorf("内存中应保留4条记录, got %d", history.Len())
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go-backend/pkg/database"
 	"log"
 	"math"
+	"sort"
 	"sync"
 	"time"
 )
@@ -34,38 +35,46 @@ type System struct {
 	Scheduler          *Scheduler          // 简单调度器 (已弃用)
 	LyapunovScheduler  *LyapunovScheduler  // Lyapunov负载均衡调度器
 	AlarmMonitor       *AlarmMonitor       // 告警监控器
+	Events             *EventBus           // 时隙事件总线 (实时推送)
+	History            *StateHistory       // 时隙状态历史 (环形缓冲 + 可选持久化)
 	UseLyapunov        bool                // 是否使用Lyapunov调度器 (默认true)
 
 	// 运行状态
 	TimeSlot      uint
-	IsRunning     bool
+	IsRunning     bool // 调度循环是否正在执行时隙 (loopState == running)
 	IsInitialized bool
 	CurrentState  *define.StateMetrics // 当前系统状态指标
-	StopChan      chan bool
+	quota         define.TaskQuota     // 每个账号的任务配额 (见 quota.go)
+	instanceID    string               // 所属仿真实例ID (监控指标的instance标签)
 	mutex         sync.RWMutex
+
+	// 调度循环生命周期 (见 loop.go)
+	loopState    define.LoopState
+	speedUp      float64       // 仿真加速比
+	tickInterval time.Duration // 时隙间隔 (真实时间) = Slot / speedUp
+	loopStop     chan struct{}      // 本次运行的停止信号 (每次启动重新创建)
+	loopDone     chan struct{}      // 本次运行的循环协程已退出
+	tickReset    chan time.Duration // 运行中修改时隙间隔
+	slotMutex    sync.Mutex         // 保证同一时刻只执行一个时隙 (循环与单步互斥)
 }
 
-// NewSystem 创建新系统实例 (替代单例模式)
+// NewSystem 创建新系统实例 (替代单例模式)，拓扑从数据库加载
 func NewSystem() *System {
-	sys := &System{
-		Users:         make([]*define.UserDevice, 0),
-		Comms:         make([]*define.CommDevice, 0),
-		UserMap:       make(map[uint]*define.UserDevice),
-		CommMap:       make(map[uint]*define.CommDevice),
-		LinkMap:       make(map[[2]uint]*models.Link),
-		NodeIDToIndex: make(map[uint]int),
-		IndexToNodeID: make(map[int]uint),
-		CurrentState:  define.NewStateMetrics(),
-		StopChan:      make(chan bool, 1),
-	}
-
-	// 加载设备数据
-	if err := sys.loadNodesFromDB(); err != nil {
+	nodes, links, err := loadTopologyFromDB()
+	if err != nil {
 		log.Printf("⚠️  系统初始化失败: %v", err)
 		// 不返回nil,而是返回部分初始化的系统(允许降级运行)
-		sys.IsInitialized = false
-		return sys
+		return newEmptySystem()
 	}
+	return NewSystemWithTopology(nodes, links)
+}
+
+// NewSystemWithTopology 使用给定的节点和链路创建系统实例 (不访问数据库)
+func NewSystemWithTopology(nodes []models.Node, links []models.Link) *System {
+	sys := newEmptySystem()
+
+	// 加载设备数据
+	sys.loadTopology(nodes, links)
 
 	// 构建Floyd最短路径
 	if err := sys.buildFloydPaths(); err != nil {
@@ -86,6 +95,27 @@ func NewSystem() *System {
 	return sys
 }
 
+// newEmptySystem 创建未初始化的空系统
+func newEmptySystem() *System {
+	return &System{
+		Users:         make([]*define.UserDevice, 0),
+		Comms:         make([]*define.CommDevice, 0),
+		UserMap:       make(map[uint]*define.UserDevice),
+		CommMap:       make(map[uint]*define.CommDevice),
+		LinkMap:       make(map[[2]uint]*models.Link),
+		NodeIDToIndex: make(map[uint]int),
+		IndexToNodeID: make(map[int]uint),
+		CurrentState:  define.NewStateMetrics(),
+		Events:        NewEventBus(),
+		History:       NewStateHistory(defaultHistoryCap),
+		loopState:     define.LoopStopped,
+		speedUp:       defaultSpeedUp,
+		tickInterval:  tickIntervalFor(defaultSpeedUp),
+		quota:         defaultTaskQuota,
+		instanceID:    define.DefaultInstanceID,
+	}
+}
+
 // SetAlarmMonitor 设置告警监控器（依赖注入）
 func (s *System) SetAlarmMonitor(monitor *AlarmMonitor) {
 	s.mutex.Lock()
@@ -94,8 +124,8 @@ func (s *System) SetAlarmMonitor(monitor *AlarmMonitor) {
 	log.Println("✓ 告警监控器已启用")
 }
 
-// loadNodesFromDB 从数据库加载设备数据
-func (s *System) loadNodesFromDB() error {
+// loadTopologyFromDB 从数据库加载节点和链路
+func loadTopologyFromDB() ([]models.Node, []models.Link, error) {
 	db := database.GetDB()
 	nodeRepo := repository.NewNodeRepository(db)
 	linkRepo := repository.NewLinkRepository(db)
@@ -104,48 +134,101 @@ func (s *System) loadNodesFromDB() error {
 	nodes, err := nodeRepo.List(nil)
 	if err != nil {
 		log.Printf("❌ 加载节点失败: %v", err)
-		return fmt.Errorf("加载节点失败: %w", err)
+		return nil, nil, fmt.Errorf("加载节点失败: %w", err)
 	}
 
+	// 加载链路
+	links, err := linkRepo.List(nil)
+	if err != nil {
+		log.Printf("❌ 加载链路失败: %v", err)
+		return nil, nil, fmt.Errorf("加载链路失败: %w", err)
+	}
+
+	return nodes, links, nil
+}
+
+// loadTopology 根据节点和链路构建设备信息
+func (s *System) loadTopology(nodes []models.Node, links []models.Link) {
 	for _, node := range nodes {
 		if node.NodeType == models.NodeTypeUser {
-			user := &define.UserDevice{
-				Node:  node,
-				Speed: 0, // 稍后从Link中填充
-			}
+			user := define.NewUserDevice(node) // 接入设备和速度稍后从Link中填充
 			s.Users = append(s.Users, user)
 			s.UserMap[node.ID] = user
 		} else if node.NodeType == models.NodeTypeComm {
-			comm := &define.CommDevice{
-				Node: node,
-			}
+			comm := define.NewCommDevice(node)
 			s.Comms = append(s.Comms, comm)
 			s.CommMap[node.ID] = comm
 		}
 	}
 
-	// 加载链路
-	links, err := linkRepo.List(nil)
-	if err != nil {
-		log.Printf("❌ 加载链路失败: %v", err)
-		return fmt.Errorf("加载链路失败: %w", err)
-	}
-
+	// 收集每个用户设备有链路相连的通信设备
+	linkedComms := make(map[uint][]*define.CommDevice)
 	for _, link := range links {
 		s.LinkMap[[2]uint{link.SourceID, link.TargetID}] = &link
 
-		// 填充用户设备的上行速度
-		if user, exists := s.UserMap[link.TargetID]; exists {
-			if comm, isComm := s.CommMap[link.SourceID]; isComm {
-				// 计算用户到基站的上行速率 (bits/s)
-				dist := utils.Distance(user.X, user.Y, comm.X, comm.Y)
-				user.Speed = utils.TransferSpeed(constant.P_u, dist)
+		if comm, isComm := s.CommMap[link.SourceID]; isComm {
+			if _, isUser := s.UserMap[link.TargetID]; isUser {
+				linkedComms[link.TargetID] = append(linkedComms[link.TargetID], comm)
+			}
+		}
+		if comm, isComm := s.CommMap[link.TargetID]; isComm {
+			if _, isUser := s.UserMap[link.SourceID]; isUser {
+				linkedComms[link.SourceID] = append(linkedComms[link.SourceID], comm)
 			}
 		}
 	}
 
+	// 填充用户设备的接入设备和上行速度 (仅考虑覆盖范围内的通信设备)
+	for _, user := range s.Users {
+		user.CalcNearest(linkedComms[user.ID])
+		if !user.Connected {
+			log.Printf("⚠️  用户设备 %d (%s) 不在任何通信设备覆盖范围内", user.ID, user.Name)
+		}
+	}
+
 	log.Printf("✓ 成功加载节点数据: %d个用户设备, %d个通信设备", len(s.Users), len(s.Comms))
-	return nil
+}
+
+// inCoverage 判断用户设备与通信设备之间的链路是否在覆盖范围内
+// 非用户↔通信设备的链路不受覆盖半径限制
+func (s *System) inCoverage(srcID, dstID uint) bool {
+	if user, ok := s.UserMap[srcID]; ok {
+		if comm, ok := s.CommMap[dstID]; ok {
+			return comm.Covers(user.X, user.Y)
+		}
+	}
+	if comm, ok := s.CommMap[srcID]; ok {
+		if user, ok := s.UserMap[dstID]; ok {
+			return comm.Covers(user.X, user.Y)
+		}
+	}
+	return true
+}
+
+// uplinkSpeed 计算用户设备到接入通信设备的上行速率 (bits/s)
+func (s *System) uplinkSpeed(userID, commID uint) float64 {
+	user, userOk := s.UserMap[userID]
+	comm, commOk := s.CommMap[commID]
+	if !userOk || !commOk {
+		if userOk {
+			return user.Speed
+		}
+		return 0
+	}
+	dist := utils.Distance(user.X, user.Y, comm.X, comm.Y)
+	return utils.TransferSpeed(constant.P_u, dist)
+}
+
+// isUserConnected 用户设备是否在覆盖范围内
+func (s *System) isUserConnected(userID uint) bool {
+	user, ok := s.UserMap[userID]
+	return ok && user.Connected
+}
+
+// isNodeRoutable 节点是否可作为路由端点 (调用方需持有锁或处于初始化阶段)
+func (s *System) isNodeRoutable(nodeID uint) bool {
+	comm, ok := s.CommMap[nodeID]
+	return !ok || comm.IsAvailable()
 }
 
 // buildFloydPaths 构建Floyd最短路径矩阵
@@ -163,6 +246,9 @@ func (s *System) buildFloydPaths() error {
 		return fmt.Errorf("没有可用节点")
 	}
 
+	// 固定节点顺序，保证等长路径的选择结果可复现
+	sort.Slice(allNodeIDs, func(i, j int) bool { return allNodeIDs[i] < allNodeIDs[j] })
+
 	// 构建ID映射 (NodeID <-> Matrix Index)
 	for idx, nodeID := range allNodeIDs {
 		s.NodeIDToIndex[nodeID] = idx
@@ -193,6 +279,16 @@ func (s *System) buildFloydPaths() error {
 			continue
 		}
 
+		// 超出覆盖半径的用户↔通信设备链路不可用
+		if !s.inCoverage(srcID, dstID) {
+			continue
+		}
+
+		// 电量耗尽的无人机不参与路由
+		if !s.isNodeRoutable(srcID) || !s.isNodeRoutable(dstID) {
+			continue
+		}
+
 		// 使用传输延迟作为边权重
 		delay := 1.0 // 默认延迟
 		if bandwidth, ok := link.Properties["bandwidth"].(float64); ok && bandwidth > 0 {
@@ -230,46 +326,27 @@ func (s *System) buildFloydPaths() error {
 
 // SubmitTask 提交任务
 func (s *System) SubmitTask(userID uint, dataSize float64, taskType string) (*define.Task, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// 检查系统是否已初始化
-	if !s.IsInitialized {
-		log.Printf("❌ 系统未初始化,无法提交任务")
-		return nil, fmt.Errorf("系统未初始化")
-	}
-
-	// 验证用户
-	if _, exists := s.UserMap[userID]; !exists {
-		log.Printf("❌ 提交任务失败: 用户 %d 不存在", userID)
-		return nil, fmt.Errorf("用户不存在: %d", userID)
-	}
-
-	// 验证数据大小
-	if dataSize <= 0 {
-		log.Printf("❌ 提交任务失败: 无效的数据大小 %.2f", dataSize)
-		return nil, fmt.Errorf("无效的数据大小: %.2f", dataSize)
-	}
-
-	// 创建任务
-	task := define.NewTask(userID, dataSize, taskType)
-	s.TaskManager.AddTask(task)
+	return s.SubmitTaskAs(define.TaskOwner{}, userID, dataSize, taskType, define.PriorityNormal)
+}
 
-	log.Printf("✓ 任务 %s 已提交 (用户:%d, 数据:%.2fMB, 类型:%s)",
-		task.ID, userID, dataSize, taskType)
+// SubmitTaskWithPriority 提交带优先级的任务
+func (s *System) SubmitTaskWithPriority(userID uint, dataSize float64, taskType string, priority int) (*define.Task, error) {
+	return s.SubmitTaskAs(define.TaskOwner{}, userID, dataSize, taskType, priority)
+}
 
-	// 启动调度循环
-	if !s.IsRunning {
-		s.IsRunning = true
-		go s.runSchedulingLoop()
-		log.Println("✓ 调度循环已启动")
+// SubmitTaskAs 以指定账号身份提交任务 (owner.ID为0表示不记录提交账号)，受账号任务配额限制
+func (s *System) SubmitTaskAs(owner define.TaskOwner, userID uint, dataSize float64, taskType string, priority int) (*define.Task, error) {
+	tasks, err := s.SubmitTasksAs(owner, []define.TaskBase{{UserID: userID, DataSize: dataSize, Type: taskType, Priority: priority}})
+	if err != nil {
+		return nil, err
 	}
-
-	return task, nil
+	return tasks[0], nil
 }
 
-// SubmitTaskWithPriority 提交带优先级的任务
-func (s *System) SubmitTaskWithPriority(userID uint, dataSize float64, taskType string, priority int) (*define.Task, error) {
+// SubmitTasksAs 以指定账号身份批量提交任务
+// 整批任务在同一次加锁中校验、检查配额并加入任务队列: 任一任务无效或整批超出配额时不提交任何任务，
+// 并发提交的其他批次只能在本批次加入后检查配额
+func (s *System) SubmitTasksAs(owner define.TaskOwner, requests []define.TaskBase) ([]*define.Task, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -279,49 +356,51 @@ func (s *System) SubmitTaskWithPriority(userID uint, dataSize float64, taskType
 		return nil, fmt.Errorf("系统未初始化")
 	}
 
-	// 验证用户
-	if _, exists := s.UserMap[userID]; !exists {
-		log.Printf("❌ 提交任务失败: 用户 %d 不存在", userID)
-		return nil, fmt.Errorf("用户不存在: %d", userID)
-	}
+	// 先校验整批任务，之后不再有失败的可能
+	totalData := 0.0
+	for _, req := range requests {
+		// 验证用户
+		if _, exists := s.UserMap[req.UserID]; !exists {
+			log.Printf("❌ 提交任务失败: 用户 %d 不存在", req.UserID)
+			return nil, fmt.Errorf("用户不存在: %d", req.UserID)
+		}
 
-	// 验证数据大小
-	if dataSize <= 0 {
-		log.Printf("❌ 提交任务失败: 无效的数据大小 %.2f", dataSize)
-		return nil, fmt.Errorf("无效的数据大小: %.2f", dataSize)
+		// 验证数据大小
+		if req.DataSize <= 0 {
+			log.Printf("❌ 提交任务失败: 无效的数据大小 %.2f", req.DataSize)
+			return nil, fmt.Errorf("无效的数据大小: %.2f", req.DataSize)
+		}
+		totalData += req.DataSize
 	}
 
-	// 创建带优先级的任务
-	task := define.NewTaskWithPriority(userID, dataSize, taskType, priority)
-	s.TaskManager.AddTask(task)
-
-	log.Printf("✓ 任务 %s 已提交 (用户:%d, 数据:%.2fMB, 类型:%s, 优先级:%d)",
-		task.ID, userID, dataSize, taskType, priority)
-
-	// 启动调度循环
-	if !s.IsRunning {
-		s.IsRunning = true
-		go s.runSchedulingLoop()
-		log.Println("✓ 调度循环已启动")
+	// 检查账号配额
+	if err := s.checkQuotaLocked(owner, len(requests), totalData); err != nil {
+		log.Printf("❌ 提交任务失败: 账号 %d %v", owner.ID, err)
+		return nil, err
 	}
 
-	return task, nil
-}
+	tasks := make([]*define.Task, 0, len(requests))
+	for _, req := range requests {
+		if !s.isUserConnected(req.UserID) {
+			log.Printf("⚠️  用户 %d 不在覆盖范围内，任务将保持等待", req.UserID)
+		}
+		// 创建任务
+		task := define.NewTaskWithPriority(req.UserID, req.DataSize, req.Type, req.Priority)
+		task.OwnerID = owner.ID
+		task.CreatedSlot = s.TimeSlot
+		s.TaskManager.AddTask(task)
+		tasks = append(tasks, task)
 
-// runSchedulingLoop 调度循环 (简化的单一职责流程)
-func (s *System) runSchedulingLoop() {
-	ticker := time.NewTicker(1 * time.Second) // constant.Slot是float64,这里用1秒
-	defer ticker.Stop()
+		log.Printf("✓ 任务 %s 已提交 (用户:%d, 数据:%.2fMB, 类型:%s, 优先级:%d, 账号:%d)",
+			task.ID, req.UserID, req.DataSize, req.Type, req.Priority, owner.ID)
+	}
 
-	for {
-		select {
-		case <-s.StopChan:
-			log.Println("调度循环停止")
-			return
-		case <-ticker.C:
-			s.executeOneSlot()
-		}
+	// 启动调度循环 (已暂停时保持暂停)
+	if len(tasks) > 0 && s.loopState == define.LoopStopped {
+		s.startLoopLocked()
 	}
+
+	return tasks, nil
 }
 
 // executeOneSlot 执行一个时隙的调度
@@ -329,27 +408,33 @@ func (s *System) executeOneSlot() {
 	// 细化锁粒度: 只在必要时持有锁
 
 	// 1. 原子递增时隙
+	start := time.Now()
 	s.mutex.Lock()
 	s.TimeSlot++
 	currentSlot := s.TimeSlot
+	instanceID := s.instanceID
 	s.mutex.Unlock()
 
+	// 记录时隙执行耗时
+	defer func() {
+		slotDuration.WithLabelValues(instanceID).Observe(time.Since(start).Seconds())
+		slotsTotal.WithLabelValues(instanceID).Inc()
+	}()
+
+	// 记录时隙开始时的任务状态 (用于推送状态变化)
+	statusBefore := s.snapshotTaskStatus()
+
 	// 2. 检查超时任务
-	s.checkTimeouts()
+	s.checkTimeouts(currentSlot)
 
 	// 3. 获取活跃任务（TaskManager有自己的锁）
 	tasks := s.TaskManager.GetActiveTasks()
 	if len(tasks) == 0 {
 		s.mutex.Lock()
-		if s.IsRunning {
+		if s.loopState == define.LoopRunning {
 			log.Println("所有任务已完成，停止调度")
-			s.IsRunning = false
-			// 发送停止信号，终止ticker循环
-			select {
-			case s.StopChan <- true:
-			default:
-				// StopChan已满，忽略
-			}
+			// 在循环协程内部调用，只发出停止信号，不等待退出
+			s.stopLoopLocked()
 		}
 		s.mutex.Unlock()
 		return
@@ -361,11 +446,15 @@ func (s *System) executeOneSlot() {
 	useLyapunov := s.UseLyapunov
 	s.mutex.RUnlock()
 
+	// 不在覆盖范围内的用户，其任务保持Pending并记录原因
+	schedulable := s.holdDisconnectedTasks(tasks)
+
 	if useLyapunov {
-		assignments = s.LyapunovScheduler.Schedule(currentSlot, tasks)
+		assignments = s.LyapunovScheduler.Schedule(currentSlot, schedulable)
 	} else {
-		assignments = s.Scheduler.Schedule(currentSlot, tasks)
+		assignments = s.Scheduler.Schedule(currentSlot, schedulable)
 	}
+	s.markUnassignedTasks(schedulable, assignments)
 
 	// 4. 执行分配,计算传输和处理量（不需要System锁）
 	taskMap := make(map[string]*define.Task)
@@ -387,10 +476,17 @@ func (s *System) executeOneSlot() {
 		s.AssignmentManager.AddAssignment(assign)
 	}
 
-	// 7. 更新系统状态指标（供前端Dashboard使用）
+	// 7. 扣减无人机电量（悬停 + 计算 + 转发），并更新能耗虚拟队列
+	commEnergy := s.computeCommEnergy(assignments)
+	s.drainBatteries(commEnergy)
+	if useLyapunov {
+		s.LyapunovScheduler.UpdateEnergyQueues(commEnergy, s.computeUserEnergy(assignments, taskMap))
+	}
+
+	// 8. 更新系统状态指标（供前端Dashboard使用）
 	s.updateStateMetrics(assignments, tasks)
 
-	// 8. 检查系统状态并产生告警（如果告警监控器已启用）
+	// 9. 检查系统状态并产生告警（如果告警监控器已启用）
 	s.mutex.RLock()
 	alarmMonitor := s.AlarmMonitor
 	currentState := s.CurrentState
@@ -408,9 +504,95 @@ func (s *System) executeOneSlot() {
 		}
 	}
 
+	// 10. 推送时隙事件 (非阻塞，慢客户端不影响调度)
+	s.publishSlotEvent(currentSlot, currentState, statusBefore, assignments)
+
 	log.Printf("时隙 %d: 调度了 %d 个任务", currentSlot, len(assignments))
 }
 
+// snapshotTaskStatus 记录所有活跃任务的当前状态
+func (s *System) snapshotTaskStatus() map[string]define.TaskStatus {
+	tasks := s.TaskManager.GetActiveTasks()
+	statuses := make(map[string]define.TaskStatus, len(tasks))
+	for _, task := range tasks {
+		statuses[task.ID] = task.Status
+	}
+	return statuses
+}
+
+// publishSlotEvent 汇总本时隙的状态变化和分配，发布到事件总线
+func (s *System) publishSlotEvent(slot uint, state *define.StateMetrics, statusBefore map[string]define.TaskStatus, assignments []*define.Assignment) {
+	if s.Events == nil || s.Events.SubscriberCount() == 0 {
+		return
+	}
+
+	event := &define.SlotEvent{
+		TimeSlot:    slot,
+		Timestamp:   time.Now(),
+		State:       state,
+		Transitions: make([]define.TaskTransition, 0),
+		Assignments: make([]*define.Assignment, 0, len(assignments)),
+		TaskUsers:   make(map[string]uint, len(statusBefore)),
+		TaskOwners:  make(map[string]uint, len(statusBefore)),
+	}
+
+	taskIDs := make([]string, 0, len(statusBefore))
+	for taskID := range statusBefore {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+	event.Assignments = append(event.Assignments, assignments...)
+
+	for _, taskID := range taskIDs {
+		task := s.TaskManager.GetTask(taskID)
+		if task == nil {
+			continue
+		}
+		event.TaskUsers[taskID] = task.UserID
+		event.TaskOwners[taskID] = task.OwnerID
+		if from := statusBefore[taskID]; task.Status != from {
+			event.Transitions = append(event.Transitions, define.TaskTransition{
+				TaskID: taskID,
+				UserID: task.UserID,
+				From:   from,
+				To:     task.Status,
+			})
+		}
+	}
+
+	s.Events.Publish(event)
+}
+
+// holdDisconnectedTasks 过滤出可调度的任务
+// 用户设备不在任何覆盖范围内时，Pending任务保持等待并记录原因
+func (s *System) holdDisconnectedTasks(tasks []*define.Task) []*define.Task {
+	schedulable := make([]*define.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status == define.TaskPending && !s.isUserConnected(task.UserID) {
+			s.TaskManager.SetPendingReason(task.ID, define.ReasonOutOfCoverage)
+			continue
+		}
+		schedulable = append(schedulable, task)
+	}
+	return schedulable
+}
+
+// markUnassignedTasks 为本时隙未能分配的Pending任务记录原因，已分配的清除原因
+func (s *System) markUnassignedTasks(tasks []*define.Task, assignments []*define.Assignment) {
+	assigned := make(map[string]bool, len(assignments))
+	for _, assign := range assignments {
+		assigned[assign.TaskID] = true
+	}
+
+	for _, task := range tasks {
+		if assigned[task.ID] {
+			s.TaskManager.SetPendingReason(task.ID, "")
+		} else if task.Status == define.TaskPending {
+			s.TaskManager.SetPendingReason(task.ID, define.ReasonNoReachableComm)
+		}
+	}
+}
+
 // updateTaskStates 根据分配结果更新任务状态
 func (s *System) updateTaskStates(assignments []*define.Assignment) {
 	// 批量更新任务状态（使用TaskManager的锁保护）
@@ -456,7 +638,7 @@ func (s *System) updateTaskStates(assignments []*define.Assignment) {
 
 		// 执行状态转换（使用TaskManager的写锁保护）
 		if shouldUpdate {
-			if err := s.TaskManager.UpdateTaskStatus(assign.TaskID, targetStatus); err != nil {
+			if err := s.TaskManager.UpdateTaskStatus(assign.TaskID, targetStatus, assign.TimeSlot); err != nil {
 				log.Printf("状态转换失败 (%s→%d): %v", assign.TaskID, targetStatus, err)
 			}
 		}
@@ -477,25 +659,13 @@ func (s *System) CancelTask(taskID string) error {
 }
 
 // checkTimeouts 在每个时隙检查超时任务
-func (s *System) checkTimeouts() {
-	timedOutTasks := s.TaskManager.CheckTimeouts()
+func (s *System) checkTimeouts(currentSlot uint) {
+	timedOutTasks := s.TaskManager.CheckTimeouts(currentSlot)
 	if len(timedOutTasks) > 0 {
 		log.Printf("⚠️  检测到 %d 个超时任务: %v", len(timedOutTasks), timedOutTasks)
 	}
 }
 
-// Stop 停止调度
-func (s *System) Stop() {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if s.IsRunning {
-		s.StopChan <- true
-		s.IsRunning = false
-		log.Println("✓ 调度循环已停止")
-	}
-}
-
 // SetSchedulerType 设置调度器类型
 // schedulerType: "lyapunov" 或 "simple"
 func (s *System) SetSchedulerType(schedulerType string) error {
@@ -529,24 +699,43 @@ func (s *System) GetSchedulerType() string {
 
 // GetSystemInfo 获取系统信息
 func (s *System) GetSystemInfo() *define.SystemInfo {
+	return s.GetScopedSystemInfo(nil)
+}
+
+// GetScopedSystemInfo 获取访问范围内的系统信息
+// scope 不为nil时任务数、传输路径和未覆盖的用户设备只统计范围内的，并隐藏全局状态指标 (包含所有用户的队列)
+func (s *System) GetScopedSystemInfo(scope *define.TaskScope) *define.SystemInfo {
 	s.mutex.RLock()
 	userCount := len(s.Users)
 	commCount := len(s.Comms)
 	isRunning := s.IsRunning
 	isInitialized := s.IsInitialized
 	timeSlot := s.TimeSlot
+	loopState := s.loopState
+	tickInterval := s.tickInterval
+	speedUp := s.speedUp
 	s.mutex.RUnlock()
 
-	// 收集传输路径 - 使用TaskManager的锁保护
-	transferPaths := make(map[string][]uint)
+	// 统计范围内的任务 - 使用TaskManager的锁保护
 	s.TaskManager.mutex.RLock()
-	taskIDs := make([]string, 0, len(s.TaskManager.Tasks))
-	for taskID := range s.TaskManager.Tasks {
-		taskIDs = append(taskIDs, taskID)
+	taskIDs := make([]string, 0, len(s.TaskManager.TaskList))
+	activeTaskCount, completedTaskCount := 0, 0
+	for _, task := range s.TaskManager.TaskList {
+		if !scope.AllowsTask(task) {
+			continue
+		}
+		taskIDs = append(taskIDs, task.ID)
+		if task.StateMachine().IsActive() {
+			activeTaskCount++
+		}
+		if task.StateMachine().IsCompleted() {
+			completedTaskCount++
+		}
 	}
 	s.TaskManager.mutex.RUnlock()
 
 	// 获取每个任务的最后分配（AssignmentManager有自己的锁）
+	transferPaths := make(map[string][]uint)
 	for _, taskID := range taskIDs {
 		lastAssign := s.AssignmentManager.GetLastAssignment(taskID)
 		if lastAssign != nil && len(lastAssign.Path) > 0 {
@@ -554,17 +743,19 @@ func (s *System) GetSystemInfo() *define.SystemInfo {
 		}
 	}
 
-	// 获取活跃任务数量
-	activeTaskCount := len(s.TaskManager.GetActiveTasks())
-
 	// 复制当前状态（避免并发问题）
 	// 如果没有活跃任务，返回空状态
 	s.mutex.RLock()
 	var currentState interface{}
-	if activeTaskCount > 0 && s.CurrentState != nil {
+	if scope == nil && activeTaskCount > 0 && s.CurrentState != nil {
 		currentState = s.CurrentState
-	} else {
-		currentState = nil
+	}
+
+	disconnectedUsers := make([]uint, 0)
+	for _, user := range s.Users {
+		if !user.Connected && (scope == nil || scope.UserIDs[user.ID]) {
+			disconnectedUsers = append(disconnectedUsers, user.ID)
+		}
 	}
 	s.mutex.RUnlock()
 
@@ -575,10 +766,15 @@ func (s *System) GetSystemInfo() *define.SystemInfo {
 		IsInitialized:  isInitialized,
 		TimeSlot:       timeSlot,
 		TransferPath:   transferPaths,
-		TaskCount:      s.TaskManager.Count(),
+		TaskCount:      len(taskIDs),
 		ActiveTasks:    activeTaskCount,
-		CompletedTasks: s.TaskManager.CountCompleted(),
+		CompletedTasks: completedTaskCount,
 		State:          currentState,
+
+		DisconnectedUsers: disconnectedUsers,
+		LoopState:         loopState,
+		TickIntervalMs:    tickInterval.Milliseconds(),
+		SpeedUp:           speedUp,
 	}
 }
 
@@ -650,15 +846,32 @@ func (s *System) updateStateMetrics(assignments []*define.Assignment, tasks []*d
 		state.Load = float64(len(tasks)) / float64(len(s.Comms))
 	}
 
-	// 4. 计算Cost (简化版: 加权和)
+	// 4. 计算Cost (简化版: 加权和，使用Lyapunov调度器时替换为其所选方案的实际值)
 	state.Cost = state.TotalDelay*1.0 + state.TotalEnergy*0.1 + state.TotalQueue*0.05
 
 	// 5. 计算Drift和Penalty (简化版: 基于队列和延迟)
 	state.Drift = state.TotalQueue * 0.5
 	state.Penalty = state.TotalDelay + state.TotalEnergy*0.1
 
-	// 6. 原子更新CurrentState
+	// 6. 原子更新CurrentState（同时记录无人机剩余电量和本时隙生效的参数）
 	s.mutex.Lock()
+	state.TimeSlot = s.TimeSlot
+	state.Timestamp = time.Now()
+	if s.UseLyapunov && s.LyapunovScheduler != nil {
+		params := s.LyapunovScheduler.current
+		state.Params = &params
+		// 与/metrics导出的值一致，告警规则按Drift/Penalty/Cost评估时使用调度器的实际目标值
+		state.Drift, state.Penalty, state.Cost = s.LyapunovScheduler.LastCostTerms()
+		state.CommEnergyQueues, state.UserEnergyQueues = s.LyapunovScheduler.GetEnergyQueues()
+	}
+	for commID, comm := range s.CommMap {
+		if comm.IsUAV {
+			state.BatteryLevels[fmt.Sprintf("%d", commID)] = comm.BatteryRatio()
+		}
+	}
 	s.CurrentState = state
 	s.mutex.Unlock()
+
+	// 7. 记录状态历史（供趋势图查询）
+	s.History.Add(state)
 }
@@ -1,6 +1,7 @@
 package algorithm
 
 import (
+	"errors"
 	"go-backend/internal/algorithm/define"
 	"sync"
 	"testing"
@@ -74,8 +75,9 @@ func TestConcurrentReadWrite(t *testing.T) {
 	}
 
 	// 启动调度循环(会修改任务状态)
-	sys.IsRunning = true
-	go sys.runSchedulingLoop()
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
 
 	wg.Wait()
 }
@@ -157,3 +159,350 @@ func TestAssignmentManagerConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+// TestLoopLifecycle 测试调度循环的启动/暂停/单步/恢复/停止及重启
+func TestLoopLifecycle(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+
+	for _, user := range sys.Users {
+		if _, err := sys.SubmitTask(user.ID, 1e9, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sys.SetTickInterval(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	waitForSlot := func(slot uint) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for sys.GetLoopStatus().TimeSlot < slot {
+			if time.Now().After(deadline) {
+				t.Fatalf("等待时隙 %d 超时", slot)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitForSlot(3)
+
+	if _, err := sys.Step(1); err == nil {
+		t.Error("运行中单步应返回错误")
+	}
+
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	paused := sys.GetLoopStatus().TimeSlot
+	time.Sleep(50 * time.Millisecond)
+	if slot := sys.GetLoopStatus().TimeSlot; slot != paused {
+		t.Fatalf("暂停期间时隙不应前进: %d → %d", paused, slot)
+	}
+
+	if _, err := sys.Step(3); err != nil {
+		t.Fatal(err)
+	}
+	if slot := sys.GetLoopStatus().TimeSlot; slot != paused+3 {
+		t.Errorf("单步后时隙应为 %d, got %d", paused+3, slot)
+	}
+
+	if err := sys.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	waitForSlot(paused + 5)
+
+	sys.Stop()
+	if info := sys.GetSystemInfo(); info.LoopState != define.LoopStopped || info.IsRunning {
+		t.Errorf("停止后状态不正确: %s", info.LoopState)
+	}
+
+	// 停止后可以再次启动
+	stopped := sys.GetLoopStatus().TimeSlot
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitForSlot(stopped + 2)
+}
+
+// TestSimulatedTaskTiming 测试任务计时按时隙换算的仿真时间，与加速比无关
+func TestSimulatedTaskTiming(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+
+	if err := sys.SetSpeedUp(10); err != nil {
+		t.Fatal(err)
+	}
+	if status := sys.GetLoopStatus(); status.TickIntervalMs != 50 {
+		t.Errorf("加速比10时时隙间隔应为50ms, got %dms", status.TickIntervalMs)
+	}
+	if err := sys.SetSpeedUp(1000); err == nil {
+		t.Error("超出范围的加速比应被拒绝")
+	}
+
+	// 暂停状态下提交任务，由单步驱动时隙
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	task, err := sys.SubmitTask(sys.Users[0].ID, 1e12, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Timeout = 3 * define.SlotDuration
+
+	if _, err := sys.Step(3); err != nil {
+		t.Fatal(err)
+	}
+	if task.ScheduledSlot != 1 || task.GetWaitTime(sys.TimeSlot) != define.SlotDuration {
+		t.Errorf("等待时间应为1个时隙: scheduled_slot=%d wait=%v", task.ScheduledSlot, task.GetWaitTime(sys.TimeSlot))
+	}
+	if task.Status == define.TaskFailed {
+		t.Fatal("3个时隙内不应超时")
+	}
+
+	if _, err := sys.Step(1); err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != define.TaskFailed {
+		t.Errorf("超过3个时隙的仿真时间后应超时, status=%s", task.Status)
+	}
+}
+
+// TestTaskScope 测试普通用户只能看到自己提交的和名下用户设备的任务
+func TestTaskScope(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	own, err := sys.SubmitTaskAs(define.TaskOwner{ID: 2}, 5, 1e6, "test", define.PriorityNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device, err := sys.SubmitTaskAs(define.TaskOwner{ID: 1}, 6, 1e6, "test", define.PriorityNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sys.SubmitTaskAs(define.TaskOwner{ID: 1}, 7, 1e6, "test", define.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	scope := &define.TaskScope{OwnerID: 2, UserIDs: map[uint]bool{6: true}}
+	tasks, total := sys.TaskManager.GetTasksWithPage(0, 10, nil, nil, scope)
+	if total != 2 || len(tasks) != 2 || tasks[0].ID != own.ID || tasks[1].ID != device.ID {
+		t.Errorf("访问范围内应只有2个任务, got total=%d", total)
+	}
+	if _, total := sys.TaskManager.GetTasksWithPage(0, 10, nil, nil, nil); total != 3 {
+		t.Errorf("不限制访问范围时应有3个任务, got %d", total)
+	}
+
+	// 未记录提交账号的任务不属于任何账号
+	if (&define.TaskScope{}).Allows(0, 5) {
+		t.Error("空的访问范围不应允许访问未记录账号的任务")
+	}
+}
+
+// TestTaskQuota 测试账号任务配额: 超出上限的提交被拒绝，任务结束后释放配额
+func TestTaskQuota(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.SetTaskQuota(define.TaskQuota{MaxActiveTasks: 2, MaxActiveData: 3e6}); err != nil {
+		t.Fatal(err)
+	}
+
+	owner := define.TaskOwner{ID: 2}
+	first, err := sys.SubmitTaskAs(owner, 5, 1e6, "test", define.PriorityNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sys.SubmitTaskAs(owner, 5, 2.5e6, "test", define.PriorityNormal); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("超出数据量配额应被拒绝, got %v", err)
+	}
+	if _, err := sys.SubmitTaskAs(owner, 5, 1e6, "test", define.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sys.SubmitTaskAs(owner, 5, 1e6, "test", define.PriorityNormal); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("超出任务数配额应被拒绝, got %v", err)
+	}
+	if _, err := sys.SubmitTaskAs(define.TaskOwner{ID: 2, Unlimited: true}, 5, 1e6, "test", define.PriorityNormal); err != nil {
+		t.Errorf("不受限制的账号不应被拒绝: %v", err)
+	}
+	if err := sys.CheckQuota(define.TaskOwner{ID: 3}, 3, 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("整批任务超出配额应被拒绝, got %v", err)
+	}
+
+	// 取消任务后释放配额
+	if err := sys.CancelTask(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if usage := sys.QuotaUsage(owner); usage.ActiveTasks != 2 {
+		t.Errorf("取消后未结束任务数应为2, got %d", usage.ActiveTasks)
+	}
+}
+
+// TestHoldDisconnectedTasks 测试不在覆盖范围内的用户设备的Pending任务保持等待
+func TestHoldDisconnectedTasks(t *testing.T) {
+	sys := newTestSystem(t)
+	sys.UserMap[6].Connected = false
+
+	tests := []struct {
+		name   string
+		userID uint
+		status define.TaskStatus
+		held   bool
+	}{
+		{"已连接的等待任务", 5, define.TaskPending, false},
+		{"未连接的等待任务", 6, define.TaskPending, true},
+		{"未连接的已排队任务", 6, define.TaskQueued, false},
+		{"未连接的计算中任务", 6, define.TaskComputing, false},
+		{"不存在的用户设备", 99, define.TaskPending, true},
+	}
+	for _, tt := range tests {
+		task := define.NewTask(tt.userID, 1e6, "test")
+		task.Status = tt.status
+		sys.TaskManager.AddTask(task)
+
+		schedulable := sys.holdDisconnectedTasks([]*define.Task{task})
+		if held := len(schedulable) == 0; held != tt.held {
+			t.Errorf("%s: held=%v, want %v", tt.name, held, tt.held)
+		}
+		wantReason := ""
+		if tt.held {
+			wantReason = define.ReasonOutOfCoverage
+		}
+		if task.PendingReason != wantReason {
+			t.Errorf("%s: pending_reason=%q, want %q", tt.name, task.PendingReason, wantReason)
+		}
+	}
+}
+
+// TestScopedSystemInfo 测试普通用户的系统信息只统计自己的任务且不包含全局状态
+func TestScopedSystemInfo(t *testing.T) {
+	sys := newTestSystem(t)
+	sys.UserMap[6].Connected = false
+	sys.UserMap[8].Connected = false
+
+	own := define.NewTask(5, 1e6, "test")
+	own.OwnerID = 2
+	sys.TaskManager.AddTask(own)
+	for _, userID := range []uint{7, 9} {
+		task := define.NewTask(userID, 1e6, "test")
+		task.OwnerID = 1
+		sys.TaskManager.AddTask(task)
+	}
+	sys.executeOneSlot()
+
+	all := sys.GetSystemInfo()
+	if all.TaskCount != 3 || all.State == nil || len(all.DisconnectedUsers) != 2 {
+		t.Fatalf("不限制访问范围时应包含所有任务和全局状态: %+v", all)
+	}
+
+	scoped := sys.GetScopedSystemInfo(&define.TaskScope{OwnerID: 2, UserIDs: map[uint]bool{5: true, 6: true}})
+	if scoped.TaskCount != 1 || scoped.ActiveTasks != 1 || scoped.State != nil {
+		t.Errorf("访问范围内应只有1个任务且不含全局状态: %+v", scoped)
+	}
+	if _, ok := scoped.TransferPath[own.ID]; !ok || len(scoped.TransferPath) != 1 {
+		t.Errorf("传输路径应只包含自己的任务: %v", scoped.TransferPath)
+	}
+	if len(scoped.DisconnectedUsers) != 1 || scoped.DisconnectedUsers[0] != 6 {
+		t.Errorf("未覆盖的用户设备应只包含名下设备: %v", scoped.DisconnectedUsers)
+	}
+}
+
+// TestSubmitBatchAtomic 测试批量提交整批校验配额: 无效任务或超出配额时不提交任何任务，并发批次不会同时通过配额检查
+func TestSubmitBatchAtomic(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+	if err := sys.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.SetTaskQuota(define.TaskQuota{MaxActiveTasks: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	owner := define.TaskOwner{ID: 2}
+	invalid := []define.TaskBase{{UserID: 5, DataSize: 1e6}, {UserID: 99, DataSize: 1e6}}
+	if _, err := sys.SubmitTasksAs(owner, invalid); err == nil {
+		t.Fatal("包含无效任务的批次应被拒绝")
+	}
+	if count := sys.TaskManager.Count(); count != 0 {
+		t.Fatalf("被拒绝的批次不应提交任何任务, got %d", count)
+	}
+
+	batch := []define.TaskBase{{UserID: 5, DataSize: 1e6}, {UserID: 6, DataSize: 1e6}, {UserID: 5, DataSize: 1e6}}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sys.SubmitTasksAs(owner, batch)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	accepted := 0
+	for err := range errs {
+		if err == nil {
+			accepted++
+		} else if !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("应因超出配额被拒绝, got %v", err)
+		}
+	}
+	if accepted != 1 || sys.TaskManager.Count() != 3 {
+		t.Errorf("并发批次只应通过1个: accepted=%d tasks=%d", accepted, sys.TaskManager.Count())
+	}
+}
+
+// TestClearHistoryWhileRunning 测试调度循环运行时清除历史 (配合 -race 检查数据竞争)
+func TestClearHistoryWhileRunning(t *testing.T) {
+	sys := newTestSystem(t)
+	defer sys.Stop()
+	adapter := NewSystemAdapter(sys)
+
+	if err := sys.SetSpeedUp(50); err != nil {
+		t.Fatal(err)
+	}
+	task, err := sys.SubmitTask(sys.Users[0].ID, 1e12, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before uint
+	for i := 0; i < 20; i++ {
+		time.Sleep(5 * time.Millisecond)
+		before = sys.GetLoopStatus().TimeSlot
+		adapter.ClearHistory()
+		if after := sys.GetLoopStatus().TimeSlot; after < before {
+			t.Fatalf("清除历史不应重置时隙: %d -> %d", before, after)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sys.GetLoopStatus().TimeSlot <= before+2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := sys.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	status := sys.GetLoopStatus()
+	if wait := task.GetElapsedTime(status.TimeSlot); wait < define.SlotsToDuration(status.TimeSlot-1) {
+		t.Errorf("清除历史后任务计时不应停滞: elapsed=%v slot=%d", wait, status.TimeSlot)
+	}
+}
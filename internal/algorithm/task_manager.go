@@ -66,8 +66,8 @@ func (tm *TaskManager) GetTasksByStatus(status define.TaskStatus) []*define.Task
 	return tasks
 }
 
-// GetTasksWithPage 分页获取任务
-func (tm *TaskManager) GetTasksWithPage(offset, limit int, userID *uint, status *define.TaskStatus) ([]*define.Task, int64) {
+// GetTasksWithPage 分页获取任务 (scope为nil时不限制访问范围)
+func (tm *TaskManager) GetTasksWithPage(offset, limit int, userID *uint, status *define.TaskStatus, scope *define.TaskScope) ([]*define.Task, int64) {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
@@ -80,6 +80,9 @@ func (tm *TaskManager) GetTasksWithPage(offset, limit int, userID *uint, status
 		if status != nil && task.Status != *status {
 			continue
 		}
+		if !scope.AllowsTask(task) {
+			continue
+		}
 		filtered = append(filtered, task)
 	}
 
@@ -97,8 +100,24 @@ func (tm *TaskManager) GetTasksWithPage(offset, limit int, userID *uint, status
 	return filtered[offset:end], total
 }
 
-// UpdateTaskStatus 更新任务状态
-func (tm *TaskManager) UpdateTaskStatus(taskID string, newStatus define.TaskStatus) error {
+// OwnerUsage 统计账号提交的未结束任务数和数据总量
+func (tm *TaskManager) OwnerUsage(ownerID uint) (int, float64) {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	count, data := 0, 0.0
+	for _, task := range tm.TaskList {
+		if task.OwnerID != ownerID || task.Status == define.TaskCompleted || task.Status == define.TaskFailed {
+			continue
+		}
+		count++
+		data += task.DataSize
+	}
+	return count, data
+}
+
+// UpdateTaskStatus 在指定时隙更新任务状态
+func (tm *TaskManager) UpdateTaskStatus(taskID string, newStatus define.TaskStatus, timeSlot uint) error {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
@@ -110,17 +129,27 @@ func (tm *TaskManager) UpdateTaskStatus(taskID string, newStatus define.TaskStat
 	sm := task.StateMachine()
 	switch newStatus {
 	case define.TaskQueued:
-		return sm.ToQueued()
+		return sm.ToQueued(timeSlot)
 	case define.TaskComputing:
 		return sm.ToComputing()
 	case define.TaskCompleted:
-		return sm.ToCompleted()
+		return sm.ToCompleted(timeSlot)
 	case define.TaskFailed:
 		return sm.ToFailed("")
 	}
 	return nil
 }
 
+// SetPendingReason 设置任务保持等待的原因 (空字符串表示清除)
+func (tm *TaskManager) SetPendingReason(taskID string, reason string) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if task := tm.Tasks[taskID]; task != nil {
+		task.PendingReason = reason
+	}
+}
+
 // Count 获取任务总数
 func (tm *TaskManager) Count() int {
 	tm.mutex.RLock()
@@ -142,6 +171,18 @@ func (tm *TaskManager) CountCompleted() int {
 	return count
 }
 
+// CountByStatus 按状态统计任务数
+func (tm *TaskManager) CountByStatus() map[define.TaskStatus]int {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	counts := make(map[define.TaskStatus]int)
+	for _, task := range tm.TaskList {
+		counts[task.Status]++
+	}
+	return counts
+}
+
 // CancelTask 取消任务
 func (tm *TaskManager) CancelTask(taskID string) error {
 	tm.mutex.Lock()
@@ -172,18 +213,18 @@ func (tm *TaskManager) CancelTask(taskID string) error {
 	return nil
 }
 
-// CheckTimeouts 检查超时任务并标记为失败
-func (tm *TaskManager) CheckTimeouts() []string {
+// CheckTimeouts 检查在当前时隙已超时的任务并标记为失败 (按仿真时间计算)
+func (tm *TaskManager) CheckTimeouts(currentSlot uint) []string {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
 	timedOutTasks := make([]string, 0)
 
 	for _, task := range tm.TaskList {
-		if task.IsTimedOut() && task.StateMachine().IsActive() {
+		if task.IsTimedOut(currentSlot) && task.StateMachine().IsActive() {
 			// 标记超时
 			task.FailureReason = fmt.Sprintf("超时 (限制: %v, 实际: %v)",
-				task.Timeout, task.GetElapsedTime())
+				task.Timeout, task.GetElapsedTime(currentSlot))
 
 			// 转换到Failed状态
 			if err := task.StateMachine().ToFailed(task.FailureReason); err == nil {
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"go-backend/internal/service"
 	"go-backend/pkg/utils"
+	"io"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -18,23 +20,68 @@ func NewAlarmHandler(alarmService *service.AlarmService) *AlarmHandler {
 	}
 }
 
+// AcknowledgeAlarmRequest 确认告警请求
+type AcknowledgeAlarmRequest struct {
+	Note string `json:"note" example:"正在排查通信设备2的链路"` // 说明 (可选)
+}
+
+// AssignAlarmRequest 指派告警请求
+type AssignAlarmRequest struct {
+	AssigneeID *uint  `json:"assignee_id" example:"2"` // 处理人用户ID (null表示取消指派)
+	Note       string `json:"note" example:"请跟进"`      // 说明 (可选)
+}
+
+// AlarmCommentRequest 告警评论请求
+type AlarmCommentRequest struct {
+	Content string `json:"content" binding:"required" example:"已重启通信设备，观察中"`
+}
+
 // GetAlarms godoc
 // @Summary 获取告警列表
-// @Description 获取系统告警信息列表（支持分页和状态筛选）
+// @Description 获取系统告警信息列表（支持分页，按状态、级别、事件类型、处理人、指纹和抖动状态筛选）。监控器产生的告警按指纹去重，重复触发累加 occurrences
 // @Tags 告警管理
 // @Accept json
 // @Produce json
 // @Param current query int false "当前页" default(1)
 // @Param size query int false "每页大小" default(10)
-// @Param status query string false "告警状态(pending/resolved)"
+// @Param status query string false "告警状态(pending/acknowledged/resolved)"
+// @Param severity query string false "告警级别(info/warning/critical)"
+// @Param event_type query string false "事件类型"
+// @Param assignee_id query int false "处理人用户ID"
+// @Param name query string false "告警名称 (模糊匹配)"
+// @Param fingerprint query string false "告警指纹 (如 rule_3_2)"
+// @Param target query string false "告警对象ID (通信设备/用户节点/任务)"
+// @Param flapping query bool false "是否处于抖动状态"
 // @Success 200 {object} utils.Response{data=utils.PageResult}
 // @Router /alarms [get]
 func (h *AlarmHandler) GetAlarms(c *gin.Context) {
 	current, _ := strconv.Atoi(c.DefaultQuery("current", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-	status := c.Query("status")
 
-	alarms, total, err := h.alarmService.GetAlarmList(current, size, status)
+	filters := make(map[string]interface{})
+	for _, key := range []string{"status", "severity", "event_type", "name", "fingerprint", "target"} {
+		if value := c.Query(key); value != "" {
+			filters[key] = value
+		}
+	}
+	if value := c.Query("assignee_id"); value != "" {
+		assigneeID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的处理人ID")
+			return
+		}
+		filters["assignee_id"] = uint(assigneeID)
+	}
+	if value := c.Query("flapping"); value != "" {
+		flapping, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的flapping参数")
+			return
+		}
+		filters["flapping"] = flapping
+	}
+
+	alarms, total, err := h.alarmService.ListAlarms(current, size, filters)
 	if err != nil {
 		utils.Error(c, utils.ERROR, "获取告警列表失败")
 		return
@@ -71,7 +118,7 @@ func (h *AlarmHandler) GetAlarm(c *gin.Context) {
 
 // ResolveAlarm godoc
 // @Summary 解决告警
-// @Description 将告警标记为已解决状态
+// @Description 将活跃或已确认的告警标记为已解决状态
 // @Tags 告警管理
 // @Accept json
 // @Produce json
@@ -86,7 +133,7 @@ func (h *AlarmHandler) ResolveAlarm(c *gin.Context) {
 		return
 	}
 
-	if err := h.alarmService.ResolveAlarm(uint(id)); err != nil {
+	if err := h.alarmService.ResolveAlarm(uint(id), alarmActor(c)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
@@ -96,7 +143,7 @@ func (h *AlarmHandler) ResolveAlarm(c *gin.Context) {
 
 // ReactivateAlarm godoc
 // @Summary 重新激活告警
-// @Description 将已解决的告警重新激活为活跃状态
+// @Description 将已确认或已解决的告警重新激活为活跃状态 (清除确认信息)
 // @Tags 告警管理
 // @Accept json
 // @Produce json
@@ -111,7 +158,7 @@ func (h *AlarmHandler) ReactivateAlarm(c *gin.Context) {
 		return
 	}
 
-	if err := h.alarmService.ReactivateAlarm(uint(id)); err != nil {
+	if err := h.alarmService.ReactivateAlarm(uint(id), alarmActor(c)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
@@ -161,7 +208,7 @@ func (h *AlarmHandler) BatchResolveAlarms(c *gin.Context) {
 		return
 	}
 
-	if err := h.alarmService.BatchResolveAlarms(ids); err != nil {
+	if err := h.alarmService.BatchResolveAlarms(ids, alarmActor(c)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
@@ -196,7 +243,7 @@ func (h *AlarmHandler) BatchDeleteAlarms(c *gin.Context) {
 
 // GetAlarmStats godoc
 // @Summary 获取告警统计
-// @Description 获取告警的统计信息（总数、活跃数、已解决数）
+// @Description 获取告警的统计信息（总数、活跃数、已确认数、已解决数，以及按级别的分状态统计）
 // @Tags 告警管理
 // @Accept json
 // @Produce json
@@ -211,3 +258,133 @@ func (h *AlarmHandler) GetAlarmStats(c *gin.Context) {
 
 	utils.Success(c, stats)
 }
+
+// AcknowledgeAlarm godoc
+// @Summary 确认告警
+// @Description 确认活跃告警，表示已有人在处理 (记录确认人和确认时间)
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param request body AcknowledgeAlarmRequest false "确认说明"
+// @Success 200 {object} utils.Response{data=models.Alarm}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/{id}/acknowledge [post]
+func (h *AlarmHandler) AcknowledgeAlarm(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的告警ID")
+		return
+	}
+	var request AcknowledgeAlarmRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	alarm, err := h.alarmService.AcknowledgeAlarm(uint(id), alarmActor(c), request.Note)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, alarm, "告警已确认")
+}
+
+// AssignAlarm godoc
+// @Summary 指派告警
+// @Description 将告警指派给用户处理，assignee_id为null时取消指派
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param request body AssignAlarmRequest true "指派信息"
+// @Success 200 {object} utils.Response{data=models.Alarm}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/{id}/assignee [put]
+func (h *AlarmHandler) AssignAlarm(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的告警ID")
+		return
+	}
+	var request AssignAlarmRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	alarm, err := h.alarmService.AssignAlarm(uint(id), request.AssigneeID, alarmActor(c), request.Note)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, alarm, "告警已指派")
+}
+
+// AddAlarmComment godoc
+// @Summary 添加告警评论
+// @Description 为告警添加评论，记录在告警的处理历史中
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param request body AlarmCommentRequest true "评论内容"
+// @Success 200 {object} utils.Response{data=models.AlarmNote}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/{id}/comments [post]
+func (h *AlarmHandler) AddAlarmComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的告警ID")
+		return
+	}
+	var request AlarmCommentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	note, err := h.alarmService.AddComment(uint(id), alarmActor(c), request.Content)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, note, "评论已添加")
+}
+
+// GetAlarmNotes godoc
+// @Summary 获取告警处理历史
+// @Description 获取告警的评论以及确认、指派、解决、重新激活记录 (按时间正序)
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Success 200 {object} utils.Response{data=[]models.AlarmNote}
+// @Failure 404 {object} utils.Response
+// @Router /alarms/{id}/notes [get]
+func (h *AlarmHandler) GetAlarmNotes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的告警ID")
+		return
+	}
+
+	notes, err := h.alarmService.ListNotes(uint(id))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+		return
+	}
+
+	utils.Success(c, notes)
+}
+
+// alarmActor 当前请求的告警操作人
+func alarmActor(c *gin.Context) service.AlarmActor {
+	return service.AlarmActor{
+		UserID:   c.GetUint("userID"),
+		Username: c.GetString("username"),
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlarmRuleHandler struct {
+	ruleService *service.AlarmRuleService
+}
+
+func NewAlarmRuleHandler(ruleService *service.AlarmRuleService) *AlarmRuleHandler {
+	return &AlarmRuleHandler{
+		ruleService: ruleService,
+	}
+}
+
+// AlarmRuleRequest 创建或更新告警规则请求 (更新时省略的字段保持不变)
+type AlarmRuleRequest struct {
+	Name        *string                 `json:"name" example:"系统延迟过高"`
+	Description *string                 `json:"description" example:"系统总延迟超过10秒"`
+	Metric      *string                 `json:"metric" example:"total_delay"`     // 指标，见 /alarms/rules/metrics
+	Target      *string                 `json:"target" example:""`                // 对象ID (通信设备/用户设备/任务)，为空匹配所有对象
+	Comparator  *models.AlarmComparator `json:"comparator" example:"gt"`          // gt/gte/lt/lte/eq/ne
+	Threshold   *float64                `json:"threshold" example:"10"`           // 阈值
+	ForSlots    *int                    `json:"for_slots" example:"3"`            // 连续满足条件的时隙数 (默认1)
+	Severity    *models.AlarmSeverity   `json:"severity" example:"warning"`       // info/warning/critical (默认warning)
+	EventType   *models.AlarmEvent      `json:"event_type" example:"performance"` // 告警事件类型 (默认performance)
+	Enabled     *bool                   `json:"enabled" example:"true"`           // 是否启用 (默认启用)
+}
+
+func (r AlarmRuleRequest) input() service.AlarmRuleInput {
+	return service.AlarmRuleInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Metric:      r.Metric,
+		Target:      r.Target,
+		Comparator:  r.Comparator,
+		Threshold:   r.Threshold,
+		ForSlots:    r.ForSlots,
+		Severity:    r.Severity,
+		EventType:   r.EventType,
+		Enabled:     r.Enabled,
+	}
+}
+
+// ListMetrics godoc
+// @Summary 获取告警规则指标
+// @Description 获取告警规则可选的指标及其作用对象 (system/comm/user/task)
+// @Tags 告警规则
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AlarmMetricInfo}
+// @Router /alarms/rules/metrics [get]
+func (h *AlarmRuleHandler) ListMetrics(c *gin.Context) {
+	utils.Success(c, models.AlarmMetrics)
+}
+
+// ListRules godoc
+// @Summary 获取告警规则列表
+// @Description 获取所有告警规则 (包括已禁用的规则)
+// @Tags 告警规则
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AlarmRule}
+// @Router /alarms/rules [get]
+func (h *AlarmRuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.ruleService.ListRules()
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取告警规则失败")
+		return
+	}
+	utils.Success(c, rules)
+}
+
+// GetRule godoc
+// @Summary 获取告警规则
+// @Description 根据ID获取告警规则
+// @Tags 告警规则
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "规则ID"
+// @Success 200 {object} utils.Response{data=models.AlarmRule}
+// @Failure 404 {object} utils.Response
+// @Router /alarms/rules/{id} [get]
+func (h *AlarmRuleHandler) GetRule(c *gin.Context) {
+	id, ok := alarmRuleID(c)
+	if !ok {
+		return
+	}
+	rule, err := h.ruleService.GetRule(id)
+	if err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	utils.Success(c, rule)
+}
+
+// CreateRule godoc
+// @Summary 创建告警规则
+// @Description 创建告警规则，指标连续 for_slots 个时隙满足 "指标 运算符 阈值" 时产生告警，条件恢复后自动解决
+// @Tags 告警规则
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body AlarmRuleRequest true "规则信息"
+// @Success 200 {object} utils.Response{data=models.AlarmRule}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/rules [post]
+func (h *AlarmRuleHandler) CreateRule(c *gin.Context) {
+	var request AlarmRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	rule, err := h.ruleService.CreateRule(request.input())
+	if err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, rule, "告警规则已创建")
+}
+
+// UpdateRule godoc
+// @Summary 更新告警规则
+// @Description 更新告警规则，下一个时隙起生效
+// @Tags 告警规则
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "规则ID"
+// @Param request body AlarmRuleRequest true "规则信息"
+// @Success 200 {object} utils.Response{data=models.AlarmRule}
+// @Failure 400,404 {object} utils.Response
+// @Router /alarms/rules/{id} [put]
+func (h *AlarmRuleHandler) UpdateRule(c *gin.Context) {
+	id, ok := alarmRuleID(c)
+	if !ok {
+		return
+	}
+	var request AlarmRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	before, err := h.ruleService.GetRule(id)
+	if err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	rule, err := h.ruleService.UpdateRule(id, request.input())
+	if err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, rule)
+	utils.SuccessWithMessage(c, rule, "告警规则已更新")
+}
+
+// DeleteRule godoc
+// @Summary 删除告警规则
+// @Description 删除告警规则，该规则产生的活跃告警在下一个时隙自动解决
+// @Tags 告警规则
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "规则ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /alarms/rules/{id} [delete]
+func (h *AlarmRuleHandler) DeleteRule(c *gin.Context) {
+	id, ok := alarmRuleID(c)
+	if !ok {
+		return
+	}
+	before, err := h.ruleService.GetRule(id)
+	if err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	if err := h.ruleService.DeleteRule(id); err != nil {
+		alarmRuleError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, nil)
+	utils.SuccessWithMessage(c, nil, "告警规则已删除")
+}
+
+// alarmRuleID 解析路径中的规则ID
+func alarmRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的规则ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// alarmRuleError 将告警规则错误映射为响应码
+func alarmRuleError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrAlarmRuleNotFound) {
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+		return
+	}
+	utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlarmSilenceHandler struct {
+	silenceService *service.AlarmSilenceService
+}
+
+func NewAlarmSilenceHandler(silenceService *service.AlarmSilenceService) *AlarmSilenceHandler {
+	return &AlarmSilenceHandler{
+		silenceService: silenceService,
+	}
+}
+
+// AlarmSilenceRequest 创建或更新静默规则请求 (更新时省略的字段保持不变)
+type AlarmSilenceRequest struct {
+	Name        *string                    `json:"name" example:"拓扑调整"`
+	Comment     *string                    `json:"comment" example:"计划内的通信设备迁移"`
+	EventType   *models.AlarmEvent         `json:"event_type" example:"performance"`            // 匹配的事件类型，为空匹配所有
+	NamePattern *string                    `json:"name_pattern" example:"通信设备队列积压*"`            // 告警名称通配符 (* 和 ?)，为空匹配所有
+	Target      *string                    `json:"target" example:"2"`                          // 通信设备/用户节点/任务ID，为空匹配所有
+	Action      *models.AlarmSilenceAction `json:"action" example:"mark"`                       // suppress: 不产生告警; mark: 产生告警但不通知 (默认)
+	StartsAt    *time.Time                 `json:"starts_at" example:"2026-01-01T02:00:00Z"`    // 开始时间 (默认当前时间)
+	EndsAt      *time.Time                 `json:"ends_at" example:"2026-01-01T04:00:00Z"`      // 结束时间
+	Repeat      *models.AlarmSilenceRepeat `json:"repeat" example:"weekly"`                     // 维护窗口重复周期: 空/daily/weekly
+	RepeatUntil *time.Time                 `json:"repeat_until" example:"2026-06-30T00:00:00Z"` // 重复截止时间 (默认一直重复)
+}
+
+func (r AlarmSilenceRequest) input() service.AlarmSilenceInput {
+	return service.AlarmSilenceInput{
+		Name:        r.Name,
+		Comment:     r.Comment,
+		EventType:   r.EventType,
+		NamePattern: r.NamePattern,
+		Target:      r.Target,
+		Action:      r.Action,
+		StartsAt:    r.StartsAt,
+		EndsAt:      r.EndsAt,
+		Repeat:      r.Repeat,
+		RepeatUntil: r.RepeatUntil,
+	}
+}
+
+// ListSilences godoc
+// @Summary 获取告警静默列表
+// @Description 获取静默规则和维护窗口及其当前状态 (active: 生效中; pending: 未开始或处于两个窗口之间; expired: 已过期)
+// @Tags 告警静默
+// @Produce json
+// @Security ApiKeyAuth
+// @Param state query string false "状态筛选(active/pending/expired)"
+// @Success 200 {object} utils.Response{data=[]service.AlarmSilenceStatus}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/silences [get]
+func (h *AlarmSilenceHandler) ListSilences(c *gin.Context) {
+	state := service.AlarmSilenceState(c.Query("state"))
+	switch state {
+	case "", service.SilenceStateActive, service.SilenceStatePending, service.SilenceStateExpired:
+	default:
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的状态")
+		return
+	}
+
+	silences, err := h.silenceService.ListSilences(state)
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取静默规则失败")
+		return
+	}
+	utils.Success(c, silences)
+}
+
+// GetSilence godoc
+// @Summary 获取告警静默
+// @Description 根据ID获取静默规则及其当前状态
+// @Tags 告警静默
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "静默规则ID"
+// @Success 200 {object} utils.Response{data=service.AlarmSilenceStatus}
+// @Failure 404 {object} utils.Response
+// @Router /alarms/silences/{id} [get]
+func (h *AlarmSilenceHandler) GetSilence(c *gin.Context) {
+	id, ok := alarmSilenceID(c)
+	if !ok {
+		return
+	}
+	silence, err := h.silenceService.GetSilence(id)
+	if err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	utils.Success(c, h.silenceService.Status(silence))
+}
+
+// CreateSilence godoc
+// @Summary 创建告警静默
+// @Description 创建静默规则，在开始和结束时间之间按事件类型、名称通配符和对象ID匹配告警并抑制或标记为静默；设置 repeat 时按天或周重复，作为定期维护窗口
+// @Tags 告警静默
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body AlarmSilenceRequest true "静默规则"
+// @Success 200 {object} utils.Response{data=service.AlarmSilenceStatus}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/silences [post]
+func (h *AlarmSilenceHandler) CreateSilence(c *gin.Context) {
+	var request AlarmSilenceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	silence, err := h.silenceService.CreateSilence(request.input(), c.GetUint("userID"))
+	if err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, h.silenceService.Status(silence), "告警静默已创建")
+}
+
+// UpdateSilence godoc
+// @Summary 更新告警静默
+// @Description 更新静默规则，立即生效 (提前结束可将 ends_at 设为当前时间)
+// @Tags 告警静默
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "静默规则ID"
+// @Param request body AlarmSilenceRequest true "静默规则"
+// @Success 200 {object} utils.Response{data=service.AlarmSilenceStatus}
+// @Failure 400,404 {object} utils.Response
+// @Router /alarms/silences/{id} [put]
+func (h *AlarmSilenceHandler) UpdateSilence(c *gin.Context) {
+	id, ok := alarmSilenceID(c)
+	if !ok {
+		return
+	}
+	var request AlarmSilenceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	before, err := h.silenceService.GetSilence(id)
+	if err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	silence, err := h.silenceService.UpdateSilence(id, request.input())
+	if err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, silence)
+	utils.SuccessWithMessage(c, h.silenceService.Status(silence), "告警静默已更新")
+}
+
+// DeleteSilence godoc
+// @Summary 删除告警静默
+// @Description 删除静默规则，之后匹配的告警照常产生和通知
+// @Tags 告警静默
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "静默规则ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /alarms/silences/{id} [delete]
+func (h *AlarmSilenceHandler) DeleteSilence(c *gin.Context) {
+	id, ok := alarmSilenceID(c)
+	if !ok {
+		return
+	}
+	before, err := h.silenceService.GetSilence(id)
+	if err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	if err := h.silenceService.DeleteSilence(id); err != nil {
+		alarmSilenceError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, nil)
+	utils.SuccessWithMessage(c, nil, "告警静默已删除")
+}
+
+// alarmSilenceID 解析路径中的静默规则ID
+func alarmSilenceID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的静默规则ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// alarmSilenceError 将静默规则错误映射为响应码
+func alarmSilenceError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrAlarmSilenceNotFound) {
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+		return
+	}
+	utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+}
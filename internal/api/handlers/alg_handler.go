@@ -1,28 +1,174 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"go-backend/internal/algorithm"
 	"go-backend/internal/algorithm/define"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
 	"go-backend/pkg/utils"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AlgorithmHandler struct {
-	system *algorithm.SystemAdapter
+	networkService *service.NetworkService // 查询账号名下的用户设备 (任务访问范围)
+	snapshotDir    string                  // 快照文件目录
 }
 
-func NewAlgorithmHandler() *AlgorithmHandler {
+func NewAlgorithmHandler(networkService *service.NetworkService, snapshotDir string) *AlgorithmHandler {
 	return &AlgorithmHandler{
-		system: algorithm.GetAdaptedSystem(),
+		networkService: networkService,
+		snapshotDir:    snapshotDir,
 	}
 }
 
+// system 获取请求作用域内的系统实例
+// 实例路由 (/instances/:id/algorithm) 使用解析出的实例，否则使用默认实例 (每次请求重新获取，快照恢复后指向新实例)
+func (h *AlgorithmHandler) system(c *gin.Context) *algorithm.SystemAdapter {
+	if value, exists := c.Get(instanceContextKey); exists {
+		return algorithm.NewSystemAdapter(value.(*algorithm.Instance).System)
+	}
+	return algorithm.GetAdaptedSystem()
+}
+
+// instanceOwner 当前用户是否以指定权限作为实例作用域下的实例所有者
+// 所有者拥有实例内的全部权限，使用API密钥时仍受密钥权限限制
+func (h *AlgorithmHandler) instanceOwner(c *gin.Context, perm string) bool {
+	if value, exists := c.Get(instanceContextKey); exists {
+		return value.(*algorithm.Instance).OwnerID == currentUser(c) && middleware.KeyAllows(c, perm)
+	}
+	return false
+}
+
+// privileged 当前用户是否可控制调度 (拥有scheduler:control权限，或实例所有者)
+func (h *AlgorithmHandler) privileged(c *gin.Context) bool {
+	return hasPermission(c, models.PermSchedulerControl) || h.instanceOwner(c, models.PermSchedulerControl)
+}
+
+// manageTasks 当前用户是否可管理所有用户的任务 (拥有tasks:manage权限，或实例所有者)
+func (h *AlgorithmHandler) manageTasks(c *gin.Context) bool {
+	return hasPermission(c, models.PermTasksManage) || h.instanceOwner(c, models.PermTasksManage)
+}
+
+// taskScope 当前用户的任务访问范围: 自己提交的任务和名下用户设备的任务 (可管理所有任务时返回nil)
+func (h *AlgorithmHandler) taskScope(c *gin.Context) (*define.TaskScope, error) {
+	if h.manageTasks(c) {
+		return nil, nil
+	}
+	userID := currentUser(c)
+	nodeIDs, err := h.networkService.ListOwnedUserNodeIDs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户设备失败: %w", err)
+	}
+	scope := &define.TaskScope{OwnerID: userID, UserIDs: make(map[uint]bool, len(nodeIDs))}
+	for _, id := range nodeIDs {
+		scope.UserIDs[id] = true
+	}
+	return scope, nil
+}
+
+// RequirePrivileged 要求调度控制权限的中间件 (清除历史、停止算法、调度循环控制等影响所有用户的操作)
+func (h *AlgorithmHandler) RequirePrivileged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.privileged(c) {
+			utils.Error(c, utils.FORBIDDEN, "缺少权限: "+models.PermSchedulerControl)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireConfig 要求修改调度参数权限的中间件 (scheduler:config，或实例所有者)
+func (h *AlgorithmHandler) RequireConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasPermission(c, models.PermSchedulerConfig) && !h.instanceOwner(c, models.PermSchedulerConfig) {
+			utils.Error(c, utils.FORBIDDEN, "缺少权限: "+models.PermSchedulerConfig)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSystemWide 要求查看所有用户数据权限的中间件 (tasks:manage或scheduler:control，或实例所有者)
+// 用于状态历史等无法按任务访问范围过滤的系统级汇总数据
+func (h *AlgorithmHandler) RequireSystemWide() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.manageTasks(c) && !h.privileged(c) {
+			utils.Error(c, utils.FORBIDDEN, fmt.Sprintf("缺少权限: %s 或 %s", models.PermTasksManage, models.PermSchedulerControl))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// taskOwner 当前用户作为任务提交账号 (可管理所有任务时不受配额限制)
+func (h *AlgorithmHandler) taskOwner(c *gin.Context) define.TaskOwner {
+	return define.TaskOwner{ID: currentUser(c), Unlimited: h.manageTasks(c)}
+}
+
+// submitError 将任务提交错误映射为响应码
+func submitError(c *gin.Context, err error) {
+	if errors.Is(err, algorithm.ErrQuotaExceeded) {
+		utils.Error(c, utils.FORBIDDEN, err.Error())
+		return
+	}
+	utils.Error(c, utils.ERROR, fmt.Sprintf("任务提交失败: %v", err))
+}
+
+// checkSubmitScope 检查任务是否提交到当前用户名下的用户设备
+func (h *AlgorithmHandler) checkSubmitScope(c *gin.Context, requests ...define.TaskBase) bool {
+	scope, err := h.taskScope(c)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return false
+	}
+	if scope == nil {
+		return true
+	}
+	for _, request := range requests {
+		if !scope.UserIDs[request.UserID] {
+			utils.Error(c, utils.FORBIDDEN, fmt.Sprintf("无权为用户设备 %d 提交任务", request.UserID))
+			return false
+		}
+	}
+	return true
+}
+
+// scopedTask 获取当前用户可访问的任务 (不可访问时按不存在处理)
+func (h *AlgorithmHandler) scopedTask(c *gin.Context, taskID string) *define.TaskWithMetrics {
+	task := h.system(c).GetTaskByID(taskID)
+	if task == nil {
+		utils.Error(c, utils.NOT_FOUND, "任务不存在")
+		return nil
+	}
+	scope, err := h.taskScope(c)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return nil
+	}
+	if !scope.Allows(task.OwnerID, task.UserID) {
+		utils.Error(c, utils.NOT_FOUND, "任务不存在")
+		return nil
+	}
+	return task
+}
+
 // StartAlgorithm godoc
 // @Summary 提交任务
-// @Description 提交计算任务
+// @Description 提交计算任务 (普通用户只能为名下的用户设备提交，整批任务受账号配额限制)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -30,6 +176,7 @@ func NewAlgorithmHandler() *AlgorithmHandler {
 // @Param request body []TaskSubmitRequest true "任务列表"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Router /algorithm/start [post]
 func (h *AlgorithmHandler) StartAlgorithm(c *gin.Context) {
 	var requests []define.TaskBase
@@ -43,14 +190,22 @@ func (h *AlgorithmHandler) StartAlgorithm(c *gin.Context) {
 		return
 	}
 
-	tasks, _ := h.system.SubmitBatchTasks(requests)
+	if !h.checkSubmitScope(c, requests...) {
+		return
+	}
+
+	tasks, err := h.system(c).SubmitBatchTasks(h.taskOwner(c), requests)
+	if err != nil {
+		submitError(c, err)
+		return
+	}
 
 	utils.SuccessWithMessage(c, tasks, "任务提交成功")
 }
 
 // StopAlgorithm godoc
 // @Summary 停止算法
-// @Description 停止当前运行的算法
+// @Description 停止当前运行的算法 (需要scheduler:control权限)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -58,13 +213,13 @@ func (h *AlgorithmHandler) StartAlgorithm(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Router /algorithm/stop [post]
 func (h *AlgorithmHandler) StopAlgorithm(c *gin.Context) {
-	h.system.StopAlgorithm()
+	h.system(c).StopAlgorithm()
 	utils.SuccessWithMessage(c, nil, "算法已停止")
 }
 
 // GetSystemInfo godoc
 // @Summary 获取系统信息
-// @Description 获取当前系统状态信息
+// @Description 获取当前系统状态信息 (没有tasks:manage权限时任务统计和传输路径只包含自己的任务，不返回全局状态指标)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -72,13 +227,18 @@ func (h *AlgorithmHandler) StopAlgorithm(c *gin.Context) {
 // @Success 200 {object} utils.Response{data=define.SystemInfo}
 // @Router /algorithm/info [get]
 func (h *AlgorithmHandler) GetSystemInfo(c *gin.Context) {
-	info := h.system.GetSystemInfo()
+	scope, err := h.taskScope(c)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	info := h.system(c).GetScopedSystemInfo(scope)
 	utils.Success(c, info)
 }
 
 // ClearHistory godoc
 // @Summary 清除历史记录
-// @Description 清除算法执行的历史状态记录
+// @Description 清除算法执行的历史状态记录 (需要scheduler:control权限)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -86,16 +246,17 @@ func (h *AlgorithmHandler) GetSystemInfo(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Router /algorithm/clear [post]
 func (h *AlgorithmHandler) ClearHistory(c *gin.Context) {
-	h.system.ClearHistory()
+	h.system(c).ClearHistory()
 	utils.SuccessWithMessage(c, nil, "历史记录已清除")
 }
 
 // GetTasks 获取任务列表
 // @Summary 获取任务列表
-// @Description 获取所有任务或根据筛选条件获取任务
+// @Description 获取所有任务或根据筛选条件获取任务 (普通用户只能看到自己提交的和名下用户设备的任务)
 // @Tags Algorithm
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param current query int false "页码" default(1)
 // @Param size query int false "每页数量" default(10)
 // @Param user_id query int false "用户ID"
@@ -127,8 +288,14 @@ func (h *AlgorithmHandler) GetTasks(c *gin.Context) {
 		}
 	}
 
+	scope, err := h.taskScope(c)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
 	// 获取任务列表
-	tasks, total := h.system.GetTasksWithPage(offset, size, userID, status)
+	tasks, total := h.system(c).GetTasksWithPage(offset, size, userID, status, scope)
 
 	utils.SuccessWithPage(c, tasks, current, size, total)
 }
@@ -151,9 +318,8 @@ func (h *AlgorithmHandler) GetTaskByID(c *gin.Context) {
 		return
 	}
 
-	task := h.system.GetTaskByID(taskID)
+	task := h.scopedTask(c, taskID)
 	if task == nil {
-		utils.Error(c, utils.NOT_FOUND, "任务不存在")
 		return
 	}
 
@@ -162,7 +328,7 @@ func (h *AlgorithmHandler) GetTaskByID(c *gin.Context) {
 
 // SubmitTask godoc
 // @Summary 提交单个任务
-// @Description 提交单个计算任务到调度系统
+// @Description 提交单个计算任务到调度系统 (普通用户只能为名下的用户设备提交，受账号配额限制)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -170,6 +336,7 @@ func (h *AlgorithmHandler) GetTaskByID(c *gin.Context) {
 // @Param request body define.TaskBase true "任务信息"
 // @Success 200 {object} utils.Response{data=define.Task}
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Router /algorithm/tasks [post]
 func (h *AlgorithmHandler) SubmitTask(c *gin.Context) {
 	var request define.TaskBase
@@ -178,27 +345,25 @@ func (h *AlgorithmHandler) SubmitTask(c *gin.Context) {
 		return
 	}
 
-	// 提交单个任务（根据是否有优先级选择对应方法）
-	var task *define.Task
-	var err error
+	if !h.checkSubmitScope(c, request) {
+		return
+	}
 
-	if request.Priority != 0 {
-		task, err = h.system.SubmitTaskWithPriority(
-			request.UserID,
-			request.DataSize,
-			request.Type,
-			request.Priority,
-		)
-	} else {
-		task, err = h.system.SubmitTask(
-			request.UserID,
-			request.DataSize,
-			request.Type,
-		)
+	// 提交单个任务（未指定优先级时使用普通优先级）
+	priority := request.Priority
+	if priority == 0 {
+		priority = define.PriorityNormal
 	}
+	task, err := h.system(c).SubmitTaskAs(
+		h.taskOwner(c),
+		request.UserID,
+		request.DataSize,
+		request.Type,
+		priority,
+	)
 
 	if err != nil {
-		utils.Error(c, utils.ERROR, fmt.Sprintf("任务提交失败: %v", err))
+		submitError(c, err)
 		return
 	}
 
@@ -207,7 +372,7 @@ func (h *AlgorithmHandler) SubmitTask(c *gin.Context) {
 
 // DeleteTask godoc
 // @Summary 删除任务
-// @Description 根据任务ID删除任务
+// @Description 根据任务ID删除任务 (普通用户只能删除自己提交的和名下用户设备的任务)
 // @Tags 算法管理
 // @Accept json
 // @Produce json
@@ -223,7 +388,11 @@ func (h *AlgorithmHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	err := h.system.DeleteTask(taskID)
+	if h.scopedTask(c, taskID) == nil {
+		return
+	}
+
+	err := h.system(c).DeleteTask(taskID)
 	if err != nil {
 		utils.Error(c, utils.NOT_FOUND, "任务不存在")
 		return
@@ -231,3 +400,556 @@ func (h *AlgorithmHandler) DeleteTask(c *gin.Context) {
 
 	utils.SuccessWithMessage(c, nil, "任务删除成功")
 }
+
+// GetQuota godoc
+// @Summary 获取任务配额
+// @Description 获取当前账号的任务配额、未结束任务的使用情况以及名下可提交任务的用户设备
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=define.QuotaUsage}
+// @Failure 500 {object} utils.Response
+// @Router /algorithm/quota [get]
+func (h *AlgorithmHandler) GetQuota(c *gin.Context) {
+	usage := h.system(c).QuotaUsage(h.taskOwner(c))
+	nodeIDs, err := h.networkService.ListOwnedUserNodeIDs(usage.OwnerID)
+	if err != nil {
+		utils.Error(c, utils.ERROR, fmt.Sprintf("查询用户设备失败: %v", err))
+		return
+	}
+	usage.UserIDs = nodeIDs
+	utils.Success(c, usage)
+}
+
+// UpdateQuota godoc
+// @Summary 修改任务配额
+// @Description 修改每个账号的任务配额 (0表示不限制)，只影响之后提交的任务
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body define.TaskQuota true "任务配额"
+// @Success 200 {object} utils.Response{data=define.TaskQuota}
+// @Failure 400 {object} utils.Response
+// @Router /admin/algorithm/quota [put]
+func (h *AlgorithmHandler) UpdateQuota(c *gin.Context) {
+	quota := h.system(c).TaskQuota()
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	if err := h.system(c).SetTaskQuota(quota); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, quota, "任务配额已更新")
+}
+
+// LyapunovParamsResponse Lyapunov参数及变更历史
+type LyapunovParamsResponse struct {
+	Current define.LyapunovParams `json:"current"` // 当前参数
+	History []define.ParamsChange `json:"history"` // 变更历史
+}
+
+// GetLyapunovParams godoc
+// @Summary 获取Lyapunov参数
+// @Description 获取当前Lyapunov优化参数 (V, α, β, γ, Shrink, Iters, Solver) 及变更历史
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=LyapunovParamsResponse}
+// @Failure 500 {object} utils.Response
+// @Router /admin/algorithm/params [get]
+func (h *AlgorithmHandler) GetLyapunovParams(c *gin.Context) {
+	params, err := h.system(c).GetLyapunovParams()
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
+	utils.Success(c, LyapunovParamsResponse{
+		Current: params,
+		History: h.system(c).GetLyapunovParamsHistory(),
+	})
+}
+
+// UpdateLyapunovParams godoc
+// @Summary 修改Lyapunov参数
+// @Description 运行时修改Lyapunov优化参数，未提供的字段保持不变，从下一时隙开始生效
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body define.LyapunovParams true "Lyapunov参数"
+// @Success 200 {object} utils.Response{data=define.LyapunovParams}
+// @Failure 400 {object} utils.Response
+// @Router /admin/algorithm/params [put]
+func (h *AlgorithmHandler) UpdateLyapunovParams(c *gin.Context) {
+	params, err := h.system(c).GetLyapunovParams()
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+
+	// 在当前参数基础上覆盖请求中的字段
+	if err := c.ShouldBindJSON(&params); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	if err := h.system(c).SetLyapunovParams(params, c.GetString("username")); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, params, "Lyapunov参数已更新")
+}
+
+// SSE心跳间隔 (防止代理断开空闲连接)
+const streamHeartbeat = 15 * time.Second
+
+// StreamSlots godoc
+// @Summary 实时推送时隙事件
+// @Description 通过SSE推送每个时隙的系统状态、任务状态变化和调度分配 (event: slot)，每15秒发送心跳 (event: ping)。客户端消费过慢时丢弃事件，dropped字段为累计丢弃数。普通用户只接收自己可访问的任务事件，且不包含全局系统状态 (state为空)
+// @Tags 算法管理
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param user_id query int false "只接收该用户设备的任务事件"
+// @Param task_id query string false "只接收该任务的事件"
+// @Success 200 {object} define.SlotEvent
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/stream [get]
+func (h *AlgorithmHandler) StreamSlots(c *gin.Context) {
+	var filter algorithm.EventFilter
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		id, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+			return
+		}
+		uid := uint(id)
+		filter.UserID = &uid
+	}
+	filter.TaskID = c.Query("task_id")
+
+	scope, err := h.taskScope(c)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	filter.Scope = scope
+
+	// 在订阅的事件总线上取消订阅 (连接期间系统可能被快照恢复替换)
+	bus := h.system(c).Events
+	sub := bus.Subscribe(filter, 0)
+	defer bus.Unsubscribe(sub)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.SSEvent("slot", event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// GetHistory godoc
+// @Summary 查询状态历史
+// @Description 按时隙或时间范围查询每个时隙的系统状态指标，支持降采样 (bucket个时隙聚合为一个点) 和字段选择。设备字段使用 "comm_queues.<id>" 形式 (包含所有用户的数据，需要tasks:manage或scheduler:control权限)
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from_slot query int false "起始时隙 (含)"
+// @Param to_slot query int false "结束时隙 (含)"
+// @Param from query string false "起始时间 (RFC3339)"
+// @Param to query string false "结束时间 (RFC3339)"
+// @Param bucket query int false "每个桶的时隙数 (不指定时数据点过多会自动降采样)"
+// @Param agg query string false "聚合方式 avg|max" default(avg)
+// @Param fields query string false "字段列表，逗号分隔 (默认全部标量字段)"
+// @Param source query string false "数据来源 memory|db" default(memory)
+// @Success 200 {object} utils.Response{data=define.HistoryResult}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /algorithm/history [get]
+func (h *AlgorithmHandler) GetHistory(c *gin.Context) {
+	query := define.HistoryQuery{
+		Agg:    c.Query("agg"),
+		Source: c.Query("source"),
+	}
+
+	for name, target := range map[string]**uint{"from_slot": &query.FromSlot, "to_slot": &query.ToSlot} {
+		if value := c.Query(name); value != "" {
+			slot, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				utils.Error(c, utils.VALIDATION_ERROR, fmt.Sprintf("无效的%s", name))
+				return
+			}
+			v := uint(slot)
+			*target = &v
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				utils.Error(c, utils.VALIDATION_ERROR, fmt.Sprintf("无效的%s (需为RFC3339格式)", name))
+				return
+			}
+			*target = &t
+		}
+	}
+
+	if value := c.Query("bucket"); value != "" {
+		bucket, err := strconv.Atoi(value)
+		if err != nil || bucket < 1 {
+			utils.Error(c, utils.VALIDATION_ERROR, "bucket必须为正整数")
+			return
+		}
+		query.Bucket = bucket
+	}
+
+	if value := c.Query("fields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+
+	result, err := h.system(c).QueryHistory(query)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// LoopStepRequest 单步执行请求
+type LoopStepRequest struct {
+	Slots int `json:"slots" example:"1"` // 执行的时隙数 (默认1)
+}
+
+// LoopIntervalRequest 时隙间隔设置请求
+type LoopIntervalRequest struct {
+	IntervalMs int64 `json:"interval_ms" binding:"required" example:"500"` // 时隙间隔 (毫秒)
+}
+
+// LoopSpeedRequest 仿真加速比设置请求
+type LoopSpeedRequest struct {
+	SpeedUp float64 `json:"speed_up" binding:"required" example:"10"` // 仿真加速比
+}
+
+// GetLoopStatus godoc
+// @Summary 获取调度循环状态
+// @Description 获取调度循环的状态 (stopped/running/paused)、当前时隙和时隙间隔
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Router /algorithm/loop [get]
+func (h *AlgorithmHandler) GetLoopStatus(c *gin.Context) {
+	utils.Success(c, h.system(c).GetLoopStatus())
+}
+
+// StartLoop godoc
+// @Summary 启动调度循环
+// @Description 启动调度循环 (已暂停时恢复运行)
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/start [post]
+func (h *AlgorithmHandler) StartLoop(c *gin.Context) {
+	if err := h.system(c).Start(); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), "调度循环已启动")
+}
+
+// PauseLoop godoc
+// @Summary 暂停调度循环
+// @Description 暂停调度循环，暂停期间可单步执行
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/pause [post]
+func (h *AlgorithmHandler) PauseLoop(c *gin.Context) {
+	if err := h.system(c).Pause(); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), "调度循环已暂停")
+}
+
+// ResumeLoop godoc
+// @Summary 恢复调度循环
+// @Description 恢复已暂停的调度循环
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/resume [post]
+func (h *AlgorithmHandler) ResumeLoop(c *gin.Context) {
+	if err := h.system(c).Resume(); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), "调度循环已恢复")
+}
+
+// StepLoop godoc
+// @Summary 单步执行时隙
+// @Description 在停止或暂停状态下手动执行指定数量的时隙 (用于调试)
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body LoopStepRequest false "单步参数"
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/step [post]
+func (h *AlgorithmHandler) StepLoop(c *gin.Context) {
+	request := LoopStepRequest{Slots: 1}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+			return
+		}
+	}
+
+	executed, err := h.system(c).Step(request.Slots)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), fmt.Sprintf("已执行 %d 个时隙", executed))
+}
+
+// SetLoopInterval godoc
+// @Summary 设置时隙间隔
+// @Description 修改调度循环的时隙间隔 (10ms~60s)，运行中立即生效
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body LoopIntervalRequest true "时隙间隔"
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/interval [put]
+func (h *AlgorithmHandler) SetLoopInterval(c *gin.Context) {
+	var request LoopIntervalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	if err := h.system(c).SetTickInterval(time.Duration(request.IntervalMs) * time.Millisecond); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), "时隙间隔已更新")
+}
+
+// SetLoopSpeed godoc
+// @Summary 设置仿真加速比
+// @Description 修改仿真加速比，时隙间隔变为 时隙长度/加速比，运行中立即生效；任务计时按仿真时间，不受影响
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body LoopSpeedRequest true "仿真加速比"
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /algorithm/loop/speed [put]
+func (h *AlgorithmHandler) SetLoopSpeed(c *gin.Context) {
+	var request LoopSpeedRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	if err := h.system(c).SetSpeedUp(request.SpeedUp); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, h.system(c).GetLoopStatus(), "仿真加速比已更新")
+}
+
+// 上传快照的请求体大小上限
+const maxSnapshotUploadSize = 64 << 20
+
+// SnapshotSaveRequest 保存快照请求
+type SnapshotSaveRequest struct {
+	Name string `json:"name" example:"experiment-1"` // 快照名称 (为空时按时隙和时间自动生成)
+}
+
+// ListSnapshots godoc
+// @Summary 获取快照列表
+// @Description 列出快照目录中已保存的系统快照 (按时间倒序)
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]define.SnapshotInfo}
+// @Failure 500 {object} utils.Response
+// @Router /admin/algorithm/snapshots [get]
+func (h *AlgorithmHandler) ListSnapshots(c *gin.Context) {
+	infos, err := algorithm.ListSnapshots(h.snapshotDir)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.Success(c, infos)
+}
+
+// SaveSnapshot godoc
+// @Summary 保存系统快照
+// @Description 将系统完整状态 (时隙、任务、分配历史、调度器队列、拓扑) 保存到快照目录
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body SnapshotSaveRequest false "快照名称"
+// @Success 200 {object} utils.Response{data=define.SnapshotInfo}
+// @Failure 400 {object} utils.Response
+// @Router /admin/algorithm/snapshots [post]
+func (h *AlgorithmHandler) SaveSnapshot(c *gin.Context) {
+	var request SnapshotSaveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+			return
+		}
+	}
+
+	system := h.system(c)
+	if request.Name == "" {
+		request.Name = fmt.Sprintf("snapshot-%d-%s", system.GetLoopStatus().TimeSlot, time.Now().Format("20060102-150405"))
+	}
+	path, err := algorithm.SnapshotPath(h.snapshotDir, request.Name)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	info, err := system.SaveSnapshot(path)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, info, "快照已保存")
+}
+
+// DownloadSnapshot godoc
+// @Summary 下载系统快照
+// @Description 下载快照目录中的快照文件 (用于共享给其他环境恢复)
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "快照名称"
+// @Success 200 {object} define.Snapshot
+// @Failure 404 {object} utils.Response
+// @Router /admin/algorithm/snapshots/{name} [get]
+func (h *AlgorithmHandler) DownloadSnapshot(c *gin.Context) {
+	path, err := algorithm.SnapshotPath(h.snapshotDir, c.Param("name"))
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		utils.Error(c, utils.NOT_FOUND, "快照不存在")
+		return
+	}
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+// RestoreSnapshot godoc
+// @Summary 从已保存的快照恢复
+// @Description 停止当前调度循环，用快照目录中的快照重建系统 (恢复后调度循环处于停止状态)
+// @Tags 算法管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "快照名称"
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/algorithm/snapshots/{name}/restore [post]
+func (h *AlgorithmHandler) RestoreSnapshot(c *gin.Context) {
+	path, err := algorithm.SnapshotPath(h.snapshotDir, c.Param("name"))
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		utils.Error(c, utils.NOT_FOUND, "快照不存在")
+		return
+	}
+
+	snap, err := algorithm.LoadSnapshot(path)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	h.restore(c, snap)
+}
+
+// UploadSnapshot godoc
+// @Summary 上传快照并恢复
+// @Description 以请求体中的快照 (JSON) 重建系统，用于恢复其他环境导出的快照
+// @Tags 算法管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body define.Snapshot true "系统快照"
+// @Success 200 {object} utils.Response{data=define.LoopStatus}
+// @Failure 400 {object} utils.Response
+// @Router /admin/algorithm/restore [post]
+func (h *AlgorithmHandler) UploadSnapshot(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotUploadSize)
+	snap, err := algorithm.ReadSnapshot(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.Error(c, utils.VALIDATION_ERROR, fmt.Sprintf("快照大小超过上限 %dMB", maxSnapshotUploadSize>>20))
+			return
+		}
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	h.restore(c, snap)
+}
+
+// restore 用快照重建系统并替换当前实例
+func (h *AlgorithmHandler) restore(c *gin.Context, snap *define.Snapshot) {
+	system, err := algorithm.RestoreSystem(snap)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	algorithm.ReplaceSystemInstance(system)
+	utils.SuccessWithMessage(c, system.GetLoopStatus(), fmt.Sprintf("已恢复到时隙 %d", snap.TimeSlot))
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	userService   *service.UserService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService, userService *service.UserService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		userService:   userService,
+	}
+}
+
+// CreateAPIKeyRequest 创建API密钥请求
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required" example:"load-generator"`      // 密钥名称
+	Permissions []string   `json:"permissions" binding:"required" example:"tasks:submit"` // 权限列表 (不超过当前角色的权限)
+	ExpiresAt   *time.Time `json:"expires_at" example:"2027-01-01T00:00:00Z"`             // 过期时间 (为空表示永不过期)
+}
+
+// CreateAPIKeyResponse 创建API密钥响应 (明文密钥仅返回这一次)
+type CreateAPIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"` // 明文密钥，通过 X-API-Key 头或 Authorization: Bearer 使用
+}
+
+// CreateAPIKey godoc
+// @Summary 创建API密钥
+// @Description 为当前用户创建长期API密钥 (供压测工具、边缘代理等机器客户端使用，需使用登录会话创建)，明文密钥仅返回一次
+// @Tags API密钥
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body CreateAPIKeyRequest true "密钥信息"
+// @Success 200 {object} utils.Response{data=CreateAPIKeyResponse}
+// @Failure 400,403 {object} utils.Response
+// @Router /auth/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var request CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	user, err := h.userService.GetUserByID(currentUser(c))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "用户不存在")
+		return
+	}
+	key, raw, err := h.apiKeyService.CreateKey(user, service.APIKeyInput{
+		Name:        request.Name,
+		Permissions: request.Permissions,
+		ExpiresAt:   request.ExpiresAt,
+	})
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, CreateAPIKeyResponse{APIKey: *key, Key: raw}, "API密钥已创建，请妥善保存，密钥不会再次显示")
+}
+
+// ListMyAPIKeys godoc
+// @Summary 获取我的API密钥
+// @Description 获取当前用户的API密钥列表 (含最后使用时间和请求次数)
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Param current query int false "页码(默认1)"
+// @Param size query int false "每页数量(默认10)"
+// @Success 200 {object} utils.Response{data=utils.PageResult{records=[]models.APIKey}}
+// @Router /auth/api-keys [get]
+func (h *APIKeyHandler) ListMyAPIKeys(c *gin.Context) {
+	h.listKeys(c, currentUser(c))
+}
+
+// RevokeMyAPIKey godoc
+// @Summary 吊销我的API密钥
+// @Description 吊销当前用户的API密钥 (立即生效)
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "密钥ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeMyAPIKey(c *gin.Context) {
+	h.revokeKey(c, true)
+}
+
+// ListAPIKeys godoc
+// @Summary 获取所有API密钥
+// @Description 获取所有用户的API密钥及使用统计 (需要api_keys:manage权限)
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Param current query int false "页码(默认1)"
+// @Param size query int false "每页数量(默认10)"
+// @Param user_id query int false "所属用户ID"
+// @Success 200 {object} utils.Response{data=utils.PageResult{records=[]models.APIKey}}
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	h.listKeys(c, uint(userID))
+}
+
+// RevokeAPIKey godoc
+// @Summary 吊销API密钥
+// @Description 吊销任意用户的API密钥 (需要api_keys:manage权限)
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "密钥ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	h.revokeKey(c, false)
+}
+
+// listKeys 分页获取API密钥 (userID为0时返回所有用户的密钥)
+func (h *APIKeyHandler) listKeys(c *gin.Context, userID uint) {
+	current, _ := strconv.Atoi(c.DefaultQuery("current", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if current < 1 {
+		current = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	keys, total, err := h.apiKeyService.ListKeys(current, size, userID)
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取API密钥列表失败")
+		return
+	}
+	utils.SuccessWithPage(c, keys, current, size, total)
+}
+
+// revokeKey 吊销路径中的API密钥 (ownOnly时只能吊销自己的密钥，其他密钥按不存在处理)
+func (h *APIKeyHandler) revokeKey(c *gin.Context, ownOnly bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的密钥ID")
+		return
+	}
+
+	key, err := h.apiKeyService.GetKey(uint(id))
+	if err == nil && ownOnly && key.UserID != currentUser(c) {
+		err = service.ErrAPIKeyNotFound
+	}
+	if err == nil {
+		err = h.apiKeyService.RevokeKey(key.ID)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			utils.Error(c, utils.NOT_FOUND, err.Error())
+			return
+		}
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "API密钥已吊销")
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"go-backend/internal/repository"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary 获取审计日志
+// @Description 分页查询状态变更操作的审计日志 (需要audit:read权限)，按时间倒序
+// @Tags 审计日志
+// @Produce json
+// @Security ApiKeyAuth
+// @Param current query int false "页码(默认1)"
+// @Param size query int false "每页数量(默认20)"
+// @Param user_id query int false "操作用户ID"
+// @Param username query string false "操作用户名"
+// @Param method query string false "HTTP方法 (POST/PUT/PATCH/DELETE)"
+// @Param route query string false "路由关键词 (如 network/nodes)"
+// @Param target_id query string false "目标实体ID"
+// @Param success query bool false "是否成功"
+// @Param from query string false "开始时间 (RFC3339)"
+// @Param to query string false "结束时间 (RFC3339)"
+// @Success 200 {object} utils.Response{data=utils.PageResult{records=[]models.AuditLog}}
+// @Failure 400 {object} utils.Response
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	current, _ := strconv.Atoi(c.DefaultQuery("current", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if current < 1 {
+		current = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	filter := repository.AuditFilter{
+		Username: c.Query("username"),
+		Method:   strings.ToUpper(c.Query("method")),
+		Route:    c.Query("route"),
+		TargetID: c.Query("target_id"),
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的success参数")
+			return
+		}
+		filter.Success = &value
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				utils.Error(c, utils.VALIDATION_ERROR, "无效的时间格式 ("+param+")，请使用RFC3339")
+				return
+			}
+			*target = &t
+		}
+	}
+
+	logs, total, err := h.auditService.ListLogs(current, size, filter)
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取审计日志失败")
+		return
+	}
+	utils.SuccessWithPage(c, logs, current, size, total)
+}
@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"go-backend/internal/service"
 	"go-backend/pkg/utils"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +17,12 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // 需符合密码策略
+}
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -19,24 +30,29 @@ type TokenResponse struct {
 }
 
 type AuthHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
+	loginGuard   *service.LoginGuard
 }
 
-func NewAuthHandler(userService *service.UserService) *AuthHandler {
+func NewAuthHandler(userService *service.UserService, tokenService *service.TokenService, loginGuard *service.LoginGuard) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
+		userService:  userService,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
 	}
 }
 
 // Login godoc
 // @Summary 用户登录
-// @Description 用户登录并返回访问令牌和刷新令牌
+// @Description 用户登录并返回访问令牌和刷新令牌 (每次登录为一个新会话)，连续失败多次后账号被临时锁定 (429，Retry-After给出剩余秒数)
 // @Tags 认证管理
 // @Accept json
 // @Produce json
 // @Param loginRequest body LoginRequest true "登录信息"
 // @Success 200 {object} utils.Response{data=TokenResponse}
 // @Failure 401 {object} utils.Response
+// @Failure 429 {object} utils.Response
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -44,32 +60,38 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 		return
 	}
+	c.Set("username", req.Username) // 审计日志记录登录尝试的用户名
 
-	// 验证用户
-	user, err := h.userService.ValidateUser(req.Username, req.Password)
-	if err != nil {
-		utils.Error(c, utils.UNAUTHORIZED, err.Error())
+	// 账号锁定期间不再校验密码
+	if wait := h.loginGuard.Locked(req.Username, c.ClientIP()); wait > 0 {
+		utils.TooManyRequests(c, wait, lockoutMessage(wait))
 		return
 	}
 
-	// 生成访问令牌
-	accessToken, err := utils.GenerateToken(user.ID, user.Username, string(user.Role))
+	// 验证用户
+	user, err := h.userService.ValidateUser(req.Username, req.Password)
 	if err != nil {
-		utils.Error(c, utils.ERROR, "生成访问令牌失败")
+		if lockout := h.loginGuard.Fail(req.Username, c.ClientIP()); lockout > 0 {
+			utils.TooManyRequests(c, lockout, lockoutMessage(lockout))
+			return
+		}
+		utils.Error(c, utils.UNAUTHORIZED, err.Error())
 		return
 	}
+	h.loginGuard.Succeed(req.Username, c.ClientIP())
+	c.Set("userID", user.ID)
 
-	// 生成刷新令牌
-	refreshToken, err := utils.GenerateRefreshToken(user.ID, user.Username, string(user.Role))
+	// 生成访问令牌和刷新令牌
+	tokens, err := h.tokenService.IssueTokens(user)
 	if err != nil {
-		utils.Error(c, utils.ERROR, "生成刷新令牌失败")
+		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
 
 	utils.Success(c, gin.H{
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-		"expires_in":    24 * 3600, // 24小时的秒数
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
@@ -80,7 +102,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // RefreshToken godoc
 // @Summary 刷新访问令牌
-// @Description 使用刷新令牌获取新的访问令牌
+// @Description 使用刷新令牌获取新的访问令牌和刷新令牌。刷新令牌只能使用一次，重复使用已轮换的刷新令牌将注销整个会话
 // @Tags 认证管理
 // @Accept json
 // @Produce json
@@ -97,30 +119,128 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 	refreshToken := authHeader[7:]
 
-	// 解析刷新令牌
-	claims, err := utils.ParseToken(refreshToken)
+	// 轮换刷新令牌
+	tokens, err := h.tokenService.Refresh(refreshToken)
 	if err != nil {
-		utils.Error(c, utils.UNAUTHORIZED, "无效的刷新令牌")
+		utils.Error(c, utils.UNAUTHORIZED, err.Error())
+		return
+	}
+
+	utils.Success(c, tokens)
+}
+
+// Logout godoc
+// @Summary 退出登录
+// @Description 注销当前会话，吊销本次登录签发的访问令牌和刷新令牌
+// @Tags 认证管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		utils.Error(c, utils.UNAUTHORIZED, "用户未登录")
+		return
+	}
+
+	if err := h.tokenService.Logout(claims.(*utils.Claims)); err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "已退出登录")
+}
+
+// LogoutAll godoc
+// @Summary 退出所有设备
+// @Description 吊销当前用户此前签发的所有令牌 (包括当前令牌)
+// @Tags 认证管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := currentUser(c)
+	if err := h.tokenService.LogoutAll(userID); err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "已退出所有设备")
+}
+
+// ChangePassword godoc
+// @Summary 修改密码
+// @Description 验证原密码后修改当前用户的密码，吊销此前签发的所有令牌并返回新的令牌
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ChangePasswordRequest true "原密码和新密码"
+// @Success 200 {object} utils.Response{data=TokenResponse}
+// @Failure 400,401 {object} utils.Response
+// @Router /auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	userID := currentUser(c)
+	if err := h.userService.ChangePassword(userID, request.OldPassword, request.NewPassword); err != nil {
+		if errors.Is(err, service.ErrWrongPassword) {
+			utils.Error(c, utils.UNAUTHORIZED, err.Error())
+			return
+		}
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 		return
 	}
 
-	// 验证令牌类型
-	if claims.TokenType != "refresh" {
-		utils.Error(c, utils.UNAUTHORIZED, "令牌类型错误")
+	// 其他设备上的会话全部失效，当前客户端使用新令牌
+	if err := h.tokenService.LogoutAll(userID); err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "获取用户信息失败")
+		return
+	}
+	tokens, err := h.tokenService.IssueTokens(user)
+	if err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	utils.SuccessWithMessage(c, tokens, "密码已修改")
+}
 
-	// 生成新的访问令牌
-	accessToken, err := utils.GenerateToken(claims.UserID, claims.Username, claims.Role)
+// RevokeUserTokens godoc
+// @Summary 吊销用户的所有令牌
+// @Description 管理员强制指定用户在所有设备上退出登录 (不能操作权限高于自己的账号)
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/revoke-tokens [post]
+func (h *AuthHandler) RevokeUserTokens(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.Error(c, utils.ERROR, "生成新的访问令牌失败")
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+		return
+	}
+	if _, ok := manageableUser(c, h.userService, uint(id)); !ok {
 		return
 	}
 
-	utils.Success(c, gin.H{
-		"access_token": accessToken,
-		"expires_in":   24 * 3600, // 24小时的秒数
-	})
+	if err := h.tokenService.LogoutAll(uint(id)); err != nil {
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "已吊销该用户的所有令牌")
 }
 
 // GetCurrentUser godoc
@@ -150,3 +270,8 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 
 	utils.Success(c, user)
 }
+
+// lockoutMessage 账号锁定提示
+func lockoutMessage(wait time.Duration) string {
+	return fmt.Sprintf("登录失败次数过多，账号已临时锁定，请在%d秒后重试", int(math.Ceil(wait.Seconds())))
+}
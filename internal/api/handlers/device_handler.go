@@ -3,6 +3,7 @@ package handlers
 import (
 	"strconv"
 
+	"go-backend/internal/api/middleware"
 	"go-backend/internal/models"
 	"go-backend/internal/service"
 	"go-backend/pkg/utils"
@@ -20,9 +21,24 @@ func NewDeviceHandler(deviceService *service.DeviceService) *DeviceHandler {
 	}
 }
 
+// ownedDevice 获取当前用户可访问的设备 (没有devices:manage权限时只能访问名下设备，其他设备按不存在处理)
+func (h *DeviceHandler) ownedDevice(c *gin.Context, id uint) *models.Device {
+	device, err := h.deviceService.GetDevice(id)
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "设备不存在")
+		return nil
+	}
+	userID := currentUser(c)
+	if !hasPermission(c, models.PermDevicesManage) && (device.UserID == nil || *device.UserID != userID) {
+		utils.Error(c, utils.NOT_FOUND, "设备不存在")
+		return nil
+	}
+	return device
+}
+
 // ListDevices godoc
 // @Summary 获取设备列表
-// @Description 获取所有设备列表，支持分页和筛选
+// @Description 获取设备列表，支持分页和筛选 (没有devices:manage权限时只能看到名下设备，否则可按user_id筛选)
 // @Tags 设备管理
 // @Accept json
 // @Produce json
@@ -32,6 +48,7 @@ func NewDeviceHandler(deviceService *service.DeviceService) *DeviceHandler {
 // @Param search query string false "设备名称搜索关键词"
 // @Param device_type query string false "设备类型筛选"
 // @Param status query string false "设备状态筛选"
+// @Param user_id query int false "所属用户ID (需要devices:manage权限)"
 // @Success 200 {object} utils.Response{data=utils.PageResult{records=[]models.Device}}
 // @Router /devices [get]
 func (h *DeviceHandler) ListDevices(c *gin.Context) {
@@ -51,6 +68,11 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 	if search := c.Query("search"); search != "" {
 		filters["name"] = search
 	}
+	if !hasPermission(c, models.PermDevicesManage) {
+		filters["user_id"] = currentUser(c)
+	} else if owner := c.Query("user_id"); owner != "" {
+		filters["user_id"] = owner
+	}
 
 	// 获取设备列表
 	devices, total, err := h.deviceService.ListDevices(offset, size, filters)
@@ -64,7 +86,7 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 
 // GetDevice godoc
 // @Summary 获取设备详情
-// @Description 根据ID获取设备详细信息
+// @Description 根据ID获取设备详细信息 (普通用户只能访问名下设备)
 // @Tags 设备管理
 // @Accept json
 // @Produce json
@@ -80,9 +102,8 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 		return
 	}
 
-	device, err := h.deviceService.GetDevice(uint(id))
-	if err != nil {
-		utils.Error(c, utils.NOT_FOUND, "设备不存在")
+	device := h.ownedDevice(c, uint(id))
+	if device == nil {
 		return
 	}
 
@@ -91,7 +112,7 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 
 // CreateDevice godoc
 // @Summary 创建设备
-// @Description 创建新的设备
+// @Description 创建新的设备 (没有devices:manage权限时创建的设备归属于自己，否则可通过user_id指定所属用户)
 // @Tags 设备管理
 // @Accept json
 // @Produce json
@@ -107,6 +128,11 @@ func (h *DeviceHandler) CreateDevice(c *gin.Context) {
 		return
 	}
 
+	if !hasPermission(c, models.PermDevicesManage) {
+		userID := currentUser(c)
+		device.UserID = &userID
+	}
+
 	if err := h.deviceService.CreateDevice(&device); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
@@ -117,7 +143,7 @@ func (h *DeviceHandler) CreateDevice(c *gin.Context) {
 
 // UpdateDevice godoc
 // @Summary 更新设备
-// @Description 更新设备信息
+// @Description 更新设备信息 (普通用户只能更新名下设备，且不能转移所属用户)
 // @Tags 设备管理
 // @Accept json
 // @Produce json
@@ -134,24 +160,35 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 		return
 	}
 
+	existing := h.ownedDevice(c, uint(id))
+	if existing == nil {
+		return
+	}
+
 	var device models.Device
 	if err := c.ShouldBindJSON(&device); err != nil {
 		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 		return
 	}
 	device.ID = uint(id)
+	if !hasPermission(c, models.PermDevicesManage) {
+		device.UserID = existing.UserID
+	}
 
 	if err := h.deviceService.UpdateDevice(&device); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	if after, err := h.deviceService.GetDevice(uint(id)); err == nil {
+		middleware.AuditChange(c, existing, after)
+	}
 
 	utils.SuccessWithMessage(c, device, "设备更新成功")
 }
 
 // DeleteDevice godoc
 // @Summary 删除设备
-// @Description 删除指定ID的设备
+// @Description 删除指定ID的设备 (普通用户只能删除名下设备)
 // @Tags 设备管理
 // @Accept json
 // @Produce json
@@ -167,10 +204,16 @@ func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
 		return
 	}
 
+	existing := h.ownedDevice(c, uint(id))
+	if existing == nil {
+		return
+	}
+
 	if err := h.deviceService.DeleteDevice(uint(id)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	middleware.AuditChange(c, existing, nil)
 
 	utils.SuccessWithMessage(c, nil, "设备删除成功")
 }
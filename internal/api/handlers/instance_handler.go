@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-backend/internal/algorithm"
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中当前仿真实例的键
+const instanceContextKey = "instance"
+
+type InstanceHandler struct {
+	manager     *algorithm.InstanceManager
+	snapshotDir string
+}
+
+func NewInstanceHandler(manager *algorithm.InstanceManager, snapshotDir string) *InstanceHandler {
+	return &InstanceHandler{
+		manager:     manager,
+		snapshotDir: snapshotDir,
+	}
+}
+
+// CreateInstanceRequest 创建仿真实例请求
+type CreateInstanceRequest struct {
+	Name      string          `json:"name" example:"V=500对比实验"`        // 实例名称
+	Scheduler string          `json:"scheduler" example:"lyapunov"`    // 调度器类型: lyapunov | simple
+	Params    json.RawMessage `json:"params" swaggertype:"object"`     // Lyapunov参数 (未提供的字段使用默认参数)
+	SpeedUp   float64         `json:"speed_up" example:"10"`           // 仿真加速比 (为空使用默认值)
+	Snapshot  string          `json:"snapshot" example:"experiment-1"` // 从快照目录中的快照初始化 (为空则使用当前数据库拓扑)
+}
+
+// ListInstances godoc
+// @Summary 获取仿真实例列表
+// @Description 列出当前用户可访问的仿真实例 (拥有instances:manage权限可见全部)，default为所有用户共享的默认实例
+// @Tags 仿真实例
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]define.InstanceInfo}
+// @Router /instances [get]
+func (h *InstanceHandler) ListInstances(c *gin.Context) {
+	utils.Success(c, h.manager.List(currentUser(c), hasPermission(c, models.PermInstancesManage)))
+}
+
+// CreateInstance godoc
+// @Summary 创建仿真实例
+// @Description 创建独立的仿真实例 (独立的拓扑副本、调度器、参数、任务和时钟)，可指定快照初始化 (快照包含所有账号的任务，需要snapshots:manage权限)
+// @Tags 仿真实例
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body CreateInstanceRequest true "实例选项"
+// @Success 200 {object} utils.Response{data=define.InstanceInfo}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /instances [post]
+func (h *InstanceHandler) CreateInstance(c *gin.Context) {
+	var request CreateInstanceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+			return
+		}
+	}
+	// 快照包含所有账号的任务和完整拓扑，创建者又是实例所有者，因此需要快照管理权限
+	if request.Snapshot != "" && !hasPermission(c, models.PermSnapshotsManage) {
+		utils.Error(c, utils.FORBIDDEN, "缺少权限: "+models.PermSnapshotsManage)
+		return
+	}
+
+	opts := define.InstanceOptions{
+		Name:      request.Name,
+		Scheduler: request.Scheduler,
+		Params:    request.Params,
+		SpeedUp:   request.SpeedUp,
+	}
+	userID := currentUser(c)
+	username := c.GetString("username")
+
+	var instance *algorithm.Instance
+	var err error
+	if request.Snapshot != "" {
+		instance, err = h.createFromSnapshot(request.Snapshot, opts, userID, username)
+	} else {
+		instance, err = h.manager.Create(opts, userID, username)
+	}
+	if err != nil {
+		if errors.Is(err, algorithm.ErrInstanceLimit) {
+			utils.Error(c, utils.FORBIDDEN, err.Error())
+			return
+		}
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, instance.Info(), "仿真实例已创建")
+}
+
+// createFromSnapshot 从快照目录中的快照创建实例
+func (h *InstanceHandler) createFromSnapshot(name string, opts define.InstanceOptions, userID uint, username string) (*algorithm.Instance, error) {
+	path, err := algorithm.SnapshotPath(h.snapshotDir, name)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := algorithm.LoadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	system, err := algorithm.RestoreSystem(snap)
+	if err != nil {
+		return nil, err
+	}
+	return h.manager.Add(system, opts, userID, username)
+}
+
+// GetInstance godoc
+// @Summary 获取仿真实例
+// @Description 获取仿真实例信息 (仅所有者和拥有instances:manage权限的用户可访问)
+// @Tags 仿真实例
+// @Produce json
+// @Security ApiKeyAuth
+// @Param instance path string true "实例ID"
+// @Success 200 {object} utils.Response{data=define.InstanceInfo}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /instances/{instance} [get]
+func (h *InstanceHandler) GetInstance(c *gin.Context) {
+	instance, err := h.manager.Get(c.Param("instance"), currentUser(c), hasPermission(c, models.PermInstancesManage))
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	utils.Success(c, instance.Info())
+}
+
+// DeleteInstance godoc
+// @Summary 删除仿真实例
+// @Description 停止调度循环并删除仿真实例 (默认实例不可删除)
+// @Tags 仿真实例
+// @Produce json
+// @Security ApiKeyAuth
+// @Param instance path string true "实例ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /instances/{instance} [delete]
+func (h *InstanceHandler) DeleteInstance(c *gin.Context) {
+	if err := h.manager.Delete(c.Param("instance"), currentUser(c), hasPermission(c, models.PermInstancesManage)); err != nil {
+		instanceError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "仿真实例已删除")
+}
+
+// ResolveInstance 解析路径中的实例ID并检查访问权限，供实例作用域下的算法接口使用
+func (h *InstanceHandler) ResolveInstance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instance, err := h.manager.Get(c.Param("instance"), currentUser(c), hasPermission(c, models.PermInstancesManage))
+		if err != nil {
+			instanceError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(instanceContextKey, instance)
+		c.Next()
+	}
+}
+
+// currentUser 从上下文获取当前用户ID
+func currentUser(c *gin.Context) uint {
+	userID, _ := c.Get("userID")
+	id, _ := userID.(uint)
+	return id
+}
+
+// hasPermission 当前用户的角色是否拥有指定权限
+func hasPermission(c *gin.Context, perm string) bool {
+	return middleware.HasPermission(c, perm)
+}
+
+// instanceError 将实例管理错误映射为响应码
+func instanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, algorithm.ErrInstanceNotFound):
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+	case errors.Is(err, algorithm.ErrInstanceForbidden):
+		utils.Error(c, utils.FORBIDDEN, err.Error())
+	default:
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"go-backend/internal/algorithm"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRoleService 使用内存数据库创建角色服务 (包含内置角色)
+func newTestRoleService(t *testing.T) *service.RoleService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // 内存数据库每个连接独立
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.User{}, &models.Role{}); err != nil {
+		t.Fatal(err)
+	}
+	return service.NewRoleService(repository.NewRoleRepository(db), repository.NewUserRepository(db))
+}
+
+// TestCreateInstanceFromSnapshotRequiresPermission 测试只有拥有快照管理权限的用户才能从快照创建实例
+func TestCreateInstanceFromSnapshotRequiresPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	roles := newTestRoleService(t)
+	manager := algorithm.NewInstanceManager(1, 1, time.Hour)
+	defer manager.Close()
+	handler := NewInstanceHandler(manager, t.TempDir())
+
+	request := func(role models.UserRole) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("userID", uint(1))
+			c.Set("role", string(role))
+			c.Set(middleware.RoleServiceKey, roles)
+			c.Next()
+		})
+		router.POST("/instances", handler.CreateInstance)
+		req := httptest.NewRequest(http.MethodPost, "/instances", strings.NewReader(`{"snapshot":"baseline.json"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(models.RoleUser); w.Code != http.StatusForbidden {
+		t.Errorf("普通用户从快照创建实例应返回403, got %d: %s", w.Code, w.Body.String())
+	}
+	// 管理员通过权限检查，快照不存在时返回校验错误
+	if w := request(models.RoleAdmin); w.Code == http.StatusForbidden {
+		t.Errorf("管理员应通过快照权限检查, got %d: %s", w.Code, w.Body.String())
+	}
+}
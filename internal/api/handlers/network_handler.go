@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"go-backend/internal/api/middleware"
 	"go-backend/internal/models"
 	"go-backend/internal/service"
 	"go-backend/pkg/utils"
@@ -140,10 +141,18 @@ func (h *NetworkHandler) UpdateNode(c *gin.Context) {
 	}
 	node.ID = uint(id)
 
+	before, err := h.networkService.GetNode(uint(id))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "节点不存在")
+		return
+	}
 	if err := h.networkService.UpdateNode(&node); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	if after, err := h.networkService.GetNode(uint(id)); err == nil {
+		middleware.AuditChange(c, before, after)
+	}
 
 	utils.SuccessWithMessage(c, node, "节点更新成功")
 }
@@ -166,10 +175,16 @@ func (h *NetworkHandler) DeleteNode(c *gin.Context) {
 		return
 	}
 
+	before, err := h.networkService.GetNode(uint(id))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "节点不存在")
+		return
+	}
 	if err := h.networkService.DeleteNode(uint(id)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	middleware.AuditChange(c, before, nil)
 
 	utils.SuccessWithMessage(c, nil, "节点删除成功")
 }
@@ -286,10 +301,18 @@ func (h *NetworkHandler) UpdateLink(c *gin.Context) {
 	}
 	link.ID = uint(id)
 
+	before, err := h.networkService.GetLink(uint(id))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "链路不存在")
+		return
+	}
 	if err := h.networkService.UpdateLink(&link); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	if after, err := h.networkService.GetLink(uint(id)); err == nil {
+		middleware.AuditChange(c, before, after)
+	}
 
 	utils.SuccessWithMessage(c, link, "链路更新成功")
 }
@@ -312,10 +335,16 @@ func (h *NetworkHandler) DeleteLink(c *gin.Context) {
 		return
 	}
 
+	before, err := h.networkService.GetLink(uint(id))
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "链路不存在")
+		return
+	}
 	if err := h.networkService.DeleteLink(uint(id)); err != nil {
 		utils.Error(c, utils.ERROR, err.Error())
 		return
 	}
+	middleware.AuditChange(c, before, nil)
 
 	utils.SuccessWithMessage(c, nil, "链路删除成功")
 }
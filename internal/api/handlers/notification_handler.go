@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"go-backend/internal/repository"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// ListChannels godoc
+// @Summary 获取告警通知渠道
+// @Description 获取已配置的通知渠道及其路由规则 (在 configs/config.yaml 的 notifications 段配置，不返回密钥)
+// @Tags 告警通知
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]service.ChannelInfo}
+// @Router /alarms/channels [get]
+func (h *NotificationHandler) ListChannels(c *gin.Context) {
+	utils.Success(c, h.notificationService.Channels())
+}
+
+// TestChannel godoc
+// @Summary 测试告警通知渠道
+// @Description 向指定渠道发送一条测试通知 (忽略路由规则，按渠道配置重试)，返回投递结果
+// @Tags 告警通知
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "渠道名称"
+// @Success 200 {object} utils.Response{data=models.AlarmDelivery}
+// @Failure 404 {object} utils.Response
+// @Router /alarms/channels/{name}/test [post]
+func (h *NotificationHandler) TestChannel(c *gin.Context) {
+	delivery, err := h.notificationService.TestChannel(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrChannelNotFound) {
+			utils.Error(c, utils.NOT_FOUND, err.Error())
+			return
+		}
+		utils.Error(c, utils.ERROR, err.Error())
+		return
+	}
+	if !delivery.Success {
+		utils.Error(c, utils.ERROR, "测试通知发送失败: "+delivery.Error)
+		return
+	}
+	utils.SuccessWithMessage(c, delivery, "测试通知已发送")
+}
+
+// ListDeliveries godoc
+// @Summary 获取告警通知投递记录
+// @Description 分页查询告警通知的投递记录 (每个渠道一条，含重试次数和错误)，按时间倒序
+// @Tags 告警通知
+// @Produce json
+// @Security ApiKeyAuth
+// @Param current query int false "页码(默认1)"
+// @Param size query int false "每页数量(默认20)"
+// @Param alarm_id query int false "告警ID"
+// @Param channel query string false "渠道名称"
+// @Param success query bool false "是否成功"
+// @Success 200 {object} utils.Response{data=utils.PageResult{records=[]models.AlarmDelivery}}
+// @Failure 400 {object} utils.Response
+// @Router /alarms/deliveries [get]
+func (h *NotificationHandler) ListDeliveries(c *gin.Context) {
+	current, _ := strconv.Atoi(c.DefaultQuery("current", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if current < 1 {
+		current = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	filter := repository.AlarmDeliveryFilter{Channel: c.Query("channel")}
+	if alarmID := c.Query("alarm_id"); alarmID != "" {
+		id, err := strconv.ParseUint(alarmID, 10, 32)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的告警ID")
+			return
+		}
+		filter.AlarmID = uint(id)
+	}
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, "无效的success参数")
+			return
+		}
+		filter.Success = &value
+	}
+
+	deliveries, total, err := h.notificationService.ListDeliveries(current, size, filter)
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取投递记录失败")
+		return
+	}
+	utils.SuccessWithPage(c, deliveries, current, size, total)
+}
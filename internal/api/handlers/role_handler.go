@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"go-backend/internal/api/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// RoleRequest 创建或更新角色请求
+type RoleRequest struct {
+	Name        models.UserRole `json:"name" example:"operator"`                          // 角色名称 (仅创建时有效)
+	Description *string         `json:"description" example:"运维人员"`                       // 角色描述
+	Permissions []string        `json:"permissions" example:"alarms:read,alarms:resolve"` // 权限列表 (更新时为空保持不变)
+}
+
+// MyPermissionsResponse 当前用户的角色和权限
+type MyPermissionsResponse struct {
+	Role        models.UserRole `json:"role"`
+	Permissions []string        `json:"permissions"`
+}
+
+// ListPermissions godoc
+// @Summary 获取权限列表
+// @Description 获取所有可分配给角色的权限
+// @Tags 角色管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.PermissionInfo}
+// @Router /admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	utils.Success(c, models.AllPermissions)
+}
+
+// GetMyPermissions godoc
+// @Summary 获取当前用户权限
+// @Description 获取当前登录用户的角色和权限 (前端据此显示可用功能)，使用API密钥时返回密钥实际可用的权限
+// @Tags 认证管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=MyPermissionsResponse}
+// @Router /auth/permissions [get]
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	role := models.UserRole(c.GetString("role"))
+	permissions := h.roleService.Permissions(role)
+	if value, exists := c.Get(middleware.APIKeyContextKey); exists {
+		permissions = make([]string, 0)
+		for _, perm := range value.(*models.APIKey).Permissions {
+			if h.roleService.HasPermission(role, perm) {
+				permissions = append(permissions, perm)
+			}
+		}
+	}
+	utils.Success(c, MyPermissionsResponse{
+		Role:        role,
+		Permissions: permissions,
+	})
+}
+
+// ListRoles godoc
+// @Summary 获取角色列表
+// @Description 获取所有角色及其权限
+// @Tags 角色管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.Role}
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		utils.Error(c, utils.ERROR, "获取角色列表失败")
+		return
+	}
+	utils.Success(c, roles)
+}
+
+// GetRole godoc
+// @Summary 获取角色
+// @Description 根据ID获取角色及其权限
+// @Tags 角色管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "角色ID"
+// @Success 200 {object} utils.Response{data=models.Role}
+// @Failure 404 {object} utils.Response
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	role, err := h.roleService.GetRole(id)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	utils.Success(c, role)
+}
+
+// CreateRole godoc
+// @Summary 创建角色
+// @Description 创建由一组权限组成的自定义角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body RoleRequest true "角色信息"
+// @Success 200 {object} utils.Response{data=models.Role}
+// @Failure 400 {object} utils.Response
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var request RoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	role, err := h.roleService.CreateRole(service.RoleInput{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, role, "角色已创建")
+}
+
+// UpdateRole godoc
+// @Summary 更新角色
+// @Description 更新角色描述和权限，立即对该角色的所有用户生效 (内置管理员角色不可修改)
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "角色ID"
+// @Param request body RoleRequest true "角色信息"
+// @Success 200 {object} utils.Response{data=models.Role}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	var request RoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(id, service.RoleInput{
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, role, "角色已更新")
+}
+
+// DeleteRole godoc
+// @Summary 删除角色
+// @Description 删除自定义角色 (内置角色和仍有用户使用的角色不可删除)
+// @Tags 角色管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "角色ID"
+// @Success 200 {object} utils.Response
+// @Failure 400,404 {object} utils.Response
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	if err := h.roleService.DeleteRole(id); err != nil {
+		roleError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, nil, "角色已删除")
+}
+
+// roleID 解析路径中的角色ID
+func roleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的角色ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// roleError 将角色管理错误映射为响应码
+func roleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+	case errors.Is(err, service.ErrRoleBuiltIn):
+		utils.Error(c, utils.FORBIDDEN, err.Error())
+	default:
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+	}
+}
@@ -1,44 +1,85 @@
 package handlers
 
 import (
+	"errors"
+	"go-backend/internal/api/middleware"
 	"go-backend/internal/models"
 	"go-backend/internal/service"
 	"go-backend/pkg/utils"
+	"log"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
+	loginGuard   *service.LoginGuard
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, tokenService *service.TokenService, loginGuard *service.LoginGuard) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:  userService,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
 	}
 }
 
+// CreateUserRequest 创建用户请求
+type CreateUserRequest struct {
+	Username string          `json:"username" binding:"required" example:"alice"`
+	Email    string          `json:"email" binding:"required,email" example:"alice@example.com"`
+	Password string          `json:"password" binding:"required" example:"Passw0rd!"` // 需符合密码策略
+	Role     models.UserRole `json:"role" example:"user"`                             // 角色名称 (默认user，其他角色需要roles:manage权限)
+}
+
+// UpdateUserRequest 更新用户请求 (未提供的字段保持不变)
+type UpdateUserRequest struct {
+	Email *string          `json:"email" binding:"omitempty,email" example:"alice@example.com"`
+	Role  *models.UserRole `json:"role" example:"user"` // 需要roles:manage权限
+}
+
+// ResetPasswordRequest 管理员重置密码请求
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" example:"Passw0rd!"` // 为空时生成随机密码
+}
+
+// ResetPasswordResponse 重置密码结果
+type ResetPasswordResponse struct {
+	Password string `json:"password,omitempty"` // 生成的随机密码 (仅返回一次)
+}
+
 // CreateUser godoc
 // @Summary 创建新用户
-// @Description 创建新用户账号
+// @Description 创建新用户账号，密码需符合密码策略 (指定默认角色user以外的角色需要roles:manage权限)
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param user body models.User true "用户信息"
+// @Param user body CreateUserRequest true "用户信息"
 // @Success 201 {object} utils.Response{data=models.User}
-// @Failure 400 {object} utils.Response
-// @Router /users [post]
+// @Failure 400,403 {object} utils.Response
+// @Router /admin/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var request CreateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 		return
 	}
+	if request.Role != "" && request.Role != models.RoleUser && !hasPermission(c, models.PermRolesManage) {
+		utils.Error(c, utils.FORBIDDEN, "缺少权限: "+models.PermRolesManage)
+		return
+	}
 
+	user := models.User{
+		Username: request.Username,
+		Email:    request.Email,
+		Password: request.Password,
+		Role:     request.Role,
+	}
 	if err := h.userService.CreateUser(&user); err != nil {
-		utils.Error(c, utils.ERROR, err.Error())
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 		return
 	}
 
@@ -47,26 +88,23 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 // GetUser godoc
 // @Summary 获取用户信息
-// @Description 根据ID获取用户详细信息
+// @Description 根据ID获取用户详细信息 (普通用户只能查看自己)
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "用户ID"
 // @Success 200 {object} utils.Response{data=models.User}
+// @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
-	id := c.Param("id")
-
-	// 将字符串ID转换为uint
-	userID, err := strconv.ParseUint(id, 10, 32)
-	if err != nil {
-		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+	userID, ok := h.targetUser(c)
+	if !ok {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(uint(userID))
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
 		utils.Error(c, utils.NOT_FOUND, "用户不存在")
 		return
@@ -77,7 +115,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 // ListUsers godoc
 // @Summary 获取用户列表
-// @Description 获取用户列表，仅管理员可访问
+// @Description 获取用户列表 (需要users:manage权限)
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -113,10 +151,311 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	// 清除密码字段
-	for i := range users {
-		users[i].Password = ""
+	utils.SuccessWithPage(c, users, current, size, total)
+}
+
+// targetUser 解析路径中的用户ID，没有users:manage权限时只能操作自己
+func (h *UserHandler) targetUser(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+		return 0, false
 	}
+	if !hasPermission(c, models.PermUsersManage) && uint(id) != currentUser(c) {
+		utils.Error(c, utils.FORBIDDEN, "只能操作自己的账号")
+		return 0, false
+	}
+	return uint(id), true
+}
 
-	utils.SuccessWithPage(c, users, current, size, total)
+// UpdateUser godoc
+// @Summary 更新用户信息
+// @Description 更新用户邮箱 (本人或拥有users:manage权限，需要登录会话) 和角色 (需要roles:manage权限)，不能修改权限高于自己的账号，角色变更后吊销该用户已签发的令牌
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body UpdateUserRequest true "用户信息"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	userID, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+
+	var request UpdateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	if request.Role != nil && !hasPermission(c, models.PermRolesManage) {
+		utils.Error(c, utils.FORBIDDEN, "缺少权限: "+models.PermRolesManage)
+		return
+	}
+
+	before, ok := manageableUser(c, h.userService, userID)
+	if !ok {
+		return
+	}
+	if request.Role != nil && !middleware.CoversRole(c, *request.Role) {
+		utils.Error(c, utils.FORBIDDEN, "不能分配权限高于自己的角色")
+		return
+	}
+	user, err := h.userService.UpdateUser(userID, service.UpdateUserInput{Email: request.Email, Role: request.Role})
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+
+	// 令牌中携带角色，角色变更后需重新登录
+	if user.Role != before.Role {
+		h.revokeTokens(userID)
+	}
+	utils.SuccessWithMessage(c, user, "用户信息已更新")
+}
+
+// AssignRoleRequest 分配角色请求
+type AssignRoleRequest struct {
+	Role models.UserRole `json:"role" binding:"required" example:"operator"` // 角色名称
+}
+
+// AssignRole godoc
+// @Summary 分配角色
+// @Description 为用户分配角色 (需要roles:manage权限，不能操作权限高于自己的账号或分配权限高于自己的角色)，并吊销该用户已签发的令牌
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body AssignRoleRequest true "角色"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	userID, ok := h.otherUser(c)
+	if !ok {
+		return
+	}
+
+	var request AssignRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+		return
+	}
+	if _, ok := manageableUser(c, h.userService, userID); !ok {
+		return
+	}
+	if !middleware.CoversRole(c, request.Role) {
+		utils.Error(c, utils.FORBIDDEN, "不能分配权限高于自己的角色")
+		return
+	}
+
+	user, err := h.userService.UpdateUser(userID, service.UpdateUserInput{Role: &request.Role})
+	if err != nil {
+		userError(c, err)
+		return
+	}
+	h.revokeTokens(userID)
+	utils.SuccessWithMessage(c, user, "角色已分配")
+}
+
+// DeleteUser godoc
+// @Summary 删除用户
+// @Description 软删除用户 (保留记录，不能再登录)，并吊销该用户已签发的令牌 (不能删除权限高于自己的账号)
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	userID, ok := h.otherUser(c)
+	if !ok {
+		return
+	}
+	if _, ok := manageableUser(c, h.userService, userID); !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(userID); err != nil {
+		userError(c, err)
+		return
+	}
+	h.revokeTokens(userID)
+	utils.SuccessWithMessage(c, nil, "用户已删除")
+}
+
+// DisableUser godoc
+// @Summary 禁用用户
+// @Description 禁用用户 (不能登录和刷新令牌)，并吊销该用户已签发的令牌 (不能禁用权限高于自己的账号)
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	userID, ok := h.otherUser(c)
+	if !ok {
+		return
+	}
+	if _, ok := manageableUser(c, h.userService, userID); !ok {
+		return
+	}
+
+	user, err := h.userService.SetUserStatus(userID, models.UserStatusDisabled)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+	h.revokeTokens(userID)
+	utils.SuccessWithMessage(c, user, "用户已禁用")
+}
+
+// EnableUser godoc
+// @Summary 启用用户
+// @Description 重新启用已禁用的用户 (不能操作权限高于自己的账号)
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/enable [post]
+func (h *UserHandler) EnableUser(c *gin.Context) {
+	userID, ok := h.otherUser(c)
+	if !ok {
+		return
+	}
+	if _, ok := manageableUser(c, h.userService, userID); !ok {
+		return
+	}
+
+	user, err := h.userService.SetUserStatus(userID, models.UserStatusActive)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+	utils.SuccessWithMessage(c, user, "用户已启用")
+}
+
+// UnlockUser godoc
+// @Summary 解除登录锁定
+// @Description 解除用户因连续登录失败导致的临时锁定 (不能操作权限高于自己的账号)
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+		return
+	}
+	user, ok := manageableUser(c, h.userService, uint(id))
+	if !ok {
+		return
+	}
+
+	h.loginGuard.Unlock(user.Username)
+	utils.SuccessWithMessage(c, nil, "已解除登录锁定")
+}
+
+// ResetPassword godoc
+// @Summary 重置用户密码
+// @Description 管理员重置用户密码 (未指定新密码时生成随机密码并仅返回一次)，吊销该用户已签发的令牌并解除登录锁定 (不能重置权限高于自己的账号)
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body ResetPasswordRequest false "新密码"
+// @Success 200 {object} utils.Response{data=ResetPasswordResponse}
+// @Failure 400,403,404 {object} utils.Response
+// @Router /admin/users/{id}/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+		return
+	}
+
+	target, ok := manageableUser(c, h.userService, uint(id))
+	if !ok {
+		return
+	}
+
+	var request ResetPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.Error(c, utils.VALIDATION_ERROR, err.Error())
+			return
+		}
+	}
+
+	password, err := h.userService.ResetPassword(uint(id), request.NewPassword)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+	h.revokeTokens(uint(id))
+	h.loginGuard.Unlock(target.Username)
+
+	var response ResetPasswordResponse
+	if request.NewPassword == "" {
+		response.Password = password
+	}
+	utils.SuccessWithMessage(c, response, "密码已重置")
+}
+
+// otherUser 解析路径中的用户ID (不能对自己执行禁用、删除等操作)
+func (h *UserHandler) otherUser(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, utils.VALIDATION_ERROR, "无效的用户ID")
+		return 0, false
+	}
+	if uint(id) == currentUser(c) {
+		utils.Error(c, utils.VALIDATION_ERROR, "不能对自己的账号执行此操作")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// manageableUser 获取要操作的用户，不能操作权限高于当前用户的账号 (本人除外)
+func manageableUser(c *gin.Context, userService *service.UserService, userID uint) (*models.User, bool) {
+	user, err := userService.GetUserByID(userID)
+	if err != nil {
+		utils.Error(c, utils.NOT_FOUND, "用户不存在")
+		return nil, false
+	}
+	if userID != currentUser(c) && !middleware.CoversRole(c, user.Role) {
+		utils.Error(c, utils.FORBIDDEN, "不能操作权限高于自己的账号")
+		return nil, false
+	}
+	return user, true
+}
+
+// revokeTokens 吊销用户已签发的令牌 (失败时只记录日志，账号变更已生效)
+func (h *UserHandler) revokeTokens(userID uint) {
+	if err := h.tokenService.LogoutAll(userID); err != nil {
+		log.Printf("⚠️  吊销用户 %d 的令牌失败: %v", userID, err)
+	}
+}
+
+// userError 将用户管理错误映射为响应码
+func userError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUserNotFound) {
+		utils.Error(c, utils.NOT_FOUND, err.Error())
+		return
+	}
+	utils.Error(c, utils.VALIDATION_ERROR, err.Error())
 }
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"go-backend/internal/algorithm"
+	"go-backend/internal/service"
+	"go-backend/pkg/metrics"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// alarmsDesc 按事件类型、级别和状态统计的告警数
+var alarmsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(metrics.Namespace, "", "alarms"),
+	"按事件类型、级别和状态统计的告警数",
+	[]string{"event_type", "severity", "status"}, nil)
+
+// alarmCollector 导出时从数据库统计告警数
+type alarmCollector struct {
+	alarmService *service.AlarmService
+}
+
+func (c *alarmCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- alarmsDesc
+}
+
+func (c *alarmCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.alarmService.CountByGroup()
+	if err != nil {
+		log.Printf("❌ 统计告警数量失败: %v", err)
+		return
+	}
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(alarmsDesc, prometheus.GaugeValue, float64(count.Count),
+			string(count.EventType), string(count.Severity), string(count.Status))
+	}
+}
+
+// registerMetrics 注册Prometheus指标采集
+func registerMetrics(alarmService *service.AlarmService) {
+	algorithm.RegisterMetrics(metrics.Default, algorithm.GetSystemInstance)
+	metrics.Default.MustRegister(&alarmCollector{alarmService: alarmService})
+}
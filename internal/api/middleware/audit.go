@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 上下文中实体变更前后状态的键 (由处理器通过 AuditChange 设置)
+	auditChangeKey = "auditChange"
+	// 解析响应时最多缓存的响应体字节数
+	auditBodyLimit = 64 << 10
+	// 审计消息最大长度 (字符)
+	auditMessageLimit = 500
+)
+
+// auditChange 实体变更前后的状态
+type auditChange struct {
+	before interface{}
+	after  interface{}
+}
+
+// auditWriter 在写出响应的同时缓存响应体 (用于获取业务码、消息和创建的实体ID)
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditWriter) capture(data []byte) {
+	if remaining := auditBodyLimit - w.body.Len(); remaining > 0 {
+		if len(data) > remaining {
+			data = data[:remaining]
+		}
+		w.body.Write(data)
+	}
+}
+
+// AuditChange 记录本次操作修改的实体在变更前后的状态，审计记录中保存两者的字段差异
+// 创建时before为nil，删除时after为nil
+func AuditChange(c *gin.Context, before, after interface{}) {
+	c.Set(auditChangeKey, auditChange{before: before, after: after})
+}
+
+// AuditMiddleware 记录所有状态变更请求 (非GET/HEAD/OPTIONS) 的审计日志
+// 需注册在认证中间件之前，在请求处理完成后读取认证中间件设置的用户信息
+func AuditMiddleware(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return // 未匹配的路由不是有效操作
+		}
+
+		entry := &models.AuditLog{
+			CreatedAt:  start,
+			Username:   c.GetString("username"),
+			Method:     c.Request.Method,
+			Route:      route,
+			Path:       c.Request.URL.Path,
+			StatusCode: writer.Status(),
+			Success:    writer.Status() < http.StatusBadRequest,
+			ClientIP:   c.ClientIP(),
+			Duration:   time.Since(start).Milliseconds(),
+		}
+		if userID, exists := c.Get("userID"); exists {
+			entry.UserID, _ = userID.(uint)
+		}
+		if value, exists := c.Get(APIKeyContextKey); exists {
+			if key, ok := value.(*models.APIKey); ok {
+				entry.APIKeyID = &key.ID
+			}
+		}
+
+		var response struct {
+			Code int             `json:"code"`
+			Msg  string          `json:"message"`
+			Data json.RawMessage `json:"data"`
+		}
+		if json.Unmarshal(writer.body.Bytes(), &response) == nil {
+			entry.Success = entry.Success && response.Code == 0
+			entry.Message = truncate(response.Msg, auditMessageLimit)
+		}
+		entry.TargetID = auditTarget(c, response.Data)
+
+		if value, exists := c.Get(auditChangeKey); exists && entry.Success {
+			change := value.(auditChange)
+			changes, err := service.Diff(change.before, change.after)
+			if err != nil {
+				entry.Message = truncate(fmt.Sprintf("%s (计算变更差异失败: %v)", entry.Message, err), auditMessageLimit)
+			}
+			entry.Changes = changes
+		}
+
+		auditService.Record(entry)
+	}
+}
+
+// auditTarget 获取目标实体ID: 路径参数，或创建操作返回的实体ID
+func auditTarget(c *gin.Context, data json.RawMessage) string {
+	for _, param := range []string{"id", "name", "instance"} {
+		if value := c.Param(param); value != "" {
+			return value
+		}
+	}
+	var created struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if len(data) > 0 && json.Unmarshal(data, &created) == nil && len(created.ID) > 0 && string(created.ID) != "null" {
+		return strings.Trim(string(created.ID), `"`)
+	}
+	return ""
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
@@ -1,33 +1,51 @@
 package middleware
 
 import (
+	"go-backend/internal/service"
 	"go-backend/pkg/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware 是一个基于 JWT 的认证中间件
-func AuthMiddleware() gin.HandlerFunc {
+// 上下文中API密钥的键 (仅使用API密钥认证时存在)
+const APIKeyContextKey = "apiKey"
+
+// AuthMiddleware 认证中间件，支持 JWT 访问令牌 (拒绝已吊销的令牌) 和 API 密钥
+// API 密钥可通过 X-API-Key 头或 Authorization: Bearer 传递
+func AuthMiddleware(tokenService *service.TokenService, apiKeyService *service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取 Authorization header
+		// 获取 API 密钥或 Authorization header
+		apiKey := c.GetHeader("X-API-Key")
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if apiKey == "" && authHeader == "" {
 			utils.Error(c, utils.UNAUTHORIZED, "未提供认证信息")
 			c.Abort()
 			return
 		}
 
-		// 检查 Bearer token 格式
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			utils.Error(c, utils.UNAUTHORIZED, "认证格式错误")
-			c.Abort()
+		var token string
+		if authHeader != "" {
+			// 检查 Bearer token 格式
+			parts := strings.SplitN(authHeader, " ", 2)
+			if !(len(parts) == 2 && parts[0] == "Bearer") {
+				utils.Error(c, utils.UNAUTHORIZED, "认证格式错误")
+				c.Abort()
+				return
+			}
+			token = parts[1]
+			if strings.HasPrefix(token, service.APIKeyPrefix) {
+				apiKey, token = token, ""
+			}
+		}
+
+		if apiKey != "" {
+			authenticateAPIKey(c, apiKeyService, apiKey)
 			return
 		}
 
 		// 解析 token
-		claims, err := utils.ParseToken(parts[1])
+		claims, err := utils.ParseToken(token)
 		if err != nil {
 			utils.Error(c, utils.UNAUTHORIZED, "无效的token")
 			c.Abort()
@@ -35,39 +53,54 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// 验证令牌类型
-		if claims.TokenType != "access" {
+		if claims.TokenType != utils.TokenTypeAccess {
 			utils.Error(c, utils.UNAUTHORIZED, "令牌类型错误")
 			c.Abort()
 			return
 		}
 
+		// 检查令牌是否已吊销 (退出登录、刷新令牌重放等)
+		if err := tokenService.Validate(claims); err != nil {
+			utils.Error(c, utils.UNAUTHORIZED, err.Error())
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储在上下文中
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 
 		c.Next()
 	}
 }
 
-// AdminMiddleware 管理员权限检查中间件
-func AdminMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 先检查是否已经通过了认证
-		role, exists := c.Get("role")
-		if !exists {
-			utils.Error(c, utils.UNAUTHORIZED, "用户未登录")
-			c.Abort()
-			return
-		}
+// authenticateAPIKey 使用 API 密钥认证，以密钥所属用户的身份访问 (权限为密钥权限与用户角色权限的交集)
+func authenticateAPIKey(c *gin.Context, apiKeyService *service.APIKeyService, raw string) {
+	key, user, err := apiKeyService.Authenticate(raw)
+	if err != nil {
+		utils.Error(c, utils.UNAUTHORIZED, err.Error())
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", user.ID)
+	c.Set("username", user.Username)
+	c.Set("role", string(user.Role))
+	c.Set(APIKeyContextKey, key)
 
-		// 检查是否为管理员
-		if role.(string) != string("admin") {
-			utils.Error(c, utils.FORBIDDEN, "需要管理员权限")
+	c.Next()
+}
+
+// RequireSession 要求使用登录会话 (JWT) 认证，API 密钥不能用于退出登录、修改密码和管理密钥等账号操作
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("claims"); !exists {
+			utils.Error(c, utils.FORBIDDEN, "该操作需要登录会话，不能使用API密钥")
 			c.Abort()
 			return
 		}
-
 		c.Next()
 	}
 }
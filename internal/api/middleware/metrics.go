@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"go-backend/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTP请求耗时直方图 (按方法、路由模板和状态码)
+var httpRequestDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_request_duration_seconds",
+	Help:    "HTTP请求处理耗时",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// MetricsMiddleware 记录每个请求的处理耗时
+// 使用路由模板 (如 /api/v1/devices/:id) 作为标签，避免路径参数导致标签基数膨胀
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/service"
+	"go-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中角色服务的键 (处理器通过它检查细粒度权限)
+const RoleServiceKey = "roleService"
+
+// PermissionMiddleware 将角色服务注入上下文 (需在认证中间件之后)
+func PermissionMiddleware(roleService *service.RoleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(RoleServiceKey, roleService)
+		c.Next()
+	}
+}
+
+// KeyAllows 使用API密钥认证时检查密钥是否包含指定权限 (登录会话不受限制)
+func KeyAllows(c *gin.Context, perm string) bool {
+	value, exists := c.Get(APIKeyContextKey)
+	if !exists {
+		return true
+	}
+	key, ok := value.(*models.APIKey)
+	return ok && key.HasPermission(perm)
+}
+
+// HasPermission 检查当前用户的角色是否拥有指定权限 (使用API密钥时还需密钥包含该权限)
+func HasPermission(c *gin.Context, perm string) bool {
+	if !KeyAllows(c, perm) {
+		return false
+	}
+	value, exists := c.Get(RoleServiceKey)
+	if !exists {
+		return false
+	}
+	roleService, ok := value.(*service.RoleService)
+	if !ok {
+		return false
+	}
+	return roleService.HasPermission(models.UserRole(c.GetString("role")), perm)
+}
+
+// CoversRole 检查当前用户是否拥有指定角色的全部权限 (不能管理权限高于自己的账号)
+func CoversRole(c *gin.Context, role models.UserRole) bool {
+	value, exists := c.Get(RoleServiceKey)
+	if !exists {
+		return false
+	}
+	roleService, ok := value.(*service.RoleService)
+	if !ok {
+		return false
+	}
+	for _, perm := range roleService.Permissions(role) {
+		if !HasPermission(c, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// RequirePermission 权限检查中间件 (需拥有全部指定权限)
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("role"); !exists {
+			utils.Error(c, utils.UNAUTHORIZED, "用户未登录")
+			c.Abort()
+			return
+		}
+
+		for _, perm := range perms {
+			if !HasPermission(c, perm) {
+				utils.Error(c, utils.FORBIDDEN, "缺少权限: "+perm)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
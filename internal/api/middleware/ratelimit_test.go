@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"encoding/json"
+	"go-backend/pkg/ratelimit"
+	"go-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRateLimitRetryAfter 测试超出限流时返回429和Retry-After，且按客户端IP隔离
+func TestRateLimitRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	limiter := NewRateLimiter(ratelimit.Limit{Rate: 0.1, Burst: 2}, ratelimit.Limit{}, nil)
+	router.Use(limiter.LimitByIP())
+	router.GET("/ping", func(c *gin.Context) { utils.Success(c, nil) })
+
+	request := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1:1234", ""); w.Code != http.StatusOK {
+			t.Fatalf("第%d个请求应通过, got %d", i+1, w.Code)
+		}
+	}
+
+	// 未信任代理时伪造X-Forwarded-For不能绕过限流
+	w := request("10.0.0.1:1234", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超出突发容量应返回429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After应为补充一个令牌的等待秒数10, got %q", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+		t.Errorf("X-RateLimit-Limit应为桶容量2, got %q", got)
+	}
+	var body utils.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != utils.TOO_MANY_REQUESTS {
+		t.Errorf("响应体应使用统一的错误格式: %s", w.Body.String())
+	}
+
+	if w := request("10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("其他IP不应受影响, got %d", w.Code)
+	}
+}
@@ -1,52 +1,132 @@
 package api
 
 import (
+	"context"
 	"go-backend/internal/algorithm"
 	"go-backend/internal/api/handlers"
 	"go-backend/internal/api/middleware"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
 	"go-backend/internal/repository"
 	"go-backend/internal/service"
 	"go-backend/pkg/database"
+	"go-backend/pkg/metrics"
+	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRoutes 设置所有路由
-func SetupRoutes(router *gin.Engine) {
+// SetupRoutes 设置所有路由，返回关闭服务时释放后台资源的函数
+func SetupRoutes(router *gin.Engine, cfg *config.Config) (shutdown func(ctx context.Context)) {
 	// 获取数据库连接
 	db := database.GetDB()
 
+	// 只信任配置的反向代理转发的客户端IP，否则客户端可伪造X-Forwarded-For绕过按IP的限流和登录锁定
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Printf("⚠️  受信任代理配置无效，不信任任何代理: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	// 记录HTTP请求耗时 (需在注册路由之前)
+	router.Use(middleware.MetricsMiddleware())
+
+	// 按IP、用户和接口限流
+	rateLimiter := newRateLimiter(cfg)
+	if cfg.RateLimit.Enabled {
+		router.Use(rateLimiter.LimitByIP())
+	}
+
 	// 初始化仓储层
 	userRepo := repository.NewUserRepository(db)
 	deviceRepo := repository.NewDeviceRepository(db)
 	nodeRepo := repository.NewNodeRepository(db)
 	linkRepo := repository.NewLinkRepository(db)
 	alarmRepo := repository.NewAlarmRepository(db)
+	alarmRuleRepo := repository.NewAlarmRuleRepository(db)
+	alarmDeliveryRepo := repository.NewAlarmDeliveryRepository(db)
+	alarmSilenceRepo := repository.NewAlarmSilenceRepository(db)
+	stateRecordRepo := repository.NewStateRecordRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
 
 	// 初始化服务层
-	userService := service.NewUserService(userRepo)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	userService := service.NewUserService(userRepo, roleService)
 	deviceService := service.NewDeviceService(deviceRepo, nodeRepo, linkRepo)
 	networkService := service.NewNetworkService(nodeRepo, linkRepo)
 	monitorService := service.NewMonitorService()
-	alarmService := service.NewAlarmService(alarmRepo)
+	alarmService := service.NewAlarmService(alarmRepo, userRepo)
+	alarmRuleService := service.NewAlarmRuleService(alarmRuleRepo)
+	alarmSilenceService := service.NewAlarmSilenceService(alarmSilenceRepo)
+	notificationService := service.NewNotificationService(alarmDeliveryRepo, cfg.Notifications.Channels)
+	alarmService.SetNotifier(notificationService)
+	alarmService.SetFlapPolicy(newAlarmFlapPolicy(cfg))
+	stateHistoryService := service.NewStateHistoryService(stateRecordRepo)
+	tokenService := service.NewTokenService(tokenRepo, userRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, roleService)
+	auditService := service.NewAuditService(auditRepo)
+	loginGuard := newLoginGuard(cfg)
+	userService.SetPasswordPolicy(service.PasswordPolicy{
+		MinLength:     cfg.PasswordPolicy.MinLength,
+		RequireLetter: cfg.PasswordPolicy.RequireLetter,
+		RequireDigit:  cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol: cfg.PasswordPolicy.RequireSymbol,
+	})
+
+	// 记录所有状态变更操作的审计日志 (需在注册路由之前)
+	router.Use(middleware.AuditMiddleware(auditService))
 
 	// 初始化告警监控器并注入到算法系统
-	alarmMonitor := algorithm.NewAlarmMonitor(alarmService)
+	alarmMonitor := algorithm.NewAlarmMonitor(alarmService, alarmRuleService, alarmSilenceService)
 	system := algorithm.GetSystemInstance()
 	system.SetAlarmMonitor(alarmMonitor)
 
+	// 按配置启用状态历史持久化
+	if cfg.History.Persist {
+		system.SetStateStore(stateHistoryService)
+	}
+
+	// 注册监控指标并导出 (Prometheus文本格式)，默认需要metrics:read权限
+	registerMetrics(alarmService)
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Public {
+			log.Println("⚠️  /metrics 无需认证即可访问，请确保仅在受信任的网络中暴露")
+			router.GET("/metrics", gin.WrapH(metrics.Handler()))
+		} else {
+			router.GET("/metrics",
+				middleware.AuthMiddleware(tokenService, apiKeyService),
+				middleware.PermissionMiddleware(roleService),
+				middleware.RequirePermission(models.PermMetricsRead),
+				gin.WrapH(metrics.Handler()))
+		}
+	}
+
 	// 初始化处理器
-	authHandler := handlers.NewAuthHandler(userService)
-	userHandler := handlers.NewUserHandler(userService)
+	authHandler := handlers.NewAuthHandler(userService, tokenService, loginGuard)
+	userHandler := handlers.NewUserHandler(userService, tokenService, loginGuard)
 	deviceHandler := handlers.NewDeviceHandler(deviceService)
 	networkHandler := handlers.NewNetworkHandler(networkService)
 	overviewHandler := handlers.NewOverviewHandler(deviceService, networkService, userService, monitorService, alarmService)
 	alarmHandler := handlers.NewAlarmHandler(alarmService)
+	alarmRuleHandler := handlers.NewAlarmRuleHandler(alarmRuleService)
+	alarmSilenceHandler := handlers.NewAlarmSilenceHandler(alarmSilenceService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	healthHandler := handlers.NewHealthHandler()
-	algorithmHandler := handlers.NewAlgorithmHandler()
+	algorithmHandler := handlers.NewAlgorithmHandler(networkService, cfg.Snapshot.Dir)
+	instanceManager := newInstanceManager(cfg)
+	instanceHandler := handlers.NewInstanceHandler(instanceManager, cfg.Snapshot.Dir)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, userService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 
 	// 公开路由组
 	public := router.Group("/api/v1")
+	if cfg.RateLimit.Enabled {
+		public.Use(rateLimiter.LimitByClient())
+	}
 	{
 		// 健康检查路由
 		public.GET("/health", healthHandler.CheckHealth)
@@ -58,102 +138,321 @@ func SetupRoutes(router *gin.Engine) {
 			auth.POST("/refresh", authHandler.RefreshToken)
 		}
 
-		// 算法管理路由
-		algorithm := public.Group("/algorithm")
-		{
-			algorithm.POST("/start", algorithmHandler.StartAlgorithm)
-			algorithm.POST("/stop", algorithmHandler.StopAlgorithm)
-			algorithm.GET("/info", algorithmHandler.GetSystemInfo)
-			algorithm.POST("/clear", algorithmHandler.ClearHistory)
-			algorithm.GET("/tasks", algorithmHandler.GetTasks)
-			algorithm.POST("/tasks", algorithmHandler.SubmitTask)
-			algorithm.GET("/tasks/:id", algorithmHandler.GetTaskByID)
-			algorithm.DELETE("/tasks/:id", algorithmHandler.DeleteTask)
-		}
-
 		// 系统监控（公开访问，方便Dashboard）
 		public.GET("/system/metrics", overviewHandler.GetSystemMetrics)
-
-		// 告警管理路由
-		alarms := public.Group("/alarms")
-		{
-			alarms.GET("", alarmHandler.GetAlarms)                         // 获取告警列表
-			alarms.GET("/stats", alarmHandler.GetAlarmStats)               // 获取告警统计
-			alarms.GET("/:id", alarmHandler.GetAlarm)                      // 获取单个告警
-			alarms.POST("/:id/resolve", alarmHandler.ResolveAlarm)         // 解决告警
-			alarms.POST("/:id/reactivate", alarmHandler.ReactivateAlarm)   // 重新激活告警
-			alarms.DELETE("/:id", alarmHandler.DeleteAlarm)                // 删除告警
-			alarms.POST("/batch/resolve", alarmHandler.BatchResolveAlarms) // 批量解决
-			alarms.POST("/batch/delete", alarmHandler.BatchDeleteAlarms)   // 批量删除
-		}
 	}
 
-	// 需要认证的路由组
+	// 需要认证的路由组 (JWT或API密钥，每个路由按所需权限检查，角色的权限在 /admin/roles 中管理)
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(tokenService, apiKeyService), middleware.PermissionMiddleware(roleService))
+	if cfg.RateLimit.Enabled {
+		protected.Use(rateLimiter.LimitByClient())
+	}
 	{
 		// 系统概览
-		protected.GET("/overview", overviewHandler.GetOverview)
+		protected.GET("/overview", middleware.RequirePermission(models.PermTopologyRead), overviewHandler.GetOverview)
 
-		// 认证相关路由
+		// 认证相关路由 (仅操作当前用户自己，账号操作需要登录会话)
 		auth := protected.Group("/auth")
 		{
+			session := middleware.RequireSession()
+
 			auth.GET("/me", authHandler.GetCurrentUser)
+			auth.GET("/permissions", roleHandler.GetMyPermissions)
+			auth.POST("/logout", session, authHandler.Logout)
+			auth.POST("/logout-all", session, authHandler.LogoutAll)
+			auth.PUT("/password", session, authHandler.ChangePassword)
+
+			// API密钥 (机器客户端使用)
+			auth.GET("/api-keys", session, apiKeyHandler.ListMyAPIKeys)
+			auth.POST("/api-keys", session, apiKeyHandler.CreateAPIKey)
+			auth.DELETE("/api-keys/:id", session, apiKeyHandler.RevokeMyAPIKey)
 		}
 
-		// 用户管理路由
+		// 算法管理路由 (默认实例，没有tasks:manage权限时只能访问自己的任务)
+		registerAlgorithmRoutes(protected.Group("/algorithm"), algorithmHandler)
+
+		// 告警管理路由
+		alarms := protected.Group("/alarms")
+		{
+			canRead := middleware.RequirePermission(models.PermAlarmsRead)
+			canResolve := middleware.RequirePermission(models.PermAlarmsResolve)
+			canDelete := middleware.RequirePermission(models.PermAlarmsDelete)
+
+			alarms.GET("", canRead, alarmHandler.GetAlarms)                            // 获取告警列表
+			alarms.GET("/stats", canRead, alarmHandler.GetAlarmStats)                  // 获取告警统计
+			alarms.GET("/:id", canRead, alarmHandler.GetAlarm)                         // 获取单个告警
+			alarms.POST("/:id/resolve", canResolve, alarmHandler.ResolveAlarm)         // 解决告警
+			alarms.POST("/:id/reactivate", canResolve, alarmHandler.ReactivateAlarm)   // 重新激活告警
+			alarms.POST("/:id/acknowledge", canResolve, alarmHandler.AcknowledgeAlarm) // 确认告警
+			alarms.PUT("/:id/assignee", canResolve, alarmHandler.AssignAlarm)          // 指派处理人
+			alarms.GET("/:id/notes", canRead, alarmHandler.GetAlarmNotes)              // 评论和处理历史
+			alarms.POST("/:id/comments", canResolve, alarmHandler.AddAlarmComment)     // 添加评论
+			alarms.DELETE("/:id", canDelete, alarmHandler.DeleteAlarm)                 // 删除告警
+			alarms.POST("/batch/resolve", canResolve, alarmHandler.BatchResolveAlarms) // 批量解决
+			alarms.POST("/batch/delete", canDelete, alarmHandler.BatchDeleteAlarms)    // 批量删除
+
+			// 告警规则 (每个时隙由告警监控器求值)
+			canManageRules := middleware.RequirePermission(models.PermAlarmsRules)
+			alarms.GET("/rules", canRead, alarmRuleHandler.ListRules)
+			alarms.GET("/rules/metrics", canRead, alarmRuleHandler.ListMetrics)
+			alarms.GET("/rules/:id", canRead, alarmRuleHandler.GetRule)
+			alarms.POST("/rules", canManageRules, alarmRuleHandler.CreateRule)
+			alarms.PUT("/rules/:id", canManageRules, alarmRuleHandler.UpdateRule)
+			alarms.DELETE("/rules/:id", canManageRules, alarmRuleHandler.DeleteRule)
+
+			// 告警通知渠道 (在配置文件中配置) 和投递记录
+			alarms.GET("/channels", canManageRules, notificationHandler.ListChannels)
+			alarms.POST("/channels/:name/test", canManageRules, notificationHandler.TestChannel)
+			alarms.GET("/deliveries", canManageRules, notificationHandler.ListDeliveries)
+
+			// 告警静默和定期维护窗口
+			canSilence := middleware.RequirePermission(models.PermAlarmsSilence)
+			alarms.GET("/silences", canRead, alarmSilenceHandler.ListSilences)
+			alarms.GET("/silences/:id", canRead, alarmSilenceHandler.GetSilence)
+			alarms.POST("/silences", canSilence, alarmSilenceHandler.CreateSilence)
+			alarms.PUT("/silences/:id", canSilence, alarmSilenceHandler.UpdateSilence)
+			alarms.DELETE("/silences/:id", canSilence, alarmSilenceHandler.DeleteSilence)
+		}
+
+		// 仿真实例 (每个实例拥有独立的拓扑、调度器、任务和时钟)
+		instances := protected.Group("/instances")
+		instances.Use(middleware.RequirePermission(models.PermInstancesCreate))
+		{
+			instances.GET("", instanceHandler.ListInstances)
+			instances.POST("", instanceHandler.CreateInstance)
+			instances.GET("/:instance", instanceHandler.GetInstance)
+			instances.DELETE("/:instance", instanceHandler.DeleteInstance)
+
+			// 实例作用域下的算法接口 (仅所有者和拥有instances:manage权限的用户，所有者拥有实例内的管理权限)
+			instanceAlgorithm := instances.Group("/:instance/algorithm")
+			instanceAlgorithm.Use(instanceHandler.ResolveInstance())
+			registerAlgorithmRoutes(instanceAlgorithm, algorithmHandler)
+		}
+
+		// 用户管理路由 (没有users:manage权限时只能操作自己，修改账号需要登录会话)
 		users := protected.Group("/users")
 		{
 			users.GET("/:id", userHandler.GetUser)
+			users.PUT("/:id", middleware.RequireSession(), userHandler.UpdateUser)
 		}
 
-		// 管理员专用路由
+		// 管理路由
 		admin := protected.Group("/admin")
-		admin.Use(middleware.AdminMiddleware())
 		{
-			// 管理员用户管理
+			// 用户管理
 			adminUsers := admin.Group("/users")
 			{
-				adminUsers.GET("", userHandler.ListUsers)
-				adminUsers.POST("", userHandler.CreateUser)
+				canManage := middleware.RequirePermission(models.PermUsersManage)
+
+				adminUsers.GET("", canManage, userHandler.ListUsers)
+				adminUsers.POST("", canManage, userHandler.CreateUser)
+				adminUsers.DELETE("/:id", canManage, userHandler.DeleteUser)
+				adminUsers.POST("/:id/disable", canManage, userHandler.DisableUser)
+				adminUsers.POST("/:id/enable", canManage, userHandler.EnableUser)
+				adminUsers.POST("/:id/reset-password", canManage, userHandler.ResetPassword)
+				adminUsers.POST("/:id/unlock", canManage, userHandler.UnlockUser)
+				adminUsers.POST("/:id/revoke-tokens", canManage, authHandler.RevokeUserTokens)
+				adminUsers.PUT("/:id/role", middleware.RequirePermission(models.PermRolesManage), userHandler.AssignRole)
+			}
+
+			// 角色与权限管理
+			admin.GET("/permissions", middleware.RequirePermission(models.PermRolesManage), roleHandler.ListPermissions)
+			adminRoles := admin.Group("/roles")
+			adminRoles.Use(middleware.RequirePermission(models.PermRolesManage))
+			{
+				adminRoles.GET("", roleHandler.ListRoles)
+				adminRoles.POST("", roleHandler.CreateRole)
+				adminRoles.GET("/:id", roleHandler.GetRole)
+				adminRoles.PUT("/:id", roleHandler.UpdateRole)
+				adminRoles.DELETE("/:id", roleHandler.DeleteRole)
+			}
+
+			// API密钥管理 (查看使用统计和吊销)
+			adminAPIKeys := admin.Group("/api-keys")
+			adminAPIKeys.Use(middleware.RequirePermission(models.PermAPIKeysManage))
+			{
+				adminAPIKeys.GET("", apiKeyHandler.ListAPIKeys)
+				adminAPIKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+			}
+
+			// 审计日志
+			admin.GET("/audit-logs", middleware.RequirePermission(models.PermAuditRead), auditHandler.ListAuditLogs)
+
+			// 算法参数管理
+			adminAlgorithm := admin.Group("/algorithm")
+			{
+				canConfig := algorithmHandler.RequireConfig()
+				canSnapshot := middleware.RequirePermission(models.PermSnapshotsManage)
+
+				adminAlgorithm.GET("/params", middleware.RequirePermission(models.PermTasksRead), algorithmHandler.GetLyapunovParams)
+				adminAlgorithm.PUT("/params", canConfig, algorithmHandler.UpdateLyapunovParams)
+				adminAlgorithm.PUT("/quota", canConfig, algorithmHandler.UpdateQuota)
+
+				// 系统快照与恢复
+				adminAlgorithm.GET("/snapshots", canSnapshot, algorithmHandler.ListSnapshots)
+				adminAlgorithm.POST("/snapshots", canSnapshot, algorithmHandler.SaveSnapshot)
+				adminAlgorithm.GET("/snapshots/:name", canSnapshot, algorithmHandler.DownloadSnapshot)
+				adminAlgorithm.POST("/snapshots/:name/restore", canSnapshot, algorithmHandler.RestoreSnapshot)
+				adminAlgorithm.POST("/restore", canSnapshot, algorithmHandler.UploadSnapshot)
 			}
 		}
 
-		// 设备管理路由
+		// 设备管理路由 (没有devices:manage权限时只能访问名下设备)
 		devices := protected.Group("/devices")
 		{
-			devices.GET("", deviceHandler.ListDevices)
-			devices.POST("", deviceHandler.CreateDevice)
-			devices.GET("/:id", deviceHandler.GetDevice)
-			devices.PUT("/:id", deviceHandler.UpdateDevice)
-			devices.DELETE("/:id", deviceHandler.DeleteDevice)
+			canRead := middleware.RequirePermission(models.PermDevicesRead)
+			canWrite := middleware.RequirePermission(models.PermDevicesWrite)
+
+			devices.GET("", canRead, deviceHandler.ListDevices)
+			devices.POST("", canWrite, deviceHandler.CreateDevice)
+			devices.GET("/:id", canRead, deviceHandler.GetDevice)
+			devices.PUT("/:id", canWrite, deviceHandler.UpdateDevice)
+			devices.DELETE("/:id", canWrite, deviceHandler.DeleteDevice)
 		}
 
 		// 网络管理路由
 		network := protected.Group("/network")
-		{ // 节点管理
+		{
+			canRead := middleware.RequirePermission(models.PermTopologyRead)
+			canWrite := middleware.RequirePermission(models.PermTopologyWrite)
+
+			// 节点管理
 			nodes := network.Group("/nodes")
 			{
-				nodes.GET("", networkHandler.ListNodes)
-				nodes.POST("", networkHandler.CreateNode)
-				nodes.GET("/:id", networkHandler.GetNode)
-				nodes.PUT("/:id", networkHandler.UpdateNode)
-				nodes.DELETE("/:id", networkHandler.DeleteNode)
-				nodes.PATCH("/batch-position", networkHandler.BatchUpdateNodesPosition) // 批量更新节点位置
+				nodes.GET("", canRead, networkHandler.ListNodes)
+				nodes.POST("", canWrite, networkHandler.CreateNode)
+				nodes.GET("/:id", canRead, networkHandler.GetNode)
+				nodes.PUT("/:id", canWrite, networkHandler.UpdateNode)
+				nodes.DELETE("/:id", canWrite, networkHandler.DeleteNode)
+				nodes.PATCH("/batch-position", canWrite, networkHandler.BatchUpdateNodesPosition) // 批量更新节点位置
 			}
 
 			// 链路管理
 			links := network.Group("/links")
 			{
-				links.GET("", networkHandler.ListLinks)
-				links.POST("", networkHandler.CreateLink)
-				links.GET("/:id", networkHandler.GetLink)
-				links.PUT("/:id", networkHandler.UpdateLink)
-				links.DELETE("/:id", networkHandler.DeleteLink)
+				links.GET("", canRead, networkHandler.ListLinks)
+				links.POST("", canWrite, networkHandler.CreateLink)
+				links.GET("/:id", canRead, networkHandler.GetLink)
+				links.PUT("/:id", canWrite, networkHandler.UpdateLink)
+				links.DELETE("/:id", canWrite, networkHandler.DeleteLink)
 			}
 
 			// 获取完整网络拓扑
-			network.GET("/topology", networkHandler.GetTopology)
+			network.GET("/topology", canRead, networkHandler.GetTopology)
+		}
+	}
+
+	// 关闭服务时停止所有实例的调度循环并写完状态历史，再等待告警通知投递完成
+	return func(ctx context.Context) {
+		instanceManager.Close()
+		system := algorithm.GetSystemInstance()
+		system.Stop()
+		system.History.Close()
+		tokenService.Close()
+		apiKeyService.Close()
+		if err := notificationService.Shutdown(ctx); err != nil {
+			log.Printf("⚠️  等待告警通知投递超时: %v", err)
+		}
+	}
+}
+
+// registerAlgorithmRoutes 注册算法管理路由 (默认实例与实例作用域共用)
+// 影响所有用户的操作 (停止、清除历史、调度循环控制) 需要scheduler:control权限或实例所有者，
+// 修改Lyapunov参数需要scheduler:config权限或实例所有者
+func registerAlgorithmRoutes(algorithm *gin.RouterGroup, algorithmHandler *handlers.AlgorithmHandler) {
+	privileged := algorithmHandler.RequirePrivileged()
+	systemWide := algorithmHandler.RequireSystemWide()
+	canConfig := algorithmHandler.RequireConfig()
+	canRead := middleware.RequirePermission(models.PermTasksRead)
+	canSubmit := middleware.RequirePermission(models.PermTasksSubmit)
+
+	algorithm.POST("/start", canSubmit, algorithmHandler.StartAlgorithm)
+	algorithm.POST("/stop", privileged, algorithmHandler.StopAlgorithm)
+	algorithm.GET("/info", canRead, algorithmHandler.GetSystemInfo)
+	algorithm.GET("/stream", canRead, algorithmHandler.StreamSlots)
+	algorithm.GET("/history", systemWide, algorithmHandler.GetHistory)
+	algorithm.POST("/clear", privileged, algorithmHandler.ClearHistory)
+	algorithm.GET("/tasks", canRead, algorithmHandler.GetTasks)
+	algorithm.POST("/tasks", canSubmit, algorithmHandler.SubmitTask)
+	algorithm.GET("/tasks/:id", canRead, algorithmHandler.GetTaskByID)
+	algorithm.DELETE("/tasks/:id", canSubmit, algorithmHandler.DeleteTask)
+	algorithm.GET("/quota", canRead, algorithmHandler.GetQuota)
+	algorithm.GET("/params", canRead, algorithmHandler.GetLyapunovParams)
+	algorithm.PUT("/params", canConfig, algorithmHandler.UpdateLyapunovParams)
+
+	// 调度循环控制
+	loop := algorithm.Group("/loop")
+	{
+		loop.GET("", canRead, algorithmHandler.GetLoopStatus)
+		loop.POST("/start", privileged, algorithmHandler.StartLoop)
+		loop.POST("/pause", privileged, algorithmHandler.PauseLoop)
+		loop.POST("/resume", privileged, algorithmHandler.ResumeLoop)
+		loop.POST("/step", privileged, algorithmHandler.StepLoop)
+		loop.PUT("/interval", privileged, algorithmHandler.SetLoopInterval)
+		loop.PUT("/speed", privileged, algorithmHandler.SetLoopSpeed)
+	}
+}
+
+// newInstanceManager 按配置创建仿真实例管理器
+func newInstanceManager(cfg *config.Config) *algorithm.InstanceManager {
+	idleTTL := time.Duration(-1) // 使用默认值
+	if cfg.Instances.IdleTTL != "" {
+		ttl, err := time.ParseDuration(cfg.Instances.IdleTTL)
+		if err != nil {
+			log.Printf("⚠️  无效的实例空闲回收时间 %q，使用默认值: %v", cfg.Instances.IdleTTL, err)
+		} else {
+			idleTTL = ttl
 		}
 	}
+	return algorithm.NewInstanceManager(cfg.Instances.MaxPerUser, cfg.Instances.MaxTotal, idleTTL)
+}
+
+// newRateLimiter 按配置创建限流器
+func newRateLimiter(cfg *config.Config) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(cfg.RateLimit.IP, cfg.RateLimit.User, cfg.RateLimit.Routes)
+}
+
+// newLoginGuard 按配置创建登录失败锁定策略
+func newLoginGuard(cfg *config.Config) *service.LoginGuard {
+	policy := service.DefaultLoginPolicy()
+	policy.MaxFailures = cfg.Login.MaxFailures
+	policy.MaxAccountFailures = cfg.Login.MaxAccountFailures
+	for _, item := range []struct {
+		value  string
+		target *time.Duration
+	}{{cfg.Login.Lockout, &policy.Lockout}, {cfg.Login.MaxLockout, &policy.MaxLockout}} {
+		if item.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(item.value)
+		if err != nil || d <= 0 {
+			log.Printf("⚠️  无效的登录锁定时长 %q，使用默认值", item.value)
+			continue
+		}
+		*item.target = d
+	}
+	if policy.MaxLockout < policy.Lockout {
+		policy.MaxLockout = policy.Lockout
+	}
+	return service.NewLoginGuard(policy)
+}
+
+func newAlarmFlapPolicy(cfg *config.Config) service.AlarmFlapPolicy {
+	policy := service.DefaultAlarmFlapPolicy()
+	if cfg.Alarms.FlapWindow != "" {
+		d, err := time.ParseDuration(cfg.Alarms.FlapWindow)
+		if err != nil || d <= 0 {
+			log.Printf("⚠️  无效的告警抖动窗口 %q，使用默认值 %s", cfg.Alarms.FlapWindow, policy.Window)
+		} else {
+			policy.Window = d
+		}
+	}
+	policy.Start = cfg.Alarms.FlapStart
+	policy.Stop = cfg.Alarms.FlapStop
+	if policy.Start > 0 && (policy.Stop <= 0 || policy.Stop >= policy.Start) {
+		log.Printf("⚠️  告警抖动退出阈值 %.1f 应在0和进入阈值 %.1f 之间，使用 %.1f", policy.Stop, policy.Start, policy.Start/2)
+		policy.Stop = policy.Start / 2
+	}
+	return policy
 }
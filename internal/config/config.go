@@ -1,15 +1,20 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 
+	"go-backend/pkg/notify"
+	"go-backend/pkg/ratelimit"
+
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	Port     string `yaml:"port"`
-	Database struct {
+	Port           string   `yaml:"port"`
+	TrustedProxies []string `yaml:"trusted_proxies"` // 受信任的反向代理 (IP或CIDR)，只有来自这些代理的X-Forwarded-For才用于确定客户端IP
+	Database       struct {
 		Host     string `yaml:"host"`
 		Port     string `yaml:"port"`
 		User     string `yaml:"user"`
@@ -17,13 +22,93 @@ type Config struct {
 		Name     string `yaml:"name"`
 	} `yaml:"database"`
 	JWT struct {
-		Secret     string `yaml:"secret"`
-		Expiration string `yaml:"expiration"`
+		Secret            string `yaml:"secret"`
+		Expiration        string `yaml:"expiration"`         // 访问令牌有效期
+		RefreshExpiration string `yaml:"refresh_expiration"` // 刷新令牌有效期
 	} `yaml:"jwt"`
+	Lyapunov LyapunovConfig `yaml:"lyapunov"`
+	History  struct {
+		Capacity int  `yaml:"capacity"` // 内存中保留的时隙数
+		Persist  bool `yaml:"persist"`  // 是否持久化到SQLite
+	} `yaml:"history"`
+	Metrics struct {
+		Enabled bool `yaml:"enabled"` // 是否导出Prometheus指标 (/metrics)
+		Public  bool `yaml:"public"`  // 无需认证即可抓取 (仅限受信任的网络)，否则需要metrics:read权限 (可使用API密钥)
+	} `yaml:"metrics"`
+	Snapshot struct {
+		Dir string `yaml:"dir"` // 快照文件目录
+	} `yaml:"snapshot"`
+	Instances struct {
+		MaxPerUser int    `yaml:"max_per_user"` // 每个用户可创建的仿真实例数
+		MaxTotal   int    `yaml:"max_total"`    // 仿真实例总数上限
+		IdleTTL    string `yaml:"idle_ttl"`     // 空闲超过该时长自动回收 (0表示不回收)
+	} `yaml:"instances"`
+	Simulation struct {
+		SpeedUp float64 `yaml:"speed_up"` // 仿真加速比: 时隙间隔(真实时间) = 时隙长度 / speed_up
+	} `yaml:"simulation"`
+	Quota struct {
+		MaxActiveTasks int     `yaml:"max_active_tasks"` // 未结束任务数上限 (0表示不限制)
+		MaxActiveData  float64 `yaml:"max_active_data"`  // 未结束任务的数据总量上限 (0表示不限制)
+	} `yaml:"quota"` // 每个账号的任务配额 (管理员不受限制)
+	PasswordPolicy struct {
+		MinLength     int  `yaml:"min_length"`     // 最小长度
+		RequireLetter bool `yaml:"require_letter"` // 必须包含字母
+		RequireDigit  bool `yaml:"require_digit"`  // 必须包含数字
+		RequireSymbol bool `yaml:"require_symbol"` // 必须包含特殊字符
+	} `yaml:"password_policy"`
+	RateLimit struct {
+		Enabled bool                       `yaml:"enabled"`
+		IP      ratelimit.Limit            `yaml:"ip"`     // 每个IP的所有请求
+		User    ratelimit.Limit            `yaml:"user"`   // 每个用户或API密钥的请求 (认证后)
+		Routes  map[string]ratelimit.Limit `yaml:"routes"` // 按接口限制每个客户端，键为 "方法 路由模板"
+	} `yaml:"rate_limit"`
+	Login struct {
+		MaxFailures        int    `yaml:"max_failures"`         // 同一IP连续登录失败多少次后锁定该IP对账号的登录 (0表示不锁定)
+		MaxAccountFailures int    `yaml:"max_account_failures"` // 所有IP合计连续登录失败多少次后锁定账号 (0表示不锁定)
+		Lockout            string `yaml:"lockout"`              // 首次锁定时长 (之后每次翻倍)
+		MaxLockout         string `yaml:"max_lockout"`          // 最长锁定时长
+	} `yaml:"login"`
+	Alarms struct {
+		FlapWindow string  `yaml:"flap_window"` // 抖动检测窗口: 状态变化次数按该时长衰减，窗口内解决的告警再次触发时重新打开原告警
+		FlapStart  float64 `yaml:"flap_start"`  // 状态变化次数达到该值时进入抖动状态 (0表示不检测)
+		FlapStop   float64 `yaml:"flap_stop"`   // 抖动中的状态变化次数降到该值以下时退出
+	} `yaml:"alarms"`
+	Notifications struct {
+		Channels []notify.ChannelConfig `yaml:"channels"` // 告警通知渠道 (webhook/smtp/file)
+	} `yaml:"notifications"`
+}
+
+// LyapunovConfig Lyapunov调度参数，未配置的字段为nil，保持算法默认值
+type LyapunovConfig struct {
+	V                *float64 `yaml:"v"`
+	Alpha            *float64 `yaml:"alpha"`
+	Beta             *float64 `yaml:"beta"`
+	Gamma            *float64 `yaml:"gamma"`
+	Shrink           *float64 `yaml:"shrink"`
+	Iters            *int     `yaml:"iters"`
+	Solver           *string  `yaml:"solver"`
+	CommEnergyBudget *float64 `yaml:"comm_energy_budget"`
+	UserEnergyBudget *float64 `yaml:"user_energy_budget"`
+	EnergyWeight     *float64 `yaml:"energy_weight"`
 }
 
 func LoadConfig(filePath string) (*Config, error) {
 	config := &Config{}
+	config.JWT.Expiration = "24h"
+	config.JWT.RefreshExpiration = "168h"
+	config.Simulation.SpeedUp = 1
+	config.PasswordPolicy.MinLength = 8
+	config.PasswordPolicy.RequireLetter = true
+	config.PasswordPolicy.RequireDigit = true
+	config.Snapshot.Dir = "./snapshots"
+	config.Metrics.Enabled = true
+	config.Login.MaxFailures = 5
+	config.Login.MaxAccountFailures = 20
+	config.Login.Lockout = "30s"
+	config.Login.MaxLockout = "1h"
+	config.Alarms.FlapWindow = "10m"
+	config.Alarms.FlapStart = 4
+	config.Alarms.FlapStop = 2
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -35,6 +120,10 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, err
 	}
 
+	if config.PasswordPolicy.MinLength < 1 || config.PasswordPolicy.MinLength > 72 {
+		return nil, fmt.Errorf("密码策略配置错误: 最小长度必须在1~72之间")
+	}
+
 	return config, nil
 }
 
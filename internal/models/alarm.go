@@ -8,10 +8,23 @@ import (
 type AlarmStatus string
 
 const (
-	AlarmStatusActive   AlarmStatus = "pending"  // 活跃状态
-	AlarmStatusResolved AlarmStatus = "resolved" // 已解决
+	AlarmStatusActive       AlarmStatus = "pending"      // 活跃状态
+	AlarmStatusAcknowledged AlarmStatus = "acknowledged" // 已确认 (有人处理中)
+	AlarmStatusResolved     AlarmStatus = "resolved"     // 已解决
 )
 
+// AlarmSeverity 告警级别
+type AlarmSeverity string
+
+const (
+	AlarmSeverityInfo     AlarmSeverity = "info"     // 提示
+	AlarmSeverityWarning  AlarmSeverity = "warning"  // 警告
+	AlarmSeverityCritical AlarmSeverity = "critical" // 严重
+)
+
+// AlarmSeverities 所有告警级别 (按严重程度从低到高)
+var AlarmSeverities = []AlarmSeverity{AlarmSeverityInfo, AlarmSeverityWarning, AlarmSeverityCritical}
+
 // AlarmEvent 事件类型枚举
 type AlarmEvent string
 
@@ -25,12 +38,63 @@ const (
 
 // Alarm 告警数据模型
 type Alarm struct {
-	ID          uint        `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
-	Name        string      `json:"name" gorm:"not null;size:255" validate:"required,min=1,max=255" example:"网络连接超时"`
-	EventType   AlarmEvent  `json:"event_type" gorm:"not null;size:50" validate:"required" example:"network"`
-	Status      AlarmStatus `json:"status" gorm:"not null;size:20;default:'active'" validate:"required" example:"active"`
-	Description string      `json:"description" gorm:"type:text" validate:"max=1000" example:"设备与基站之间的网络连接超时，可能影响通信质量"`
-	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
-	ResolvedAt  *time.Time  `json:"resolved_at,omitempty"`
+	ID             uint          `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	Name           string        `json:"name" gorm:"not null;size:255" validate:"required,min=1,max=255" example:"网络连接超时"`
+	EventType      AlarmEvent    `json:"event_type" gorm:"not null;size:50" validate:"required" example:"network"`
+	Severity       AlarmSeverity `json:"severity" gorm:"not null;size:20;default:'warning';index" example:"critical"`
+	Status         AlarmStatus   `json:"status" gorm:"not null;size:20;default:'active'" validate:"required" example:"active"`
+	Description    string        `json:"description" gorm:"type:text" validate:"max=1000" example:"设备与基站之间的网络连接超时，可能影响通信质量"`
+	AssigneeID     *uint         `json:"assignee_id,omitempty" gorm:"index"`          // 指派的处理人
+	AcknowledgedBy *uint         `json:"acknowledged_by,omitempty"`                   // 确认人
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`                   // 确认时间
+	Fingerprint    string        `json:"fingerprint,omitempty" gorm:"size:128;index"` // 告警指纹 (监控器产生的告警，如 rule_3_2；手动创建的告警为空)
+	Target         string        `json:"target,omitempty" gorm:"size:64"`             // 告警对象ID (通信设备/用户节点/任务)，系统级告警为空
+	SilenceID      *uint         `json:"silence_id,omitempty" gorm:"index"`           // 匹配的静默规则 (告警照常记录，不发送通知)
+	Occurrences    int           `json:"occurrences" gorm:"not null;default:1"`       // 发生次数 (条件恢复后再次触发时累加，不产生新告警)
+	LastSeenAt     *time.Time    `json:"last_seen_at,omitempty"`                      // 最后一次检测到告警条件的时间
+	Firing         bool          `json:"firing" gorm:"not null;default:false"`        // 告警条件当前是否满足
+	Flapping       bool          `json:"flapping" gorm:"not null;default:false"`      // 是否处于抖动状态 (抑制通知和自动解决)
+	FlapScore      float64       `json:"flap_score"`                                  // 按时间衰减的状态变化次数
+	LastChangeAt   *time.Time    `json:"last_change_at,omitempty"`                    // 告警条件最后一次变化 (触发/恢复) 的时间
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+}
+
+// AlarmNoteKind 告警处理记录类型
+type AlarmNoteKind string
+
+const (
+	AlarmNoteComment     AlarmNoteKind = "comment"     // 评论
+	AlarmNoteAcknowledge AlarmNoteKind = "acknowledge" // 确认
+	AlarmNoteAssign      AlarmNoteKind = "assign"      // 指派
+	AlarmNoteResolve     AlarmNoteKind = "resolve"     // 解决
+	AlarmNoteReactivate  AlarmNoteKind = "reactivate"  // 重新激活
+	AlarmNoteRecur       AlarmNoteKind = "recur"       // 条件恢复后再次触发
+	AlarmNoteFlapping    AlarmNoteKind = "flapping"    // 进入或退出抖动状态
+)
+
+// AlarmNote 告警处理记录 (评论和状态变更历史)
+type AlarmNote struct {
+	ID        uint          `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time     `json:"created_at"`
+	AlarmID   uint          `json:"alarm_id" gorm:"index;not null"`
+	UserID    uint          `json:"user_id"`                      // 0表示系统自动操作
+	Username  string        `json:"username" gorm:"size:50"`      // 操作人用户名
+	Kind      AlarmNoteKind `json:"kind" gorm:"size:20;not null"` // 记录类型
+	Content   string        `json:"content" gorm:"type:text"`
+}
+
+// AlarmDelivery 告警通知投递记录 (每个通知渠道一条，包含重试次数和最后的错误)
+type AlarmDelivery struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+	AlarmID     uint      `json:"alarm_id" gorm:"index"` // 0表示测试通知
+	Channel     string    `json:"channel" gorm:"size:50;index"`
+	ChannelType string    `json:"channel_type" gorm:"size:20"`
+	Event       string    `json:"event" gorm:"size:30"` // alarm.created / alarm.resolved / test
+	Success     bool      `json:"success"`
+	Attempts    int       `json:"attempts"` // 发送次数 (含重试)
+	Error       string    `json:"error,omitempty" gorm:"type:text"`
+	Duration    int64     `json:"duration_ms"` // 从首次发送到完成的耗时 (毫秒，含重试间隔)
 }
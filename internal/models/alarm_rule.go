@@ -0,0 +1,154 @@
+package models
+
+import (
+	"time"
+)
+
+// AlarmComparator 告警规则比较运算符
+type AlarmComparator string
+
+const (
+	ComparatorGT  AlarmComparator = "gt"  // 大于
+	ComparatorGTE AlarmComparator = "gte" // 大于等于
+	ComparatorLT  AlarmComparator = "lt"  // 小于
+	ComparatorLTE AlarmComparator = "lte" // 小于等于
+	ComparatorEQ  AlarmComparator = "eq"  // 等于
+	ComparatorNE  AlarmComparator = "ne"  // 不等于
+)
+
+// Symbol 运算符的显示符号 (用于告警描述)
+func (c AlarmComparator) Symbol() string {
+	switch c {
+	case ComparatorGT:
+		return ">"
+	case ComparatorGTE:
+		return ">="
+	case ComparatorLT:
+		return "<"
+	case ComparatorLTE:
+		return "<="
+	case ComparatorEQ:
+		return "="
+	case ComparatorNE:
+		return "!="
+	}
+	return string(c)
+}
+
+// Compare 判断 value 与 threshold 是否满足比较条件，无效运算符返回false
+func (c AlarmComparator) Compare(value, threshold float64) bool {
+	switch c {
+	case ComparatorGT:
+		return value > threshold
+	case ComparatorGTE:
+		return value >= threshold
+	case ComparatorLT:
+		return value < threshold
+	case ComparatorLTE:
+		return value <= threshold
+	case ComparatorEQ:
+		return value == threshold
+	case ComparatorNE:
+		return value != threshold
+	}
+	return false
+}
+
+// AlarmMetricScope 指标的作用对象
+type AlarmMetricScope string
+
+const (
+	MetricScopeSystem AlarmMetricScope = "system" // 系统整体 (每时隙一个值)
+	MetricScopeComm   AlarmMetricScope = "comm"   // 每个通信设备 (无人机)
+	MetricScopeUser   AlarmMetricScope = "user"   // 每个用户设备
+	MetricScopeTask   AlarmMetricScope = "task"   // 每个未完成的任务
+)
+
+// 告警规则可选的指标
+const (
+	MetricTotalDelay      = "total_delay"       // 系统总延迟 (秒)
+	MetricTransferDelay   = "transfer_delay"    // 传输延迟 (秒)
+	MetricComputeDelay    = "compute_delay"     // 计算延迟 (秒)
+	MetricTotalEnergy     = "total_energy"      // 系统总能耗 (焦耳)
+	MetricTransferEnergy  = "transfer_energy"   // 传输能耗 (焦耳)
+	MetricComputeEnergy   = "compute_energy"    // 计算能耗 (焦耳)
+	MetricLoad            = "load"              // 系统负载
+	MetricTotalQueue      = "total_queue"       // 总队列长度 (bits)
+	MetricCost            = "cost"              // 总成本
+	MetricDrift           = "drift"             // Lyapunov漂移值
+	MetricPenalty         = "penalty"           // Lyapunov惩罚项
+	MetricCommQueue       = "comm_queue"        // 通信设备队列长度 (bits)
+	MetricBatteryLevel    = "battery_level"     // 无人机剩余电量比例 (0~1)
+	MetricCommEnergyQueue = "comm_energy_queue" // 通信设备能耗虚拟队列 (焦耳)
+	MetricUserEnergyQueue = "user_energy_queue" // 用户设备能耗虚拟队列 (焦耳)
+	MetricTaskWaitSlots   = "task_wait_slots"   // 任务已等待的时隙数
+	MetricTaskDataSize    = "task_data_size"    // 任务数据大小
+)
+
+// AlarmMetricInfo 指标说明
+type AlarmMetricInfo struct {
+	Name        string           `json:"name"`
+	Scope       AlarmMetricScope `json:"scope"`
+	Description string           `json:"description"`
+}
+
+// AlarmMetrics 告警规则可选的所有指标
+var AlarmMetrics = []AlarmMetricInfo{
+	{MetricTotalDelay, MetricScopeSystem, "系统总延迟 (秒)"},
+	{MetricTransferDelay, MetricScopeSystem, "传输延迟 (秒)"},
+	{MetricComputeDelay, MetricScopeSystem, "计算延迟 (秒)"},
+	{MetricTotalEnergy, MetricScopeSystem, "系统总能耗 (焦耳)"},
+	{MetricTransferEnergy, MetricScopeSystem, "传输能耗 (焦耳)"},
+	{MetricComputeEnergy, MetricScopeSystem, "计算能耗 (焦耳)"},
+	{MetricLoad, MetricScopeSystem, "系统负载"},
+	{MetricTotalQueue, MetricScopeSystem, "总队列长度 (bits)"},
+	{MetricCost, MetricScopeSystem, "总成本"},
+	{MetricDrift, MetricScopeSystem, "Lyapunov漂移值"},
+	{MetricPenalty, MetricScopeSystem, "Lyapunov惩罚项"},
+	{MetricCommQueue, MetricScopeComm, "通信设备队列长度 (bits)"},
+	{MetricBatteryLevel, MetricScopeComm, "无人机剩余电量比例 (0~1)"},
+	{MetricCommEnergyQueue, MetricScopeComm, "通信设备能耗虚拟队列 (焦耳)"},
+	{MetricUserEnergyQueue, MetricScopeUser, "用户设备能耗虚拟队列 (焦耳)"},
+	{MetricTaskWaitSlots, MetricScopeTask, "任务已等待的时隙数 (未完成的任务)"},
+	{MetricTaskDataSize, MetricScopeTask, "任务数据大小 (未完成的任务)"},
+}
+
+// FindAlarmMetric 根据名称查找指标
+func FindAlarmMetric(name string) (AlarmMetricInfo, bool) {
+	for _, m := range AlarmMetrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return AlarmMetricInfo{}, false
+}
+
+// AlarmRule 告警规则
+// 每个时隙对指标求值，连续 ForSlots 个时隙满足条件时产生告警，条件不再满足时自动解决
+type AlarmRule struct {
+	ID          uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Name        string          `json:"name" gorm:"uniqueIndex;not null;size:100" example:"系统延迟过高"`
+	Description string          `json:"description" gorm:"size:500"`
+	Metric      string          `json:"metric" gorm:"not null;size:50" example:"total_delay"`
+	Target      string          `json:"target" gorm:"size:100"` // 对象ID (通信设备/用户设备/任务)，为空匹配所有对象
+	Comparator  AlarmComparator `json:"comparator" gorm:"not null;size:10" example:"gt"`
+	Threshold   float64         `json:"threshold" example:"10"`
+	ForSlots    int             `json:"for_slots" gorm:"not null;default:1" example:"1"` // 连续满足条件的时隙数
+	Severity    AlarmSeverity   `json:"severity" gorm:"not null;size:20" example:"warning"`
+	EventType   AlarmEvent      `json:"event_type" gorm:"not null;size:50" example:"performance"`
+	Enabled     bool            `json:"enabled" gorm:"not null"`
+}
+
+// DefaultAlarmRules 默认告警规则 (首次启动且规则表为空时创建)
+func DefaultAlarmRules() []AlarmRule {
+	return []AlarmRule{
+		{Name: "系统延迟过高", Description: "系统总延迟超过10秒", Metric: MetricTotalDelay, Comparator: ComparatorGT, Threshold: 10, ForSlots: 1, Severity: AlarmSeverityWarning, EventType: AlarmEventPerformance, Enabled: true},
+		{Name: "系统能耗过高", Description: "系统总能耗超过100焦耳", Metric: MetricTotalEnergy, Comparator: ComparatorGT, Threshold: 100, ForSlots: 1, Severity: AlarmSeverityWarning, EventType: AlarmEventPerformance, Enabled: true},
+		{Name: "系统负载过高", Description: "系统负载超过5倍，可能导致任务处理缓慢", Metric: MetricLoad, Comparator: ComparatorGT, Threshold: 5, ForSlots: 1, Severity: AlarmSeverityWarning, EventType: AlarmEventPerformance, Enabled: true},
+		{Name: "网络队列积压严重", Description: "总队列数据量超过100MB，通信设备处理能力不足", Metric: MetricTotalQueue, Comparator: ComparatorGT, Threshold: 1e8, ForSlots: 1, Severity: AlarmSeverityCritical, EventType: AlarmEventNetwork, Enabled: true},
+		{Name: "通信设备队列积压", Description: "单个通信设备队列数据量超过50MB，可能存在传输瓶颈", Metric: MetricCommQueue, Comparator: ComparatorGT, Threshold: 5e7, ForSlots: 1, Severity: AlarmSeverityWarning, EventType: AlarmEventNetwork, Enabled: true},
+		{Name: "无人机电量不足", Description: "无人机剩余电量低于20%", Metric: MetricBatteryLevel, Comparator: ComparatorLT, Threshold: 0.2, ForSlots: 1, Severity: AlarmSeverityCritical, EventType: AlarmEventHardware, Enabled: true},
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"path"
+	"time"
+)
+
+// AlarmSilenceAction 静默期间对匹配告警的处理方式
+type AlarmSilenceAction string
+
+const (
+	AlarmSilenceSuppress AlarmSilenceAction = "suppress" // 不产生告警
+	AlarmSilenceMark     AlarmSilenceAction = "mark"     // 照常产生告警并标记为静默，不发送通知
+)
+
+// AlarmSilenceRepeat 维护窗口的重复周期
+type AlarmSilenceRepeat string
+
+const (
+	AlarmSilenceOnce   AlarmSilenceRepeat = ""       // 不重复 (一次性静默)
+	AlarmSilenceDaily  AlarmSilenceRepeat = "daily"  // 每天
+	AlarmSilenceWeekly AlarmSilenceRepeat = "weekly" // 每周
+)
+
+// Days 重复周期的天数 (不重复为0)
+func (r AlarmSilenceRepeat) Days() int {
+	switch r {
+	case AlarmSilenceDaily:
+		return 1
+	case AlarmSilenceWeekly:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// AlarmSilence 告警静默规则
+// 在 [StartsAt, EndsAt) 期间匹配的告警被抑制或标记为静默；设置 Repeat 时按周期重复该时间段，用作定期维护窗口
+type AlarmSilence struct {
+	ID          uint               `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Name        string             `json:"name" gorm:"size:100;not null" example:"拓扑调整"`
+	Comment     string             `json:"comment" gorm:"type:text" example:"计划内的通信设备迁移"`
+	EventType   AlarmEvent         `json:"event_type" gorm:"size:50" example:"performance"`              // 匹配的事件类型，为空匹配所有
+	NamePattern string             `json:"name_pattern" gorm:"size:255" example:"通信设备队列积压*"`             // 告警名称通配符 (* 和 ?)，为空匹配所有
+	Target      string             `json:"target" gorm:"size:64" example:"2"`                            // 匹配的对象ID (通信设备/用户节点/任务)，为空匹配所有
+	Action      AlarmSilenceAction `json:"action" gorm:"size:20;not null;default:'mark'" example:"mark"` // suppress/mark
+	StartsAt    time.Time          `json:"starts_at" gorm:"not null"`                                    // 开始时间 (重复时为第一个窗口的开始)
+	EndsAt      time.Time          `json:"ends_at" gorm:"not null"`                                      // 结束时间 (重复时为第一个窗口的结束)
+	Repeat      AlarmSilenceRepeat `json:"repeat" gorm:"size:20" example:"weekly"`                       // 重复周期: 空/daily/weekly
+	RepeatUntil *time.Time         `json:"repeat_until,omitempty"`                                       // 重复截止时间，为空表示一直重复
+	CreatedBy   uint               `json:"created_by"`                                                   // 创建人用户ID
+}
+
+// Matches 告警是否匹配静默规则的条件 (不考虑时间)
+func (s *AlarmSilence) Matches(eventType AlarmEvent, name, target string) bool {
+	if s.EventType != "" && s.EventType != eventType {
+		return false
+	}
+	if s.Target != "" && s.Target != target {
+		return false
+	}
+	if s.NamePattern != "" {
+		if ok, err := path.Match(s.NamePattern, name); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Window 返回 t 时刻所在或之前最近的静默时间段，以及 t 是否处于该时间段内
+func (s *AlarmSilence) Window(t time.Time) (time.Time, time.Time, bool) {
+	days := s.Repeat.Days()
+	if days == 0 || t.Before(s.StartsAt) {
+		return s.StartsAt, s.EndsAt, !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+	}
+
+	// 先按固定周期估算，再按日历修正 (夏令时切换时一天不是24小时)
+	n := int(t.Sub(s.StartsAt) / (time.Duration(days) * 24 * time.Hour))
+	start := s.StartsAt.AddDate(0, 0, n*days)
+	for start.After(t) {
+		n--
+		start = s.StartsAt.AddDate(0, 0, n*days)
+	}
+	if next := s.StartsAt.AddDate(0, 0, (n+1)*days); !next.After(t) {
+		start = next
+	}
+	if s.RepeatUntil != nil && start.After(*s.RepeatUntil) {
+		return start, start, false
+	}
+	end := start.Add(s.EndsAt.Sub(s.StartsAt))
+	return start, end, t.Before(end)
+}
+
+// NextStart 返回 t 之后下一个静默时间段的开始时间 (没有时返回nil)
+func (s *AlarmSilence) NextStart(t time.Time) *time.Time {
+	if t.Before(s.StartsAt) {
+		start := s.StartsAt
+		return &start
+	}
+	days := s.Repeat.Days()
+	if days == 0 {
+		return nil
+	}
+	start, _, _ := s.Window(t)
+	next := start.AddDate(0, 0, days)
+	if s.RepeatUntil != nil && next.After(*s.RepeatUntil) {
+		return nil
+	}
+	return &next
+}
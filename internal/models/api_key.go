@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// APIKey 机器客户端使用的长期API密钥 (只保存哈希，明文仅在创建时返回一次)
+// swagger:model
+type APIKey struct {
+	ID           uint       `json:"id" gorm:"primarykey,autoIncrement"`         // 密钥ID
+	CreatedAt    time.Time  `json:"created_at"`                                 // 创建时间
+	Name         string     `json:"name" gorm:"size:100;not null"`              // 密钥名称
+	Prefix       string     `json:"prefix" gorm:"size:20;not null;uniqueIndex"` // 密钥前缀 (用于查找和识别，不是秘密)
+	KeyHash      string     `json:"-" gorm:"size:64;not null"`                  // 密钥SHA-256哈希
+	UserID       uint       `json:"user_id" gorm:"index;not null"`              // 所属用户 (以该用户身份访问)
+	Permissions  StringList `json:"permissions" gorm:"type:json"`               // 权限列表 (不超过所属用户角色的权限)
+	ExpiresAt    *time.Time `json:"expires_at"`                                 // 过期时间 (为空表示永不过期)
+	RevokedAt    *time.Time `json:"revoked_at"`                                 // 吊销时间
+	LastUsedAt   *time.Time `json:"last_used_at"`                               // 最后使用时间
+	RequestCount int64      `json:"request_count" gorm:"not null;default:0"`    // 累计请求次数
+}
+
+// IsActive 密钥在指定时间是否有效 (未吊销且未过期)
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasPermission 密钥是否包含指定权限
+func (k *APIKey) HasPermission(perm string) bool {
+	for _, p := range k.Permissions {
+		if p == PermAll || p == perm {
+			return true
+		}
+	}
+	return false
+}
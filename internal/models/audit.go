@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog 状态变更操作的审计记录
+// swagger:model
+type AuditLog struct {
+	ID         uint            `json:"id" gorm:"primarykey,autoIncrement"` // 记录ID
+	CreatedAt  time.Time       `json:"created_at" gorm:"index"`            // 操作时间
+	UserID     uint            `json:"user_id" gorm:"index"`               // 操作用户ID (未认证时为0)
+	Username   string          `json:"username" gorm:"size:50"`            // 操作用户名
+	APIKeyID   *uint           `json:"api_key_id"`                         // 使用的API密钥 (JWT认证时为空)
+	Method     string          `json:"method" gorm:"size:10;index"`        // HTTP方法
+	Route      string          `json:"route" gorm:"size:200;index"`        // 路由模板 (如 /api/v1/network/nodes/:id)
+	Path       string          `json:"path" gorm:"size:500"`               // 实际请求路径
+	TargetID   string          `json:"target_id" gorm:"size:100;index"`    // 目标实体ID (路径参数或创建结果的ID)
+	Changes    json.RawMessage `json:"changes,omitempty" gorm:"type:json"` // 变更前后的字段差异 {字段: {before, after}}
+	Success    bool            `json:"success" gorm:"index"`               // 是否成功
+	StatusCode int             `json:"status_code"`                        // HTTP状态码
+	Message    string          `json:"message" gorm:"size:500"`            // 响应消息 (失败原因)
+	ClientIP   string          `json:"client_ip" gorm:"size:50"`           // 客户端IP
+	Duration   int64           `json:"duration_ms"`                        // 处理耗时 (毫秒)
+}
+
+// FieldChange 字段变更前后的值
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
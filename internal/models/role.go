@@ -0,0 +1,144 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 权限 (资源:操作)
+const (
+	PermAll              = "*"                 // 所有权限
+	PermTasksRead        = "tasks:read"        // 查看系统状态和自己的任务
+	PermTasksSubmit      = "tasks:submit"      // 提交和取消自己的任务
+	PermTasksManage      = "tasks:manage"      // 查看和取消所有用户的任务，不受配额限制
+	PermSchedulerControl = "scheduler:control" // 停止调度、清除历史、调度循环控制
+	PermSchedulerConfig  = "scheduler:config"  // 修改Lyapunov参数和任务配额
+	PermSnapshotsManage  = "snapshots:manage"  // 保存、下载和恢复系统快照
+	PermInstancesCreate  = "instances:create"  // 创建和使用自己的仿真实例
+	PermInstancesManage  = "instances:manage"  // 访问和删除所有仿真实例
+	PermTopologyRead     = "topology:read"     // 查看网络拓扑
+	PermTopologyWrite    = "topology:write"    // 修改网络拓扑 (节点、链路)
+	PermDevicesRead      = "devices:read"      // 查看自己的设备
+	PermDevicesWrite     = "devices:write"     // 创建、修改和删除自己的设备
+	PermDevicesManage    = "devices:manage"    // 管理所有用户的设备
+	PermAlarmsRead       = "alarms:read"       // 查看告警
+	PermAlarmsResolve    = "alarms:resolve"    // 处理告警 (确认、指派、评论、解决和重新激活)
+	PermAlarmsDelete     = "alarms:delete"     // 删除告警
+	PermAlarmsRules      = "alarms:rules"      // 管理告警规则，查看和测试通知渠道
+	PermAlarmsSilence    = "alarms:silence"    // 管理告警静默和维护窗口
+	PermUsersManage      = "users:manage"      // 管理用户账号
+	PermRolesManage      = "roles:manage"      // 管理角色和权限
+	PermAPIKeysManage    = "api_keys:manage"   // 查看和吊销所有用户的API密钥
+	PermAuditRead        = "audit:read"        // 查看审计日志
+	PermMetricsRead      = "metrics:read"      // 抓取Prometheus监控指标
+)
+
+// PermissionInfo 权限说明
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AllPermissions 所有可分配的权限
+var AllPermissions = []PermissionInfo{
+	{PermTasksRead, "查看系统状态和自己的任务"},
+	{PermTasksSubmit, "提交和取消自己的任务"},
+	{PermTasksManage, "查看和取消所有用户的任务，不受配额限制"},
+	{PermSchedulerControl, "停止调度、清除历史、调度循环控制"},
+	{PermSchedulerConfig, "修改Lyapunov参数和任务配额"},
+	{PermSnapshotsManage, "保存、下载和恢复系统快照"},
+	{PermInstancesCreate, "创建和使用自己的仿真实例"},
+	{PermInstancesManage, "访问和删除所有仿真实例"},
+	{PermTopologyRead, "查看网络拓扑"},
+	{PermTopologyWrite, "修改网络拓扑 (节点、链路)"},
+	{PermDevicesRead, "查看自己的设备"},
+	{PermDevicesWrite, "创建、修改和删除自己的设备"},
+	{PermDevicesManage, "管理所有用户的设备"},
+	{PermAlarmsRead, "查看告警"},
+	{PermAlarmsResolve, "处理告警 (确认、指派、评论、解决和重新激活)"},
+	{PermAlarmsDelete, "删除告警"},
+	{PermAlarmsRules, "管理告警规则，查看和测试通知渠道"},
+	{PermAlarmsSilence, "管理告警静默和维护窗口"},
+	{PermUsersManage, "管理用户账号"},
+	{PermRolesManage, "管理角色和权限"},
+	{PermAPIKeysManage, "查看和吊销所有用户的API密钥"},
+	{PermAuditRead, "查看审计日志"},
+	{PermMetricsRead, "抓取Prometheus监控指标"},
+}
+
+// IsValidPermission 检查权限名称是否有效
+func IsValidPermission(name string) bool {
+	if name == PermAll {
+		return true
+	}
+	for _, p := range AllPermissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// StringList 字符串列表 (以JSON格式存储)
+type StringList []string
+
+// Value 实现 driver.Valuer 接口
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	}
+	return errors.New("Invalid scan source")
+}
+
+// Role 角色，由一组权限组成
+// swagger:model
+type Role struct {
+	ID          uint       `json:"id" gorm:"primarykey,autoIncrement"`       // 角色ID
+	CreatedAt   time.Time  `json:"created_at"`                               // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at"`                               // 更新时间
+	Name        UserRole   `json:"name" gorm:"size:50;not null;uniqueIndex"` // 角色名称 (用户的role字段)
+	Description string     `json:"description" gorm:"size:200"`              // 角色描述
+	Permissions StringList `json:"permissions" gorm:"type:json"`             // 权限列表
+	BuiltIn     bool       `json:"built_in"`                                 // 内置角色 (不可删除)
+}
+
+// BuiltInRoles 内置角色 (首次启动时创建)
+func BuiltInRoles() []Role {
+	return []Role{
+		{
+			Name:        RoleAdmin,
+			Description: "管理员，拥有所有权限",
+			Permissions: StringList{PermAll},
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleOperator,
+			Description: "运维人员，可处理和静默告警、查看拓扑和监控指标，不能修改拓扑",
+			Permissions: StringList{PermTasksRead, PermTopologyRead, PermDevicesRead, PermAlarmsRead, PermAlarmsResolve, PermAlarmsSilence, PermMetricsRead},
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleUser,
+			Description: "普通用户，可为自己的设备提交任务",
+			Permissions: StringList{PermTasksRead, PermTasksSubmit, PermInstancesCreate, PermTopologyRead, PermDevicesRead, PermDevicesWrite, PermAlarmsRead},
+			BuiltIn:     true,
+		},
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// StateRecord 时隙状态指标持久化记录
+type StateRecord struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TimeSlot  uint      `json:"time_slot" gorm:"not null;index"`
+	Timestamp time.Time `json:"timestamp" gorm:"not null;index"`
+	Data      string    `json:"data" gorm:"type:text"` // StateMetrics的JSON序列化
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// RevocationKind 令牌吊销类型
+type RevocationKind string
+
+const (
+	RevokeToken  RevocationKind = "token"  // 单个令牌 (按JTI)
+	RevokeFamily RevocationKind = "family" // 同一会话签发的所有令牌 (退出登录、刷新令牌重放)
+)
+
+// 吊销原因 (退出所有设备通过递增用户的令牌版本实现，不产生吊销记录)
+const (
+	RevokeReasonRotated = "rotated" // 刷新令牌已轮换 (单次使用)
+	RevokeReasonLogout  = "logout"  // 退出登录
+	RevokeReasonReused  = "reused"  // 检测到已轮换的刷新令牌被重复使用
+)
+
+// TokenRevocation 令牌吊销记录
+// swagger:model
+type TokenRevocation struct {
+	ID        uint           `json:"id" gorm:"primarykey,autoIncrement"`                       // 记录ID
+	CreatedAt time.Time      `json:"created_at"`                                               // 吊销时间
+	Kind      RevocationKind `json:"kind" gorm:"size:20;not null;uniqueIndex:idx_revocation"`  // 吊销类型
+	Value     string         `json:"value" gorm:"size:64;not null;uniqueIndex:idx_revocation"` // JTI / 会话ID
+	UserID    uint           `json:"user_id" gorm:"index"`                                     // 令牌所属用户
+	Reason    string         `json:"reason" gorm:"size:50"`                                    // 吊销原因
+	ExpiresAt time.Time      `json:"expires_at" gorm:"index"`                                  // 被吊销令牌的最晚过期时间 (之后可清理)
+}
@@ -10,8 +10,17 @@ import (
 type UserRole string
 
 const (
-	RoleAdmin UserRole = "admin" // 管理员
-	RoleUser  UserRole = "user"  // 普通用户
+	RoleAdmin    UserRole = "admin"    // 管理员
+	RoleOperator UserRole = "operator" // 运维人员
+	RoleUser     UserRole = "user"     // 普通用户
+)
+
+// UserStatus 定义用户状态
+type UserStatus string
+
+const (
+	UserStatusActive   UserStatus = "active"   // 正常
+	UserStatusDisabled UserStatus = "disabled" // 已禁用 (不能登录，已签发的令牌被吊销)
 )
 
 // User 表示系统用户
@@ -23,8 +32,17 @@ type User struct {
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`             // 删除时间
 	Username  string     `json:"username" gorm:"size:100;not null;uniqueIndex"` // 用户名
 	Email     string     `json:"email" gorm:"size:100;not null;uniqueIndex"`    // 电子邮件
-	Password  string     `json:"password" gorm:"size:100;not null"`             // 密码（JSON序列化时不返回）
-	Role      UserRole   `json:"role" gorm:"size:20;default:user"`              // 用户角色
+	Password  string     `json:"-" gorm:"size:100;not null"`                    // 密码哈希（JSON序列化时不返回）
+	Role      UserRole   `json:"role" gorm:"size:20;default:user"`              // 用户角色 (对应roles表的角色名称)
+	Status    UserStatus `json:"status" gorm:"size:20;default:active"`          // 用户状态
+
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"` // 最近修改密码时间
+	TokenVersion      uint       `json:"-" gorm:"not null;default:0"`   // 令牌版本: 退出所有设备时递增，签发时写入令牌，低于当前版本的令牌失效
+}
+
+// IsActive 用户是否可以登录
+func (u *User) IsActive() bool {
+	return u.Status != UserStatusDisabled && u.DeletedAt == nil
 }
 
 // BeforeCreate 在创建用户前的钩子函数
@@ -33,5 +51,8 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.Role == "" {
 		u.Role = RoleUser
 	}
+	if u.Status == "" {
+		u.Status = UserStatusActive
+	}
 	return nil
 }
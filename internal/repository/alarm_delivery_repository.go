@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AlarmDeliveryFilter 通知投递记录查询条件 (零值字段不过滤)
+type AlarmDeliveryFilter struct {
+	AlarmID uint
+	Channel string
+	Success *bool
+}
+
+type AlarmDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmDeliveryRepository(db *gorm.DB) *AlarmDeliveryRepository {
+	return &AlarmDeliveryRepository{db: db}
+}
+
+// Create 保存投递记录
+func (r *AlarmDeliveryRepository) Create(delivery *models.AlarmDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// List 按条件分页查询投递记录 (按时间倒序)
+func (r *AlarmDeliveryRepository) List(offset, limit int, filter AlarmDeliveryFilter) ([]models.AlarmDelivery, int64, error) {
+	var deliveries []models.AlarmDelivery
+	var total int64
+
+	query := r.db.Model(&models.AlarmDelivery{})
+	if filter.AlarmID != 0 {
+		query = query.Where("alarm_id = ?", filter.AlarmID)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&deliveries).Error
+	return deliveries, total, err
+}
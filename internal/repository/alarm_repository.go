@@ -34,9 +34,49 @@ func (r *AlarmRepository) Update(alarm *models.Alarm) error {
 	return r.db.Save(alarm).Error
 }
 
-// Delete 删除告警
+// UpdateColumns 只更新告警的指定列 (及更新时间)，不覆盖其他并发修改的字段
+func (r *AlarmRepository) UpdateColumns(alarm *models.Alarm, columns ...string) error {
+	return r.db.Model(alarm).Select(columns).Updates(alarm).Error
+}
+
+// Delete 删除告警及其处理记录
 func (r *AlarmRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Alarm{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("alarm_id = ?", id).Delete(&models.AlarmNote{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Alarm{}, id).Error
+	})
+}
+
+// FindLatestByFingerprint 获取指纹相同的最新告警 (不存在时返回nil)
+func (r *AlarmRepository) FindLatestByFingerprint(fingerprint string) (*models.Alarm, error) {
+	var alarms []models.Alarm
+	err := r.db.Where("fingerprint = ?", fingerprint).Order("id DESC").Limit(1).Find(&alarms).Error
+	if err != nil || len(alarms) == 0 {
+		return nil, err
+	}
+	return &alarms[0], nil
+}
+
+// ListOpenWithFingerprint 获取所有带指纹的未解决告警
+func (r *AlarmRepository) ListOpenWithFingerprint() ([]models.Alarm, error) {
+	var alarms []models.Alarm
+	err := r.db.Where("fingerprint <> '' AND status <> ?", models.AlarmStatusResolved).
+		Order("id ASC").Find(&alarms).Error
+	return alarms, err
+}
+
+// CreateNote 添加告警处理记录
+func (r *AlarmRepository) CreateNote(note *models.AlarmNote) error {
+	return r.db.Create(note).Error
+}
+
+// ListNotes 获取告警的处理记录 (按时间正序)
+func (r *AlarmRepository) ListNotes(alarmID uint) ([]models.AlarmNote, error) {
+	var notes []models.AlarmNote
+	err := r.db.Where("alarm_id = ?", alarmID).Order("id ASC").Find(&notes).Error
+	return notes, err
 }
 
 // List 获取告警列表
@@ -87,6 +127,8 @@ func (r *AlarmRepository) Count(filters map[string]interface{}) (int64, error) {
 				query = query.Where("status = ?", value)
 			case "event_type":
 				query = query.Where("event_type = ?", value)
+			case "severity":
+				query = query.Where("severity = ?", value)
 			}
 		}
 	}
@@ -95,6 +137,24 @@ func (r *AlarmRepository) Count(filters map[string]interface{}) (int64, error) {
 	return count, err
 }
 
+// AlarmGroupCount 按事件类型、级别和状态分组的告警数量
+type AlarmGroupCount struct {
+	EventType models.AlarmEvent
+	Severity  models.AlarmSeverity
+	Status    models.AlarmStatus
+	Count     int64
+}
+
+// CountByGroup 按事件类型、级别和状态分组统计告警数量
+func (r *AlarmRepository) CountByGroup() ([]AlarmGroupCount, error) {
+	var counts []AlarmGroupCount
+	err := r.db.Model(&models.Alarm{}).
+		Select("event_type, severity, status, COUNT(*) AS count").
+		Group("event_type, severity, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
 // GetByStatus 根据状态获取告警列表
 func (r *AlarmRepository) GetByStatus(status models.AlarmStatus) ([]models.Alarm, error) {
 	var alarms []models.Alarm
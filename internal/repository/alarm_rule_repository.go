@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AlarmRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmRuleRepository(db *gorm.DB) *AlarmRuleRepository {
+	return &AlarmRuleRepository{db: db}
+}
+
+// Create 创建告警规则
+func (r *AlarmRuleRepository) Create(rule *models.AlarmRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindByID 根据ID获取告警规则
+func (r *AlarmRuleRepository) FindByID(id uint) (*models.AlarmRule, error) {
+	var rule models.AlarmRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByName 根据名称获取告警规则
+func (r *AlarmRuleRepository) FindByName(name string) (*models.AlarmRule, error) {
+	var rule models.AlarmRule
+	err := r.db.Where("name = ?", name).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 获取所有告警规则
+func (r *AlarmRuleRepository) List() ([]models.AlarmRule, error) {
+	var rules []models.AlarmRule
+	err := r.db.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// Count 统计告警规则数量
+func (r *AlarmRuleRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AlarmRule{}).Count(&count).Error
+	return count, err
+}
+
+// Update 更新告警规则
+func (r *AlarmRuleRepository) Update(rule *models.AlarmRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete 删除告警规则
+func (r *AlarmRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.AlarmRule{}, id).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AlarmSilenceRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmSilenceRepository(db *gorm.DB) *AlarmSilenceRepository {
+	return &AlarmSilenceRepository{db: db}
+}
+
+// Create 创建静默规则
+func (r *AlarmSilenceRepository) Create(silence *models.AlarmSilence) error {
+	return r.db.Create(silence).Error
+}
+
+// FindByID 根据ID获取静默规则
+func (r *AlarmSilenceRepository) FindByID(id uint) (*models.AlarmSilence, error) {
+	var silence models.AlarmSilence
+	err := r.db.First(&silence, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// List 获取所有静默规则 (按开始时间倒序)
+func (r *AlarmSilenceRepository) List() ([]models.AlarmSilence, error) {
+	var silences []models.AlarmSilence
+	err := r.db.Order("starts_at DESC, id DESC").Find(&silences).Error
+	return silences, err
+}
+
+// Update 更新静默规则
+func (r *AlarmSilenceRepository) Update(silence *models.AlarmSilence) error {
+	return r.db.Save(silence).Error
+}
+
+// Delete 删除静默规则
+func (r *AlarmSilenceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.AlarmSilence{}, id).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create 创建API密钥
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// FindByID 根据ID获取API密钥
+func (r *APIKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListActive 获取未吊销的API密钥 (用于加载缓存)
+func (r *APIKeyRepository) ListActive() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("revoked_at IS NULL").Find(&keys).Error
+	return keys, err
+}
+
+// List 获取API密钥列表，支持分页和按用户过滤 (userID为0时不过滤)
+func (r *APIKeyRepository) List(offset, limit int, userID uint) ([]models.APIKey, int64, error) {
+	var keys []models.APIKey
+	var total int64
+
+	query := r.db.Model(&models.APIKey{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&keys).Error
+	return keys, total, err
+}
+
+// Revoke 吊销API密钥
+func (r *APIKeyRepository) Revoke(id uint, revokedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+// AddUsage 累加请求次数并更新最后使用时间
+func (r *APIKeyRepository) AddUsage(id uint, count int64, lastUsedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"request_count": gorm.Expr("request_count + ?", count),
+		"last_used_at":  lastUsedAt,
+	}).Error
+}
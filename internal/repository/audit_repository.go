@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter 审计日志查询条件 (零值字段不过滤)
+type AuditFilter struct {
+	UserID   uint
+	Username string
+	Method   string
+	Route    string // 路由模板包含的关键词
+	TargetID string
+	Success  *bool
+	From     *time.Time
+	To       *time.Time
+}
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create 保存审计记录
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// List 按条件分页查询审计记录 (按时间倒序)
+func (r *AuditRepository) List(offset, limit int, filter AuditFilter) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	query := r.db.Model(&models.AuditLog{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.Route != "" {
+		query = query.Where("route LIKE ?", "%"+filter.Route+"%")
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&logs).Error
+	return logs, total, err
+}
@@ -157,3 +157,13 @@ func (r *NodeRepository) GetByIDs(ids []uint) ([]models.Node, error) {
 	}
 	return nodes, nil
 }
+
+// ListUserNodeIDsByOwner 获取账号名下设备关联的用户设备节点ID
+func (r *NodeRepository) ListUserNodeIDsByOwner(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Node{}).
+		Joins("JOIN devices ON devices.id = nodes.device_id").
+		Where("devices.user_id = ? AND nodes.node_type = ?", userID, models.NodeTypeUser).
+		Pluck("nodes.id", &ids).Error
+	return ids, err
+}
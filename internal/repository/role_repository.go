@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create 创建角色
+func (r *RoleRepository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+// FindByID 根据ID获取角色
+func (r *RoleRepository) FindByID(id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.First(&role, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindByName 根据名称获取角色
+func (r *RoleRepository) FindByName(name models.UserRole) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List 获取所有角色
+func (r *RoleRepository) List() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// Update 更新角色
+func (r *RoleRepository) Update(role *models.Role) error {
+	return r.db.Save(role).Error
+}
+
+// Delete 删除角色
+func (r *RoleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Role{}, id).Error
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StateRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewStateRecordRepository(db *gorm.DB) *StateRecordRepository {
+	return &StateRecordRepository{db: db}
+}
+
+// CreateBatch 批量创建状态记录
+func (r *StateRecordRepository) CreateBatch(records []models.StateRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(records, 100).Error
+}
+
+// List 按时隙/时间范围获取状态记录 (按时间升序)
+func (r *StateRecordRepository) List(filters map[string]interface{}, limit int) ([]models.StateRecord, error) {
+	var records []models.StateRecord
+
+	query := r.db.Model(&models.StateRecord{})
+
+	// 应用过滤条件
+	for key, value := range filters {
+		if value == nil {
+			continue
+		}
+		switch key {
+		case "from_slot":
+			query = query.Where("time_slot >= ?", value)
+		case "to_slot":
+			query = query.Where("time_slot <= ?", value)
+		case "from":
+			query = query.Where("timestamp >= ?", value)
+		case "to":
+			query = query.Where("timestamp <= ?", value)
+		}
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Order("timestamp ASC, id ASC").Find(&records).Error
+	return records, err
+}
+
+// DeleteAll 删除所有状态记录
+func (r *StateRecordRepository) DeleteAll() error {
+	return r.db.Where("1 = 1").Delete(&models.StateRecord{}).Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// Save 保存吊销记录 (同类型同值的记录已存在时更新吊销时间、原因和过期时间)
+func (r *TokenRepository) Save(revocation *models.TokenRevocation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"created_at", "reason", "expires_at"}),
+	}).Create(revocation).Error
+}
+
+// ListActive 获取尚未过期的吊销记录
+func (r *TokenRepository) ListActive(now time.Time) ([]models.TokenRevocation, error) {
+	var revocations []models.TokenRevocation
+	err := r.db.Where("expires_at > ?", now).Find(&revocations).Error
+	return revocations, err
+}
+
+// DeleteExpired 删除已过期的吊销记录 (被吊销的令牌已自然过期)
+func (r *TokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.TokenRevocation{})
+	return result.RowsAffected, result.Error
+}
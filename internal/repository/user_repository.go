@@ -2,6 +2,7 @@ package repository
 
 import (
 	"go-backend/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -20,30 +21,87 @@ func (r *UserRepository) CreateUser(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// FindByID 根据ID获取用户 (不含已删除用户)
 func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.First(&user, id).Error
+	err := r.db.Where("deleted_at IS NULL").First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// FindByUsername 根据用户名获取用户 (不含已删除用户)
 func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.Where("username = ? AND deleted_at IS NULL", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// ExistsEmail 检查邮箱是否已被其他用户使用 (含已删除用户，邮箱唯一索引不区分)
+func (r *UserRepository) ExistsEmail(email string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("email = ? AND id <> ?", email, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// Update 更新用户信息 (令牌版本只能通过IncrementTokenVersion修改)
+func (r *UserRepository) Update(user *models.User) error {
+	return r.db.Omit("token_version").Save(user).Error
+}
+
+// IncrementTokenVersion 递增用户的令牌版本，返回新版本
+func (r *UserRepository) IncrementTokenVersion(id uint) (uint, error) {
+	var version uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", id).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", id).Pluck("token_version", &version).Error
+	})
+	return version, err
+}
+
+// ListTokenVersions 获取令牌版本大于0的用户及其版本
+func (r *UserRepository) ListTokenVersions() (map[uint]uint, error) {
+	var rows []struct {
+		ID           uint
+		TokenVersion uint
+	}
+	err := r.db.Model(&models.User{}).Select("id, token_version").Where("token_version > 0").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[uint]uint, len(rows))
+	for _, row := range rows {
+		versions[row.ID] = row.TokenVersion
+	}
+	return versions, nil
+}
+
+// UpdatePassword 更新密码哈希
+func (r *UserRepository) UpdatePassword(id uint, hash string, changedAt time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":            hash,
+		"password_changed_at": changedAt,
+	}).Error
+}
+
+// SoftDelete 软删除用户 (保留记录，用户名和邮箱不可再使用)
+func (r *UserRepository) SoftDelete(id uint, deletedAt time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("deleted_at", deletedAt).Error
+}
+
 // List 获取用户列表，支持分页和过滤
 func (r *UserRepository) List(offset, limit int, filters map[string]interface{}) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
-	query := r.db.Model(&models.User{})
+	query := r.db.Model(&models.User{}).Where("deleted_at IS NULL")
 
 	// 应用过滤条件
 	for key, value := range filters {
@@ -78,7 +136,7 @@ func (r *UserRepository) List(offset, limit int, filters map[string]interface{})
 // Count 统计用户数量
 func (r *UserRepository) Count(filters map[string]interface{}) (int64, error) {
 	var count int64
-	query := r.db.Model(&models.User{})
+	query := r.db.Model(&models.User{}).Where("deleted_at IS NULL")
 
 	// 应用过滤条件
 	for key, value := range filters {
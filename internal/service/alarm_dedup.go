@@ -0,0 +1,257 @@
+package service
+
+import (
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/pkg/notify"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// lastSeenResolution 告警持续期间最后检测时间的更新间隔 (避免每个时隙都写数据库)
+const lastSeenResolution = time.Minute
+
+// 监控器维护的告警列，只更新这些列，不覆盖操作员并发确认、指派等修改
+var (
+	signalColumns = []string{"name", "severity", "description", "target", "silence_id", "last_seen_at"}
+	flapColumns   = []string{"occurrences", "firing", "flapping", "flap_score", "last_change_at"}
+	reopenColumns = []string{"status", "resolved_at", "acknowledged_by", "acknowledged_at"}
+)
+
+// AlarmFlapPolicy 告警抖动检测策略
+// 告警条件每次触发或恢复计为一次状态变化，累计次数按 Window 指数衰减；
+// 衰减后的次数达到 Start 时进入抖动状态，降到 Stop 以下才退出 (滞回，避免在阈值附近反复进出)。
+// 抖动期间条件恢复不自动解决告警，再次触发也不重复通知
+type AlarmFlapPolicy struct {
+	Window time.Duration // 衰减时间常数，也是已解决告警再次触发时复用原告警的时间窗口
+	Start  float64       // 进入抖动状态的变化次数
+	Stop   float64       // 退出抖动状态的变化次数 (应小于Start)
+}
+
+func DefaultAlarmFlapPolicy() AlarmFlapPolicy {
+	return AlarmFlapPolicy{
+		Window: 10 * time.Minute,
+		Start:  4,
+		Stop:   2,
+	}
+}
+
+// AlarmSignal 监控器检测到的告警条件
+type AlarmSignal struct {
+	Fingerprint string // 告警指纹，相同指纹的告警合并为一条
+	Name        string
+	EventType   models.AlarmEvent
+	Severity    models.AlarmSeverity
+	Description string
+	Target      string // 告警对象ID，系统级告警为空
+	SilenceID   *uint  // 匹配的静默规则 (标记为静默，不发送通知)
+}
+
+// SetFlapPolicy 设置告警抖动检测策略
+func (s *AlarmService) SetFlapPolicy(policy AlarmFlapPolicy) {
+	s.flapPolicy = policy
+}
+
+// RaiseAlarm 按指纹触发告警
+// 已有未解决的同指纹告警时只累加发生次数和更新最后检测时间，不产生新告警；
+// 同指纹告警在抖动窗口内刚被解决时重新打开原告警，否则创建新告警
+func (s *AlarmService) RaiseAlarm(signal AlarmSignal) (*models.Alarm, error) {
+	if signal.Fingerprint == "" {
+		return nil, fmt.Errorf("告警指纹不能为空")
+	}
+
+	s.dedupMutex.Lock()
+	defer s.dedupMutex.Unlock()
+
+	now := s.now()
+	alarm, err := s.alarmRepo.FindLatestByFingerprint(signal.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// 首次触发，或上次解决已超过抖动窗口
+	if alarm == nil || (alarm.Status == models.AlarmStatusResolved &&
+		(alarm.ResolvedAt == nil || now.Sub(*alarm.ResolvedAt) > s.flapPolicy.Window)) {
+		alarm = &models.Alarm{
+			Name:         signal.Name,
+			EventType:    signal.EventType,
+			Severity:     signal.Severity,
+			Status:       models.AlarmStatusActive,
+			Description:  signal.Description,
+			Fingerprint:  signal.Fingerprint,
+			Target:       signal.Target,
+			SilenceID:    signal.SilenceID,
+			Occurrences:  1,
+			Firing:       true,
+			FlapScore:    1,
+			LastSeenAt:   &now,
+			LastChangeAt: &now,
+		}
+		if err := s.CreateAlarm(alarm); err != nil {
+			return nil, err
+		}
+		log.Printf("[AlarmMonitor] 告警已创建: ID=%d, %s - %s", alarm.ID, alarm.Name, alarm.Description)
+		return alarm, nil
+	}
+
+	if alarm.Firing && alarm.Status != models.AlarmStatusResolved {
+		// 告警持续中: 按间隔更新最后检测时间和描述，静默状态变化时立即更新
+		unsilenced := alarm.SilenceID != nil && signal.SilenceID == nil
+		if alarm.LastSeenAt != nil && now.Sub(*alarm.LastSeenAt) < lastSeenResolution &&
+			alarm.Severity == signal.Severity && sameSilence(alarm.SilenceID, signal.SilenceID) {
+			return alarm, nil
+		}
+		applyAlarmSignal(alarm, signal, now)
+		if err := s.alarmRepo.UpdateColumns(alarm, signalColumns...); err != nil {
+			return nil, err
+		}
+		// 静默结束后告警仍在持续，补发通知
+		if unsilenced && !alarm.Flapping {
+			s.notify(alarm, notify.EventAlarmCreated)
+		}
+		return alarm, nil
+	}
+
+	// 条件恢复后再次触发: 已解决的告警重新打开，抖动中保持打开的告警继续使用
+	reopened := alarm.Status == models.AlarmStatusResolved
+	if reopened {
+		alarm.Status = models.AlarmStatusActive
+		alarm.ResolvedAt = nil
+		alarm.AcknowledgedBy = nil
+		alarm.AcknowledgedAt = nil
+	}
+	alarm.Firing = true
+	alarm.Occurrences++
+	applyAlarmSignal(alarm, signal, now)
+	entered := s.recordFlapChange(alarm, now)
+	columns := append(append([]string{}, signalColumns...), flapColumns...)
+	if reopened {
+		columns = append(columns, reopenColumns...)
+	}
+	if err := s.alarmRepo.UpdateColumns(alarm, columns...); err != nil {
+		return nil, err
+	}
+
+	s.addSystemNote(alarm.ID, models.AlarmNoteRecur, fmt.Sprintf("告警再次触发，累计%d次", alarm.Occurrences))
+	if entered {
+		s.addFlappingNote(alarm, true)
+	}
+	if reopened && !alarm.Flapping {
+		s.notify(alarm, notify.EventAlarmCreated)
+	}
+	return alarm, nil
+}
+
+// ClearAlarms 按指纹前缀恢复告警
+// firing 为本次检测中条件仍满足的指纹，前缀匹配但不在其中的未解决告警视为条件已恢复并自动解决 (抖动中的告警除外)。
+// 未解决告警从数据库读取，服务重启后同样能够解决重启前产生的告警
+func (s *AlarmService) ClearAlarms(prefix string, firing map[string]bool) error {
+	s.dedupMutex.Lock()
+	defer s.dedupMutex.Unlock()
+
+	alarms, err := s.alarmRepo.ListOpenWithFingerprint()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for i := range alarms {
+		alarm := &alarms[i]
+		if !strings.HasPrefix(alarm.Fingerprint, prefix) {
+			continue
+		}
+		holding := firing[alarm.Fingerprint]
+
+		changed, entered, left := false, false, false
+		if alarm.Firing && !holding {
+			alarm.Firing = false
+			entered = s.recordFlapChange(alarm, now)
+			changed = true
+		}
+		if alarm.Flapping && !entered && s.decayedFlapScore(alarm, now) < s.flapPolicy.Stop {
+			alarm.Flapping = false
+			left = true
+			changed = true
+		}
+
+		if left {
+			s.addFlappingNote(alarm, false)
+		}
+		if entered {
+			s.addFlappingNote(alarm, true)
+		}
+
+		if !holding && !alarm.Flapping {
+			if err := s.resolve(alarm, SystemActor); err != nil {
+				log.Printf("[AlarmMonitor] 自动解决告警失败: ID=%d, %v", alarm.ID, err)
+				continue
+			}
+			log.Printf("[AlarmMonitor] 告警已自动解决: ID=%d, fingerprint=%s", alarm.ID, alarm.Fingerprint)
+			continue
+		}
+		if changed {
+			if err := s.alarmRepo.UpdateColumns(alarm, flapColumns...); err != nil {
+				log.Printf("[AlarmMonitor] 更新告警状态失败: ID=%d, %v", alarm.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyAlarmSignal 用最新检测结果更新告警 (规则修改后名称、级别和描述随之更新)
+func applyAlarmSignal(alarm *models.Alarm, signal AlarmSignal, now time.Time) {
+	alarm.Name = signal.Name
+	alarm.Severity = signal.Severity
+	alarm.Description = signal.Description
+	alarm.Target = signal.Target
+	alarm.SilenceID = signal.SilenceID
+	alarm.LastSeenAt = &now
+}
+
+func sameSilence(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// decayedFlapScore 按时间衰减后的状态变化次数
+func (s *AlarmService) decayedFlapScore(alarm *models.Alarm, now time.Time) float64 {
+	if alarm.LastChangeAt == nil || s.flapPolicy.Window <= 0 {
+		return alarm.FlapScore
+	}
+	elapsed := now.Sub(*alarm.LastChangeAt)
+	if elapsed <= 0 {
+		return alarm.FlapScore
+	}
+	return alarm.FlapScore * math.Exp(-float64(elapsed)/float64(s.flapPolicy.Window))
+}
+
+// recordFlapChange 记录一次状态变化，返回是否因此进入抖动状态
+func (s *AlarmService) recordFlapChange(alarm *models.Alarm, now time.Time) bool {
+	alarm.FlapScore = s.decayedFlapScore(alarm, now) + 1
+	alarm.LastChangeAt = &now
+	if alarm.Flapping || s.flapPolicy.Start <= 0 || alarm.FlapScore < s.flapPolicy.Start {
+		return false
+	}
+	alarm.Flapping = true
+	return true
+}
+
+// addFlappingNote 记录进入或退出抖动状态
+func (s *AlarmService) addFlappingNote(alarm *models.Alarm, flapping bool) {
+	content := "告警状态趋于稳定，退出抖动状态"
+	if flapping {
+		content = fmt.Sprintf("告警状态频繁变化 (近期约%.1f次)，进入抖动状态: 暂停通知和自动解决", alarm.FlapScore)
+	}
+	log.Printf("[AlarmMonitor] %s: ID=%d, fingerprint=%s", content, alarm.ID, alarm.Fingerprint)
+	s.addSystemNote(alarm.ID, models.AlarmNoteFlapping, content)
+}
+
+func (s *AlarmService) addSystemNote(alarmID uint, kind models.AlarmNoteKind, content string) {
+	if err := s.addNote(alarmID, SystemActor, kind, content); err != nil {
+		log.Printf("[AlarmMonitor] 保存告警处理记录失败: ID=%d, %v", alarmID, err)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAlarmDeduplicationAndFlapping(t *testing.T) {
+	db, _ := newTestDB(t)
+	if err := db.AutoMigrate(&models.Alarm{}, &models.AlarmNote{}); err != nil {
+		t.Fatal(err)
+	}
+	alarmRepo := repository.NewAlarmRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newService := func() *AlarmService {
+		s := NewAlarmService(alarmRepo, userRepo)
+		s.now = func() time.Time { return now }
+		s.SetFlapPolicy(AlarmFlapPolicy{Window: 10 * time.Minute, Start: 3.5, Stop: 1.5})
+		return s
+	}
+	s := newService()
+	signal := AlarmSignal{Fingerprint: "rule_1_2", Name: "队列积压", EventType: models.AlarmEventNetwork, Severity: models.AlarmSeverityWarning}
+	raise := func() *models.Alarm {
+		t.Helper()
+		alarm, err := s.RaiseAlarm(signal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return alarm
+	}
+	clear := func() {
+		t.Helper()
+		if err := s.ClearAlarms("rule_", map[string]bool{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(id uint) *models.Alarm {
+		t.Helper()
+		alarm, err := alarmRepo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return alarm
+	}
+
+	// 持续触发只保留一条告警
+	first := raise()
+	now = now.Add(2 * time.Minute)
+	if again := raise(); again.ID != first.ID || again.Occurrences != 1 || !again.LastSeenAt.Equal(now) {
+		t.Fatalf("持续触发不应产生新告警: %+v", again)
+	}
+	taskAlarm, _ := s.RaiseAlarm(AlarmSignal{Fingerprint: "task_failed_a", Name: "任务失败", EventType: models.AlarmEventSystem})
+
+	// 条件恢复后自动解决，窗口内再次触发时重新打开原告警
+	clear()
+	if got := get(first.ID); got.Status != models.AlarmStatusResolved || got.Firing {
+		t.Fatalf("条件恢复后应自动解决: %+v", got)
+	}
+	if got := get(taskAlarm.ID); got.Status != models.AlarmStatusActive {
+		t.Fatal("其他前缀的告警不应被解决")
+	}
+	if reopened := raise(); reopened.ID != first.ID || reopened.Occurrences != 2 || reopened.Status != models.AlarmStatusActive {
+		t.Fatalf("窗口内再次触发应重新打开原告警: %+v", reopened)
+	}
+
+	// 第4次状态变化进入抖动状态，抖动期间恢复不自动解决
+	clear()
+	if got := get(first.ID); got.Status == models.AlarmStatusResolved || !got.Flapping || got.Firing {
+		t.Fatalf("频繁变化应进入抖动状态并保持打开: %+v", got)
+	}
+	raise()
+	now = now.Add(5 * time.Minute)
+	clear()
+	if got := get(first.ID); got.Status == models.AlarmStatusResolved || !got.Flapping || got.Occurrences != 3 {
+		t.Fatalf("抖动状态未稳定时不应退出: %+v", got)
+	}
+
+	// 状态稳定后退出抖动并解决 (退出阈值低于进入阈值)
+	now = now.Add(20 * time.Minute)
+	clear()
+	if got := get(first.ID); got.Status != models.AlarmStatusResolved || got.Flapping {
+		t.Fatalf("状态稳定后应退出抖动并解决: %+v", got)
+	}
+	notes, _ := alarmRepo.ListNotes(first.ID)
+	flapNotes := 0
+	for _, note := range notes {
+		if note.Kind == models.AlarmNoteFlapping {
+			flapNotes++
+		}
+	}
+	if flapNotes != 2 {
+		t.Errorf("应记录进入和退出抖动状态: %+v", notes)
+	}
+
+	// 超过窗口后再次触发产生新告警；重启后仍能按指纹解决
+	now = now.Add(time.Hour)
+	second := raise()
+	if second.ID == first.ID || second.Occurrences != 1 {
+		t.Fatalf("超过窗口后应产生新告警: %+v", second)
+	}
+	s = newService()
+	clear()
+	if got := get(second.ID); got.Status != models.AlarmStatusResolved {
+		t.Fatalf("重启后应解决重启前产生的告警: %+v", got)
+	}
+}
+
+// TestAlarmMonitorKeepsOperatorChanges 测试监控器更新告警时不覆盖操作员的确认和指派
+func TestAlarmMonitorKeepsOperatorChanges(t *testing.T) {
+	db, user := newTestDB(t)
+	if err := db.AutoMigrate(&models.Alarm{}, &models.AlarmNote{}); err != nil {
+		t.Fatal(err)
+	}
+	alarmRepo := repository.NewAlarmRepository(db)
+	s := NewAlarmService(alarmRepo, repository.NewUserRepository(db))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	signal := AlarmSignal{Fingerprint: "rule_1_2", Name: "队列积压", EventType: models.AlarmEventNetwork, Severity: models.AlarmSeverityWarning}
+	alarm, err := s.RaiseAlarm(signal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, _ := alarmRepo.GetByID(alarm.ID) // 监控器在操作员确认之前读取的告警
+
+	operator := AlarmActor{UserID: user.ID}
+	if _, err := s.AcknowledgeAlarm(alarm.ID, operator, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AssignAlarm(alarm.ID, &user.ID, operator, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用过期数据写入监控器维护的列
+	stale.Occurrences = 5
+	if err := alarmRepo.UpdateColumns(stale, flapColumns...); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := s.RaiseAlarm(signal); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := alarmRepo.GetByID(alarm.ID)
+	if got.Status != models.AlarmStatusAcknowledged || got.AcknowledgedBy == nil || got.AssigneeID == nil {
+		t.Fatalf("监控器不应覆盖确认和指派: %+v", got)
+	}
+	if got.Occurrences != 5 || !got.LastSeenAt.Equal(now) {
+		t.Errorf("监控器维护的列应被更新: %+v", got)
+	}
+
+	// 自动解决时保留处理人
+	if err := s.ClearAlarms("rule_", map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := alarmRepo.GetByID(alarm.ID); got.Status != models.AlarmStatusResolved || got.AssigneeID == nil {
+		t.Errorf("自动解决应只修改状态: %+v", got)
+	}
+}
+
+// TestManualResolveKeepsOccurrences 测试手动解决与告警触发并发时不丢失发生次数
+func TestManualResolveKeepsOccurrences(t *testing.T) {
+	db, user := newTestDB(t)
+	if err := db.AutoMigrate(&models.Alarm{}, &models.AlarmNote{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewAlarmService(repository.NewAlarmRepository(db), repository.NewUserRepository(db))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	signal := AlarmSignal{Fingerprint: "rule_1_2", Name: "队列积压", EventType: models.AlarmEventNetwork, Severity: models.AlarmSeverityWarning}
+	alarm, err := s.RaiseAlarm(signal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := AlarmActor{UserID: user.ID}
+	if err := s.ResolveAlarm(alarm.ID, operator); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReactivateAlarm(alarm.ID, operator); err != nil {
+		t.Fatal(err)
+	}
+
+	// 手动解决读取告警之后、写回之前，监控器再次触发该告警
+	raised := make(chan error, 1)
+	var hooked atomic.Bool
+	s.now = func() time.Time {
+		if hooked.CompareAndSwap(false, true) {
+			go func() {
+				_, err := s.RaiseAlarm(signal)
+				raised <- err
+			}()
+			time.Sleep(50 * time.Millisecond)
+		}
+		return now
+	}
+	if err := s.ResolveAlarm(alarm.ID, operator); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-raised; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetAlarm(alarm.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Occurrences != 2 {
+		t.Errorf("手动解决不应覆盖并发累加的发生次数: got %d, want 2", got.Occurrences)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"log"
+	"strings"
+	"sync"
+)
+
+var ErrAlarmRuleNotFound = errors.New("告警规则不存在")
+
+// maxRuleForSlots 告警规则持续时隙数上限
+const maxRuleForSlots = 10000
+
+// AlarmRuleInput 创建或更新告警规则 (更新时nil字段保持不变)
+type AlarmRuleInput struct {
+	Name        *string
+	Description *string
+	Metric      *string
+	Target      *string
+	Comparator  *models.AlarmComparator
+	Threshold   *float64
+	ForSlots    *int
+	Severity    *models.AlarmSeverity
+	EventType   *models.AlarmEvent
+	Enabled     *bool
+}
+
+// AlarmRuleService 告警规则管理
+// 启用的规则缓存在内存中，告警监控器每个时隙求值时无需查询数据库
+type AlarmRuleService struct {
+	ruleRepo *repository.AlarmRuleRepository
+	enabled  []models.AlarmRule
+	mutex    sync.RWMutex
+}
+
+// NewAlarmRuleService 创建告警规则服务，规则表为空时创建默认规则
+func NewAlarmRuleService(ruleRepo *repository.AlarmRuleRepository) *AlarmRuleService {
+	s := &AlarmRuleService{ruleRepo: ruleRepo}
+
+	if count, err := ruleRepo.Count(); err == nil && count == 0 {
+		for _, rule := range models.DefaultAlarmRules() {
+			rule := rule
+			if err := ruleRepo.Create(&rule); err != nil {
+				log.Printf("⚠️  创建默认告警规则 %s 失败: %v", rule.Name, err)
+				continue
+			}
+		}
+		log.Printf("✓ 已创建默认告警规则")
+	}
+
+	if err := s.reload(); err != nil {
+		log.Printf("⚠️  加载告警规则失败: %v", err)
+	}
+	return s
+}
+
+// reload 从数据库重新加载启用的规则
+func (s *AlarmRuleService) reload() error {
+	rules, err := s.ruleRepo.List()
+	if err != nil {
+		return err
+	}
+	enabled := make([]models.AlarmRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	s.mutex.Lock()
+	s.enabled = enabled
+	s.mutex.Unlock()
+	return nil
+}
+
+// EnabledRules 获取所有启用的规则 (返回副本)
+func (s *AlarmRuleService) EnabledRules() []models.AlarmRule {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	rules := make([]models.AlarmRule, len(s.enabled))
+	copy(rules, s.enabled)
+	return rules
+}
+
+// ListRules 获取所有告警规则
+func (s *AlarmRuleService) ListRules() ([]models.AlarmRule, error) {
+	return s.ruleRepo.List()
+}
+
+// GetRule 根据ID获取告警规则
+func (s *AlarmRuleService) GetRule(id uint) (*models.AlarmRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAlarmRuleNotFound
+	}
+	return rule, nil
+}
+
+// CreateRule 创建告警规则 (名称、指标、运算符和阈值必填)
+func (s *AlarmRuleService) CreateRule(input AlarmRuleInput) (*models.AlarmRule, error) {
+	if input.Name == nil || input.Metric == nil || input.Comparator == nil || input.Threshold == nil {
+		return nil, errors.New("规则名称、指标、运算符和阈值不能为空")
+	}
+
+	rule := &models.AlarmRule{
+		ForSlots:  1,
+		Severity:  models.AlarmSeverityWarning,
+		EventType: models.AlarmEventPerformance,
+		Enabled:   true,
+	}
+	applyAlarmRuleInput(rule, input)
+	if err := s.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, s.reload()
+}
+
+// UpdateRule 更新告警规则，下一个时隙起生效
+func (s *AlarmRuleService) UpdateRule(id uint, input AlarmRuleInput) (*models.AlarmRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAlarmRuleNotFound
+	}
+
+	applyAlarmRuleInput(rule, input)
+	if err := s.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, s.reload()
+}
+
+// DeleteRule 删除告警规则 (该规则产生的活跃告警在下一个时隙自动解决)
+func (s *AlarmRuleService) DeleteRule(id uint) error {
+	if _, err := s.ruleRepo.FindByID(id); err != nil {
+		return ErrAlarmRuleNotFound
+	}
+	if err := s.ruleRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// applyAlarmRuleInput 将非nil字段写入规则
+func applyAlarmRuleInput(rule *models.AlarmRule, input AlarmRuleInput) {
+	if input.Name != nil {
+		rule.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		rule.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Metric != nil {
+		rule.Metric = strings.TrimSpace(*input.Metric)
+	}
+	if input.Target != nil {
+		rule.Target = strings.TrimSpace(*input.Target)
+	}
+	if input.Comparator != nil {
+		rule.Comparator = *input.Comparator
+	}
+	if input.Threshold != nil {
+		rule.Threshold = *input.Threshold
+	}
+	if input.ForSlots != nil {
+		rule.ForSlots = *input.ForSlots
+	}
+	if input.Severity != nil {
+		rule.Severity = *input.Severity
+	}
+	if input.EventType != nil {
+		rule.EventType = *input.EventType
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+}
+
+// validate 校验告警规则
+func (s *AlarmRuleService) validate(rule *models.AlarmRule) error {
+	if rule.Name == "" || len([]rune(rule.Name)) > 100 {
+		return errors.New("规则名称长度必须为1-100个字符")
+	}
+	if existing, err := s.ruleRepo.FindByName(rule.Name); err == nil && existing.ID != rule.ID {
+		return fmt.Errorf("告警规则已存在: %s", rule.Name)
+	}
+
+	metric, ok := models.FindAlarmMetric(rule.Metric)
+	if !ok {
+		return fmt.Errorf("无效的指标: %s", rule.Metric)
+	}
+	if rule.Target != "" && metric.Scope == models.MetricScopeSystem {
+		return fmt.Errorf("系统指标 %s 不能指定对象", rule.Metric)
+	}
+	if !isValidComparator(rule.Comparator) {
+		return fmt.Errorf("无效的运算符: %s", rule.Comparator)
+	}
+	if rule.ForSlots < 1 || rule.ForSlots > maxRuleForSlots {
+		return fmt.Errorf("持续时隙数必须为1-%d", maxRuleForSlots)
+	}
+	if !isValidSeverity(rule.Severity) {
+		return fmt.Errorf("无效的告警级别: %s", rule.Severity)
+	}
+	if !isValidEventType(rule.EventType) {
+		return errors.New("无效的事件类型")
+	}
+	return nil
+}
+
+// isValidComparator 验证比较运算符是否有效
+func isValidComparator(comparator models.AlarmComparator) bool {
+	switch comparator {
+	case models.ComparatorGT, models.ComparatorGTE, models.ComparatorLT,
+		models.ComparatorLTE, models.ComparatorEQ, models.ComparatorNE:
+		return true
+	default:
+		return false
+	}
+}
+
+// isValidSeverity 验证告警级别是否有效
+func isValidSeverity(severity models.AlarmSeverity) bool {
+	switch severity {
+	case models.AlarmSeverityInfo, models.AlarmSeverityWarning, models.AlarmSeverityCritical:
+		return true
+	default:
+		return false
+	}
+}
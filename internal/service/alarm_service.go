@@ -2,37 +2,107 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-backend/internal/models"
 	"go-backend/internal/repository"
+	"go-backend/pkg/notify"
+	"strings"
+	"sync"
 	"time"
 )
 
+// maxAlarmNoteLength 告警评论的最大长度 (字符)
+const maxAlarmNoteLength = 2000
+
 type AlarmService struct {
-	alarmRepo *repository.AlarmRepository
+	alarmRepo  *repository.AlarmRepository
+	userRepo   *repository.UserRepository
+	notifier   *NotificationService
+	flapPolicy AlarmFlapPolicy
+	dedupMutex sync.Mutex // 串行化按指纹的告警触发和恢复
+	now        func() time.Time
 }
 
 // AlarmStats 告警统计数据
 type AlarmStats struct {
-	TotalCount    int64 `json:"total_count"`    // 告警总数
-	ActiveCount   int64 `json:"active_count"`   // 活跃告警数
-	ResolvedCount int64 `json:"resolved_count"` // 已解决告警数
+	TotalCount        int64                                    `json:"total_count"`        // 告警总数
+	ActiveCount       int64                                    `json:"active_count"`       // 活跃 (未确认) 告警数
+	AcknowledgedCount int64                                    `json:"acknowledged_count"` // 已确认告警数
+	ResolvedCount     int64                                    `json:"resolved_count"`     // 已解决告警数
+	BySeverity        map[models.AlarmSeverity]AlarmStateCount `json:"by_severity"`        // 按级别统计
+}
+
+// AlarmStateCount 按状态统计的告警数量
+type AlarmStateCount struct {
+	Total        int64 `json:"total"`
+	Active       int64 `json:"active"`
+	Acknowledged int64 `json:"acknowledged"`
+	Resolved     int64 `json:"resolved"`
+}
+
+// add 累加某状态的告警数量
+func (c *AlarmStateCount) add(status models.AlarmStatus, count int64) {
+	c.Total += count
+	switch status {
+	case models.AlarmStatusActive:
+		c.Active += count
+	case models.AlarmStatusAcknowledged:
+		c.Acknowledged += count
+	case models.AlarmStatusResolved:
+		c.Resolved += count
+	}
+}
+
+// AlarmActor 告警操作人 (UserID为0表示系统自动操作)
+type AlarmActor struct {
+	UserID   uint
+	Username string
 }
 
-func NewAlarmService(alarmRepo *repository.AlarmRepository) *AlarmService {
+// SystemActor 告警监控器等系统组件的自动操作
+var SystemActor = AlarmActor{Username: "system"}
+
+func NewAlarmService(alarmRepo *repository.AlarmRepository, userRepo *repository.UserRepository) *AlarmService {
 	return &AlarmService{
-		alarmRepo: alarmRepo,
+		alarmRepo:  alarmRepo,
+		userRepo:   userRepo,
+		flapPolicy: DefaultAlarmFlapPolicy(),
+		now:        time.Now,
+	}
+}
+
+// SetNotifier 设置告警通知 (告警产生和解决时发送，nil表示不通知)
+func (s *AlarmService) SetNotifier(notifier *NotificationService) {
+	s.notifier = notifier
+}
+
+// notify 发送告警事件通知 (静默的告警不通知)
+func (s *AlarmService) notify(alarm *models.Alarm, event string) {
+	if s.notifier != nil && alarm.SilenceID == nil {
+		s.notifier.NotifyAlarm(alarm, event)
 	}
 }
 
 // CreateAlarm 创建新告警
 func (s *AlarmService) CreateAlarm(alarm *models.Alarm) error {
-	// 设置默认状态为活跃
+	// 设置默认状态为活跃，默认级别为警告
 	if alarm.Status == "" {
 		alarm.Status = models.AlarmStatusActive
 	}
+	if alarm.Severity == "" {
+		alarm.Severity = models.AlarmSeverityWarning
+	}
+	if alarm.Occurrences < 1 {
+		alarm.Occurrences = 1
+	}
+
+	// 验证告警级别
+	if !isValidSeverity(alarm.Severity) {
+		return errors.New("无效的告警级别")
+	}
 
 	// 验证事件类型
-	if !s.isValidEventType(alarm.EventType) {
+	if !isValidEventType(alarm.EventType) {
 		return errors.New("无效的事件类型")
 	}
 
@@ -41,7 +111,11 @@ func (s *AlarmService) CreateAlarm(alarm *models.Alarm) error {
 		return errors.New("无效的告警状态")
 	}
 
-	return s.alarmRepo.Create(alarm)
+	if err := s.alarmRepo.Create(alarm); err != nil {
+		return err
+	}
+	s.notify(alarm, notify.EventAlarmCreated)
+	return nil
 }
 
 // GetAlarm 获取告警详情
@@ -72,14 +146,17 @@ func (s *AlarmService) UpdateAlarm(alarm *models.Alarm) error {
 	}
 
 	// 验证事件类型
-	if alarm.EventType != "" && !s.isValidEventType(alarm.EventType) {
+	if alarm.EventType != "" && !isValidEventType(alarm.EventType) {
 		return errors.New("无效的事件类型")
 	}
 
-	// 验证状态
+	// 验证状态和级别
 	if alarm.Status != "" && !s.isValidStatus(alarm.Status) {
 		return errors.New("无效的告警状态")
 	}
+	if alarm.Severity != "" && !isValidSeverity(alarm.Severity) {
+		return errors.New("无效的告警级别")
+	}
 
 	// 如果状态变为已解决，设置解决时间
 	if alarm.Status == models.AlarmStatusResolved && existingAlarm.Status != models.AlarmStatusResolved {
@@ -106,8 +183,12 @@ func (s *AlarmService) DeleteAlarm(id uint) error {
 	return s.alarmRepo.Delete(id)
 }
 
-// ResolveAlarm 解决告警
-func (s *AlarmService) ResolveAlarm(id uint) error {
+// ResolveAlarm 解决告警 (活跃或已确认的告警均可解决)
+// 与告警触发和自动恢复串行执行，避免写回过期的发生次数和抖动状态
+func (s *AlarmService) ResolveAlarm(id uint, actor AlarmActor) error {
+	s.dedupMutex.Lock()
+	defer s.dedupMutex.Unlock()
+
 	alarm, err := s.alarmRepo.GetByID(id)
 	if err != nil {
 		return errors.New("告警不存在")
@@ -116,17 +197,29 @@ func (s *AlarmService) ResolveAlarm(id uint) error {
 	if alarm.Status == models.AlarmStatusResolved {
 		return errors.New("告警已经被解决")
 	}
+	return s.resolve(alarm, actor)
+}
 
+// resolve 将告警标记为已解决并发送通知
+// 手动解决时告警条件可能仍然满足，监控器下一次检测到时会重新打开该告警
+func (s *AlarmService) resolve(alarm *models.Alarm, actor AlarmActor) error {
 	alarm.Status = models.AlarmStatusResolved
-	now := time.Now()
+	now := s.now()
 	alarm.ResolvedAt = &now
 	alarm.UpdatedAt = now
+	alarm.Firing = false
+	alarm.Flapping = false
 
-	return s.alarmRepo.Update(alarm)
+	// 只更新解决相关的列，ClearAlarms中已修改的抖动状态一并写入
+	if err := s.alarmRepo.UpdateColumns(alarm, append([]string{"status", "resolved_at"}, flapColumns...)...); err != nil {
+		return err
+	}
+	s.notify(alarm, notify.EventAlarmResolved)
+	return s.addNote(alarm.ID, actor, models.AlarmNoteResolve, "")
 }
 
-// ReactivateAlarm 重新激活告警
-func (s *AlarmService) ReactivateAlarm(id uint) error {
+// ReactivateAlarm 重新激活告警 (清除确认信息，保留指派的处理人)
+func (s *AlarmService) ReactivateAlarm(id uint, actor AlarmActor) error {
 	alarm, err := s.alarmRepo.GetByID(id)
 	if err != nil {
 		return errors.New("告警不存在")
@@ -138,9 +231,131 @@ func (s *AlarmService) ReactivateAlarm(id uint) error {
 
 	alarm.Status = models.AlarmStatusActive
 	alarm.ResolvedAt = nil
+	alarm.AcknowledgedBy = nil
+	alarm.AcknowledgedAt = nil
 	alarm.UpdatedAt = time.Now()
 
-	return s.alarmRepo.Update(alarm)
+	if err := s.alarmRepo.UpdateColumns(alarm, reopenColumns...); err != nil {
+		return err
+	}
+	return s.addNote(alarm.ID, actor, models.AlarmNoteReactivate, "")
+}
+
+// AcknowledgeAlarm 确认告警 (表示已有人在处理)，可附带说明
+func (s *AlarmService) AcknowledgeAlarm(id uint, actor AlarmActor, note string) (*models.Alarm, error) {
+	note, err := normalizeAlarmNote(note, false)
+	if err != nil {
+		return nil, err
+	}
+	alarm, err := s.alarmRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("告警不存在")
+	}
+
+	switch alarm.Status {
+	case models.AlarmStatusAcknowledged:
+		return nil, errors.New("告警已经被确认")
+	case models.AlarmStatusResolved:
+		return nil, errors.New("告警已经被解决")
+	}
+
+	now := time.Now()
+	userID := actor.UserID
+	alarm.Status = models.AlarmStatusAcknowledged
+	alarm.AcknowledgedBy = &userID
+	alarm.AcknowledgedAt = &now
+	alarm.UpdatedAt = now
+
+	if err := s.alarmRepo.UpdateColumns(alarm, "status", "acknowledged_by", "acknowledged_at"); err != nil {
+		return nil, err
+	}
+	return alarm, s.addNote(alarm.ID, actor, models.AlarmNoteAcknowledge, note)
+}
+
+// AssignAlarm 将告警指派给用户 (assigneeID为nil时取消指派)，可附带说明
+func (s *AlarmService) AssignAlarm(id uint, assigneeID *uint, actor AlarmActor, note string) (*models.Alarm, error) {
+	note, err := normalizeAlarmNote(note, false)
+	if err != nil {
+		return nil, err
+	}
+	alarm, err := s.alarmRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("告警不存在")
+	}
+
+	content := "取消指派"
+	if assigneeID != nil {
+		assignee, err := s.userRepo.FindByID(*assigneeID)
+		if err != nil {
+			return nil, errors.New("指派的用户不存在")
+		}
+		if !assignee.IsActive() {
+			return nil, errors.New("不能指派给已禁用的用户")
+		}
+		content = fmt.Sprintf("指派给 %s", assignee.Username)
+	}
+	if note != "" {
+		content += ": " + note
+	}
+
+	alarm.AssigneeID = assigneeID
+	alarm.UpdatedAt = time.Now()
+	if err := s.alarmRepo.UpdateColumns(alarm, "assignee_id"); err != nil {
+		return nil, err
+	}
+	return alarm, s.addNote(alarm.ID, actor, models.AlarmNoteAssign, content)
+}
+
+// AddComment 为告警添加评论
+func (s *AlarmService) AddComment(id uint, actor AlarmActor, content string) (*models.AlarmNote, error) {
+	content, err := normalizeAlarmNote(content, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.alarmRepo.GetByID(id); err != nil {
+		return nil, errors.New("告警不存在")
+	}
+
+	note := newAlarmNote(id, actor, models.AlarmNoteComment, content)
+	if err := s.alarmRepo.CreateNote(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// ListNotes 获取告警的评论和处理历史
+func (s *AlarmService) ListNotes(id uint) ([]models.AlarmNote, error) {
+	if _, err := s.alarmRepo.GetByID(id); err != nil {
+		return nil, errors.New("告警不存在")
+	}
+	return s.alarmRepo.ListNotes(id)
+}
+
+// addNote 记录告警处理历史
+func (s *AlarmService) addNote(alarmID uint, actor AlarmActor, kind models.AlarmNoteKind, content string) error {
+	return s.alarmRepo.CreateNote(newAlarmNote(alarmID, actor, kind, content))
+}
+
+func newAlarmNote(alarmID uint, actor AlarmActor, kind models.AlarmNoteKind, content string) *models.AlarmNote {
+	return &models.AlarmNote{
+		AlarmID:  alarmID,
+		UserID:   actor.UserID,
+		Username: actor.Username,
+		Kind:     kind,
+		Content:  content,
+	}
+}
+
+// normalizeAlarmNote 去除首尾空白并校验长度
+func normalizeAlarmNote(content string, required bool) (string, error) {
+	content = strings.TrimSpace(content)
+	if required && content == "" {
+		return "", errors.New("评论内容不能为空")
+	}
+	if len([]rune(content)) > maxAlarmNoteLength {
+		return "", fmt.Errorf("评论内容不能超过%d个字符", maxAlarmNoteLength)
+	}
+	return content, nil
 }
 
 // GetActiveAlarms 获取所有活跃告警
@@ -158,7 +373,7 @@ func (s *AlarmService) GetAlarmsByStatus(status models.AlarmStatus) ([]models.Al
 
 // GetAlarmsByEventType 根据事件类型获取告警
 func (s *AlarmService) GetAlarmsByEventType(eventType models.AlarmEvent) ([]models.Alarm, error) {
-	if !s.isValidEventType(eventType) {
+	if !isValidEventType(eventType) {
 		return nil, errors.New("无效的事件类型")
 	}
 	return s.alarmRepo.GetByEventType(eventType)
@@ -172,44 +387,47 @@ func (s *AlarmService) GetRecentAlarms(limit int) ([]models.Alarm, error) {
 	return s.alarmRepo.GetRecentAlarms(limit)
 }
 
-// GetAlarmStats 获取告警统计信息
+// GetAlarmStats 获取告警统计信息 (按状态和级别)
 func (s *AlarmService) GetAlarmStats() (*AlarmStats, error) {
-	stats := &AlarmStats{}
-
-	// 获取告警总数
-	var err error
-	stats.TotalCount, err = s.alarmRepo.Count(nil)
+	counts, err := s.alarmRepo.CountByGroup()
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取活跃告警数量
-	stats.ActiveCount, err = s.alarmRepo.Count(map[string]interface{}{
-		"status": models.AlarmStatusActive,
-	})
-	if err != nil {
-		return nil, err
+	var total AlarmStateCount
+	bySeverity := make(map[models.AlarmSeverity]AlarmStateCount, len(models.AlarmSeverities))
+	for _, severity := range models.AlarmSeverities {
+		bySeverity[severity] = AlarmStateCount{}
 	}
-
-	// 获取已解决告警数量
-	stats.ResolvedCount, err = s.alarmRepo.Count(map[string]interface{}{
-		"status": models.AlarmStatusResolved,
-	})
-	if err != nil {
-		return nil, err
+	for _, c := range counts {
+		total.add(c.Status, c.Count)
+		severityCount := bySeverity[c.Severity]
+		severityCount.add(c.Status, c.Count)
+		bySeverity[c.Severity] = severityCount
 	}
 
-	return stats, nil
+	return &AlarmStats{
+		TotalCount:        total.Total,
+		ActiveCount:       total.Active,
+		AcknowledgedCount: total.Acknowledged,
+		ResolvedCount:     total.Resolved,
+		BySeverity:        bySeverity,
+	}, nil
+}
+
+// CountByGroup 按事件类型、级别和状态统计告警数量
+func (s *AlarmService) CountByGroup() ([]repository.AlarmGroupCount, error) {
+	return s.alarmRepo.CountByGroup()
 }
 
 // BatchResolveAlarms 批量解决告警
-func (s *AlarmService) BatchResolveAlarms(ids []uint) error {
+func (s *AlarmService) BatchResolveAlarms(ids []uint, actor AlarmActor) error {
 	if len(ids) == 0 {
 		return errors.New("告警ID列表不能为空")
 	}
 
 	for _, id := range ids {
-		if err := s.ResolveAlarm(id); err != nil {
+		if err := s.ResolveAlarm(id, actor); err != nil {
 			return err
 		}
 	}
@@ -233,7 +451,7 @@ func (s *AlarmService) BatchDeleteAlarms(ids []uint) error {
 }
 
 // isValidEventType 验证事件类型是否有效
-func (s *AlarmService) isValidEventType(eventType models.AlarmEvent) bool {
+func isValidEventType(eventType models.AlarmEvent) bool {
 	switch eventType {
 	case models.AlarmEventHardware, models.AlarmEventNetwork, models.AlarmEventSecurity,
 		models.AlarmEventPerformance, models.AlarmEventSystem:
@@ -246,7 +464,7 @@ func (s *AlarmService) isValidEventType(eventType models.AlarmEvent) bool {
 // isValidStatus 验证告警状态是否有效
 func (s *AlarmService) isValidStatus(status models.AlarmStatus) bool {
 	switch status {
-	case models.AlarmStatusActive, models.AlarmStatusResolved:
+	case models.AlarmStatusActive, models.AlarmStatusAcknowledged, models.AlarmStatusResolved:
 		return true
 	default:
 		return false
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+)
+
+func TestAlarmWorkflow(t *testing.T) {
+	db, user := newTestDB(t)
+	if err := db.AutoMigrate(&models.Alarm{}, &models.AlarmNote{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewAlarmService(repository.NewAlarmRepository(db), repository.NewUserRepository(db))
+	actor := AlarmActor{UserID: user.ID, Username: user.Username}
+
+	critical := &models.Alarm{Name: "队列溢出", EventType: models.AlarmEventNetwork, Severity: models.AlarmSeverityCritical}
+	minor := &models.Alarm{Name: "延迟偏高", EventType: models.AlarmEventPerformance}
+	for _, alarm := range []*models.Alarm{critical, minor} {
+		if err := s.CreateAlarm(alarm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if minor.Severity != models.AlarmSeverityWarning {
+		t.Errorf("默认级别应为warning: %s", minor.Severity)
+	}
+
+	// 确认 -> 指派 -> 评论 -> 解决
+	alarm, err := s.AcknowledgeAlarm(critical.ID, actor, "排查中")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alarm.Status != models.AlarmStatusAcknowledged || alarm.AcknowledgedBy == nil || *alarm.AcknowledgedBy != user.ID {
+		t.Fatalf("确认信息不正确: %+v", alarm)
+	}
+	if _, err := s.AcknowledgeAlarm(critical.ID, actor, ""); err == nil {
+		t.Error("不应重复确认")
+	}
+	missing := uint(999)
+	if _, err := s.AssignAlarm(critical.ID, &missing, actor, ""); err == nil {
+		t.Error("不能指派给不存在的用户")
+	}
+	if alarm, err = s.AssignAlarm(critical.ID, &user.ID, actor, "请跟进"); err != nil || *alarm.AssigneeID != user.ID {
+		t.Fatalf("指派失败: %+v, %v", alarm, err)
+	}
+	if _, err := s.AddComment(critical.ID, actor, "   "); err == nil {
+		t.Error("空评论应被拒绝")
+	}
+	if _, err := s.AddComment(critical.ID, actor, "已重启设备"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := s.GetAlarmStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalCount != 2 || stats.ActiveCount != 1 || stats.AcknowledgedCount != 1 ||
+		stats.BySeverity[models.AlarmSeverityCritical].Acknowledged != 1 ||
+		stats.BySeverity[models.AlarmSeverityWarning].Active != 1 {
+		t.Errorf("统计不正确: %+v", stats)
+	}
+
+	if err := s.ResolveAlarm(critical.ID, SystemActor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReactivateAlarm(critical.ID, actor); err != nil {
+		t.Fatal(err)
+	}
+	reactivated, _ := s.GetAlarm(critical.ID)
+	if reactivated.AcknowledgedBy != nil || reactivated.AssigneeID == nil {
+		t.Errorf("重新激活应清除确认信息并保留处理人: %+v", reactivated)
+	}
+
+	notes, err := s.ListNotes(critical.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []models.AlarmNoteKind{models.AlarmNoteAcknowledge, models.AlarmNoteAssign, models.AlarmNoteComment,
+		models.AlarmNoteResolve, models.AlarmNoteReactivate}
+	if len(notes) != len(kinds) {
+		t.Fatalf("处理历史条数不正确: %+v", notes)
+	}
+	for i, kind := range kinds {
+		if notes[i].Kind != kind {
+			t.Errorf("第%d条记录应为%s: %+v", i+1, kind, notes[i])
+		}
+	}
+	if notes[1].Content != "指派给 alice: 请跟进" || notes[3].Username != "system" {
+		t.Errorf("处理记录内容不正确: %+v", notes)
+	}
+
+	// 删除告警时一并删除处理记录
+	if err := s.DeleteAlarm(critical.ID); err != nil {
+		t.Fatal(err)
+	}
+	if notes, _ := repository.NewAlarmRepository(db).ListNotes(critical.ID); len(notes) != 0 {
+		t.Errorf("处理记录未删除: %+v", notes)
+	}
+}
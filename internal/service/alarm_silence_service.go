@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrAlarmSilenceNotFound = errors.New("静默规则不存在")
+
+// AlarmSilenceState 静默规则的当前状态
+type AlarmSilenceState string
+
+const (
+	SilenceStateActive  AlarmSilenceState = "active"  // 生效中
+	SilenceStatePending AlarmSilenceState = "pending" // 尚未开始，或处于两个维护窗口之间
+	SilenceStateExpired AlarmSilenceState = "expired" // 已过期
+)
+
+// AlarmSilenceStatus 静默规则及其当前状态
+type AlarmSilenceStatus struct {
+	models.AlarmSilence
+	State          AlarmSilenceState `json:"state"`
+	WindowStartsAt *time.Time        `json:"window_starts_at,omitempty"` // 当前生效时间段的开始 (生效中时)
+	WindowEndsAt   *time.Time        `json:"window_ends_at,omitempty"`   // 当前生效时间段的结束 (生效中时)
+	NextStartsAt   *time.Time        `json:"next_starts_at,omitempty"`   // 下一个时间段的开始
+}
+
+// AlarmSilenceInput 创建或更新静默规则 (更新时nil字段保持不变)
+type AlarmSilenceInput struct {
+	Name        *string
+	Comment     *string
+	EventType   *models.AlarmEvent
+	NamePattern *string
+	Target      *string
+	Action      *models.AlarmSilenceAction
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	Repeat      *models.AlarmSilenceRepeat
+	RepeatUntil *time.Time
+}
+
+// AlarmSilenceService 告警静默和维护窗口管理
+// 静默规则缓存在内存中，告警监控器产生告警时无需查询数据库
+type AlarmSilenceService struct {
+	silenceRepo *repository.AlarmSilenceRepository
+	silences    []models.AlarmSilence
+	mutex       sync.RWMutex
+	now         func() time.Time
+}
+
+func NewAlarmSilenceService(silenceRepo *repository.AlarmSilenceRepository) *AlarmSilenceService {
+	s := &AlarmSilenceService{
+		silenceRepo: silenceRepo,
+		now:         time.Now,
+	}
+	if err := s.reload(); err != nil {
+		log.Printf("⚠️  加载告警静默规则失败: %v", err)
+	}
+	return s
+}
+
+// reload 从数据库重新加载静默规则
+func (s *AlarmSilenceService) reload() error {
+	silences, err := s.silenceRepo.List()
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	s.silences = silences
+	s.mutex.Unlock()
+	return nil
+}
+
+// MatchSilence 查找当前生效且匹配告警的静默规则 (多条匹配时优先抑制)，没有时返回nil
+func (s *AlarmSilenceService) MatchSilence(eventType models.AlarmEvent, name, target string) *models.AlarmSilence {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := s.now()
+	var matched *models.AlarmSilence
+	for i := range s.silences {
+		silence := &s.silences[i]
+		if _, _, active := silence.Window(now); !active || !silence.Matches(eventType, name, target) {
+			continue
+		}
+		if matched == nil || silence.Action == models.AlarmSilenceSuppress {
+			copied := *silence
+			matched = &copied
+		}
+		if matched.Action == models.AlarmSilenceSuppress {
+			break
+		}
+	}
+	return matched
+}
+
+// ListSilences 获取静默规则及其当前状态，state 不为空时只返回该状态的规则
+func (s *AlarmSilenceService) ListSilences(state AlarmSilenceState) ([]AlarmSilenceStatus, error) {
+	silences, err := s.silenceRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AlarmSilenceStatus, 0, len(silences))
+	for _, silence := range silences {
+		status := s.Status(&silence)
+		if state == "" || status.State == state {
+			result = append(result, status)
+		}
+	}
+	return result, nil
+}
+
+// Status 计算静默规则的当前状态
+func (s *AlarmSilenceService) Status(silence *models.AlarmSilence) AlarmSilenceStatus {
+	now := s.now()
+	status := AlarmSilenceStatus{AlarmSilence: *silence, NextStartsAt: silence.NextStart(now)}
+	start, end, active := silence.Window(now)
+	switch {
+	case active:
+		status.State = SilenceStateActive
+		status.WindowStartsAt = &start
+		status.WindowEndsAt = &end
+	case status.NextStartsAt != nil:
+		status.State = SilenceStatePending
+	default:
+		status.State = SilenceStateExpired
+	}
+	return status
+}
+
+// GetSilence 根据ID获取静默规则
+func (s *AlarmSilenceService) GetSilence(id uint) (*models.AlarmSilence, error) {
+	silence, err := s.silenceRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAlarmSilenceNotFound
+	}
+	return silence, nil
+}
+
+// CreateSilence 创建静默规则 (名称和结束时间必填，开始时间默认为当前时间)
+func (s *AlarmSilenceService) CreateSilence(input AlarmSilenceInput, createdBy uint) (*models.AlarmSilence, error) {
+	if input.Name == nil || input.EndsAt == nil {
+		return nil, errors.New("静默名称和结束时间不能为空")
+	}
+
+	silence := &models.AlarmSilence{
+		Action:    models.AlarmSilenceMark,
+		StartsAt:  s.now(),
+		CreatedBy: createdBy,
+	}
+	applyAlarmSilenceInput(silence, input)
+	if err := validateAlarmSilence(silence); err != nil {
+		return nil, err
+	}
+	if s.Status(silence).State == SilenceStateExpired {
+		return nil, errors.New("静默结束时间已过")
+	}
+	if err := s.silenceRepo.Create(silence); err != nil {
+		return nil, err
+	}
+	return silence, s.reload()
+}
+
+// UpdateSilence 更新静默规则，立即生效
+func (s *AlarmSilenceService) UpdateSilence(id uint, input AlarmSilenceInput) (*models.AlarmSilence, error) {
+	silence, err := s.silenceRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAlarmSilenceNotFound
+	}
+
+	applyAlarmSilenceInput(silence, input)
+	if err := validateAlarmSilence(silence); err != nil {
+		return nil, err
+	}
+	if err := s.silenceRepo.Update(silence); err != nil {
+		return nil, err
+	}
+	return silence, s.reload()
+}
+
+// DeleteSilence 删除静默规则 (已标记为静默的告警保持不变)
+func (s *AlarmSilenceService) DeleteSilence(id uint) error {
+	if _, err := s.silenceRepo.FindByID(id); err != nil {
+		return ErrAlarmSilenceNotFound
+	}
+	if err := s.silenceRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// applyAlarmSilenceInput 将非nil字段写入静默规则
+func applyAlarmSilenceInput(silence *models.AlarmSilence, input AlarmSilenceInput) {
+	if input.Name != nil {
+		silence.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Comment != nil {
+		silence.Comment = strings.TrimSpace(*input.Comment)
+	}
+	if input.EventType != nil {
+		silence.EventType = *input.EventType
+	}
+	if input.NamePattern != nil {
+		silence.NamePattern = strings.TrimSpace(*input.NamePattern)
+	}
+	if input.Target != nil {
+		silence.Target = strings.TrimSpace(*input.Target)
+	}
+	if input.Action != nil {
+		silence.Action = *input.Action
+	}
+	if input.StartsAt != nil {
+		silence.StartsAt = *input.StartsAt
+	}
+	if input.EndsAt != nil {
+		silence.EndsAt = *input.EndsAt
+	}
+	if input.Repeat != nil {
+		silence.Repeat = *input.Repeat
+	}
+	if input.RepeatUntil != nil {
+		silence.RepeatUntil = input.RepeatUntil
+	}
+}
+
+// validateAlarmSilence 校验静默规则
+func validateAlarmSilence(silence *models.AlarmSilence) error {
+	if silence.Name == "" || len([]rune(silence.Name)) > 100 {
+		return errors.New("静默名称长度必须为1-100个字符")
+	}
+	if silence.EventType != "" && !isValidEventType(silence.EventType) {
+		return errors.New("无效的事件类型")
+	}
+	if _, err := path.Match(silence.NamePattern, ""); err != nil {
+		return fmt.Errorf("无效的名称通配符: %s", silence.NamePattern)
+	}
+	if silence.Action != models.AlarmSilenceSuppress && silence.Action != models.AlarmSilenceMark {
+		return fmt.Errorf("无效的静默方式: %s", silence.Action)
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+
+	switch silence.Repeat {
+	case models.AlarmSilenceOnce:
+		silence.RepeatUntil = nil
+	case models.AlarmSilenceDaily, models.AlarmSilenceWeekly:
+		period := time.Duration(silence.Repeat.Days()) * 24 * time.Hour
+		if silence.EndsAt.Sub(silence.StartsAt) >= period {
+			return errors.New("维护窗口时长必须小于重复周期")
+		}
+		if silence.RepeatUntil != nil && silence.RepeatUntil.Before(silence.StartsAt) {
+			return errors.New("重复截止时间不能早于开始时间")
+		}
+	default:
+		return fmt.Errorf("无效的重复周期: %s", silence.Repeat)
+	}
+	return nil
+}
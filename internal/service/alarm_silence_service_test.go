@@ -0,0 +1,90 @@
+package service
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAlarmSilences(t *testing.T) {
+	db, _ := newTestDB(t)
+	if err := db.AutoMigrate(&models.AlarmSilence{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewAlarmSilenceService(repository.NewAlarmSilenceRepository(db))
+	now := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC) // 周三
+	s.now = func() time.Time { return now }
+
+	str := func(v string) *string { return &v }
+	at := func(day, hour int) *time.Time {
+		v := time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+	performance := models.AlarmEventPerformance
+	suppress := models.AlarmSilenceSuppress
+	weekly := models.AlarmSilenceWeekly
+
+	// 每周一 02:00-04:00 的维护窗口，抑制所有性能告警
+	window, err := s.CreateSilence(AlarmSilenceInput{
+		Name: str("每周维护"), EventType: &performance, Action: &suppress,
+		StartsAt: at(5, 2), EndsAt: at(5, 4), Repeat: &weekly,
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 通信设备2的队列告警标记为静默 (默认方式)
+	if _, err := s.CreateSilence(AlarmSilenceInput{
+		Name: str("设备2迁移"), NamePattern: str("通信设备队列积压*"), Target: str("2"), EndsAt: at(13, 0),
+	}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, input := range map[string]AlarmSilenceInput{
+		"无效通配符":  {Name: str("x"), NamePattern: str("["), EndsAt: at(8, 0)},
+		"结束早于开始": {Name: str("x"), StartsAt: at(8, 0), EndsAt: at(7, 0)},
+		"已过期":    {Name: str("x"), StartsAt: at(1, 0), EndsAt: at(2, 0)},
+		"窗口长于周期": {Name: str("x"), StartsAt: at(1, 0), EndsAt: at(9, 0), Repeat: &weekly},
+		"缺少结束时间": {Name: str("x")},
+	} {
+		if _, err := s.CreateSilence(input, 1); err == nil {
+			t.Errorf("%s: 应校验失败", name)
+		}
+	}
+
+	queueAlarm := "通信设备队列积压 (通信设备 2)"
+	if silence := s.MatchSilence(performance, queueAlarm, "2"); silence == nil || silence.Action != models.AlarmSilenceMark {
+		t.Fatalf("应匹配设备2的静默: %+v", silence)
+	}
+	if silence := s.MatchSilence(performance, queueAlarm, "3"); silence != nil {
+		t.Fatalf("其他设备不应被静默: %+v", silence)
+	}
+
+	// 周一维护窗口内两条规则都匹配时优先抑制
+	now = *at(12, 3)
+	if silence := s.MatchSilence(performance, queueAlarm, "2"); silence == nil || silence.ID != window.ID {
+		t.Fatalf("维护窗口内应优先抑制: %+v", silence)
+	}
+	if active, _ := s.ListSilences(SilenceStateActive); len(active) != 2 || !active[1].WindowEndsAt.Equal(*at(12, 4)) {
+		t.Fatalf("生效中的静默不正确: %+v", active)
+	}
+
+	// 窗口结束后等待下一周
+	now = *at(12, 5)
+	if silence := s.MatchSilence(performance, "系统延迟过高", ""); silence != nil {
+		t.Fatalf("维护窗口结束后不应匹配: %+v", silence)
+	}
+	pending, _ := s.ListSilences(SilenceStatePending)
+	if len(pending) != 1 || pending[0].ID != window.ID || !pending[0].NextStartsAt.Equal(*at(19, 2)) {
+		t.Fatalf("下一个维护窗口不正确: %+v", pending)
+	}
+
+	// 设置重复截止时间后不再有下一个窗口
+	if _, err := s.UpdateSilence(window.ID, AlarmSilenceInput{RepeatUntil: at(15, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	now = *at(20, 0)
+	if expired, _ := s.ListSilences(SilenceStateExpired); len(expired) != 2 {
+		t.Fatalf("静默应全部过期: %+v", expired)
+	}
+}
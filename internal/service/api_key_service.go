@@ -0,0 +1,273 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// APIKeyPrefix API密钥前缀 (便于识别和密钥扫描)
+	APIKeyPrefix = "gbk_"
+	// 前缀后的随机标识长度 (十六进制)，与前缀一起用于查找密钥
+	apiKeyIDLength = 8
+	// 密钥秘密部分的随机字节数
+	apiKeySecretBytes = 32
+	// 使用统计写入数据库的间隔
+	apiKeyUsageFlushInterval = 10 * time.Second
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("无效的API密钥")
+	ErrAPIKeyNotFound = errors.New("API密钥不存在")
+)
+
+// APIKeyInput 创建API密钥
+type APIKeyInput struct {
+	Name        string
+	Permissions []string
+	ExpiresAt   *time.Time // 为空表示永不过期
+}
+
+// apiKeyUsage 尚未写入数据库的使用统计
+type apiKeyUsage struct {
+	count      int64
+	lastUsedAt time.Time
+}
+
+// APIKeyService API密钥管理与认证
+// 未吊销的密钥缓存在内存中，使用统计在内存中累计后定期写入数据库
+type APIKeyService struct {
+	keyRepo     *repository.APIKeyRepository
+	userRepo    *repository.UserRepository
+	roleService *RoleService
+	keys        map[string]*models.APIKey // 前缀 -> 密钥
+	usage       map[uint]*apiKeyUsage
+	mutex       sync.RWMutex
+	usageMutex  sync.Mutex
+	done        chan struct{}
+}
+
+func NewAPIKeyService(keyRepo *repository.APIKeyRepository, userRepo *repository.UserRepository, roleService *RoleService) *APIKeyService {
+	s := &APIKeyService{
+		keyRepo:     keyRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+		keys:        make(map[string]*models.APIKey),
+		usage:       make(map[uint]*apiKeyUsage),
+		done:        make(chan struct{}),
+	}
+
+	keys, err := keyRepo.ListActive()
+	if err != nil {
+		log.Printf("⚠️  加载API密钥失败: %v", err)
+	}
+	for i := range keys {
+		s.keys[keys[i].Prefix] = &keys[i]
+	}
+
+	go s.flushLoop()
+	return s
+}
+
+// CreateKey 为用户创建API密钥，返回密钥记录和明文密钥 (明文只返回这一次)
+// 密钥的权限不能超过用户当前角色的权限
+func (s *APIKeyService) CreateKey(user *models.User, input APIKeyInput) (*models.APIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", errors.New("密钥名称不能为空且不超过100个字符")
+	}
+	if len(input.Permissions) == 0 {
+		return nil, "", errors.New("至少需要指定一个权限")
+	}
+	perms, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, perm := range perms {
+		if !s.roleService.HasPermission(user.Role, perm) {
+			return nil, "", fmt.Errorf("当前角色没有权限: %s", perm)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.New("过期时间必须晚于当前时间")
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, "", errors.New("生成API密钥失败")
+	}
+	key := &models.APIKey{
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     hashAPIKey(prefix + secret),
+		UserID:      user.ID,
+		Permissions: perms,
+		ExpiresAt:   input.ExpiresAt,
+	}
+	if err := s.keyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	s.mutex.Lock()
+	cached := *key
+	s.keys[prefix] = &cached
+	s.mutex.Unlock()
+
+	log.Printf("✓ 用户 %d 创建了API密钥 %s (%s)", user.ID, prefix, name)
+	return key, prefix + secret, nil
+}
+
+// Authenticate 验证明文密钥，返回密钥和所属用户 (所属用户被禁用或删除时密钥失效)
+func (s *APIKeyService) Authenticate(raw string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(raw, APIKeyPrefix) || len(raw) != len(APIKeyPrefix)+apiKeyIDLength+apiKeySecretBytes*2 {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	prefix := raw[:len(APIKeyPrefix)+apiKeyIDLength]
+
+	s.mutex.RLock()
+	cached, exists := s.keys[prefix]
+	var key models.APIKey
+	if exists {
+		key = *cached
+	}
+	s.mutex.RUnlock()
+
+	if !exists || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(raw))) != 1 {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, nil, errors.New("API密钥已过期")
+	}
+	user, err := s.userRepo.FindByID(key.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	s.recordUsage(key.ID, now)
+	return &key, user, nil
+}
+
+// GetKey 根据ID获取API密钥 (含尚未写入数据库的使用统计)
+func (s *APIKeyService) GetKey(id uint) (*models.APIKey, error) {
+	key, err := s.keyRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	s.mergeUsage(key)
+	return key, nil
+}
+
+// ListKeys 获取API密钥列表 (userID为0时返回所有用户的密钥)
+func (s *APIKeyService) ListKeys(current, size int, userID uint) ([]models.APIKey, int64, error) {
+	keys, total, err := s.keyRepo.List((current-1)*size, size, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range keys {
+		s.mergeUsage(&keys[i])
+	}
+	return keys, total, nil
+}
+
+// RevokeKey 吊销API密钥 (立即生效)
+func (s *APIKeyService) RevokeKey(id uint) error {
+	key, err := s.keyRepo.FindByID(id)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	if err := s.keyRepo.Revoke(id, time.Now()); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.keys, key.Prefix)
+	s.mutex.Unlock()
+	return nil
+}
+
+// recordUsage 在内存中累计使用统计
+func (s *APIKeyService) recordUsage(id uint, now time.Time) {
+	s.usageMutex.Lock()
+	defer s.usageMutex.Unlock()
+	usage := s.usage[id]
+	if usage == nil {
+		usage = &apiKeyUsage{}
+		s.usage[id] = usage
+	}
+	usage.count++
+	usage.lastUsedAt = now
+}
+
+// mergeUsage 将尚未写入数据库的使用统计合并到密钥记录
+func (s *APIKeyService) mergeUsage(key *models.APIKey) {
+	s.usageMutex.Lock()
+	defer s.usageMutex.Unlock()
+	if usage := s.usage[key.ID]; usage != nil {
+		key.RequestCount += usage.count
+		lastUsedAt := usage.lastUsedAt
+		key.LastUsedAt = &lastUsedAt
+	}
+}
+
+// FlushUsage 将累计的使用统计写入数据库
+func (s *APIKeyService) FlushUsage() {
+	s.usageMutex.Lock()
+	pending := s.usage
+	s.usage = make(map[uint]*apiKeyUsage)
+	s.usageMutex.Unlock()
+
+	for id, usage := range pending {
+		if err := s.keyRepo.AddUsage(id, usage.count, usage.lastUsedAt); err != nil {
+			log.Printf("⚠️  保存API密钥 %d 的使用统计失败: %v", id, err)
+		}
+	}
+}
+
+// Close 停止定期刷新协程，并写入尚未保存的使用统计
+func (s *APIKeyService) Close() {
+	close(s.done)
+	s.FlushUsage()
+}
+
+func (s *APIKeyService) flushLoop() {
+	ticker := time.NewTicker(apiKeyUsageFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.FlushUsage()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// generateAPIKey 生成随机的密钥前缀和秘密部分
+func generateAPIKey() (string, string, error) {
+	buf := make([]byte, apiKeyIDLength/2+apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix := APIKeyPrefix + hex.EncodeToString(buf[:apiKeyIDLength/2])
+	return prefix, hex.EncodeToString(buf[apiKeyIDLength/2:]), nil
+}
+
+// hashAPIKey 计算密钥的SHA-256哈希 (密钥为高熵随机值，无需慢哈希)
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db, alice := newTestDB(t)
+	if err := db.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatal(err)
+	}
+	userRepo := repository.NewUserRepository(db)
+	keyRepo := repository.NewAPIKeyRepository(db)
+	s := NewAPIKeyService(keyRepo, userRepo, NewRoleService(repository.NewRoleRepository(db), userRepo))
+
+	// 权限不能超过角色的权限 (alice 为普通用户)
+	if _, _, err := s.CreateKey(alice, APIKeyInput{Name: "ops", Permissions: []string{models.PermTopologyWrite}}); err == nil {
+		t.Error("超出角色权限的密钥应创建失败")
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := s.CreateKey(alice, APIKeyInput{Name: "old", Permissions: []string{models.PermTasksSubmit}, ExpiresAt: &past}); err == nil {
+		t.Error("过期时间早于当前时间的密钥应创建失败")
+	}
+
+	key, raw, err := s.CreateKey(alice, APIKeyInput{Name: "loadgen", Permissions: []string{models.PermTasksSubmit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := keyRepo.FindByID(key.ID)
+	if stored.KeyHash == raw || stored.KeyHash != hashAPIKey(raw) {
+		t.Error("数据库中只应保存密钥哈希")
+	}
+
+	got, user, err := s.Authenticate(raw)
+	if err != nil || user.ID != alice.ID || !got.HasPermission(models.PermTasksSubmit) || got.HasPermission(models.PermTasksRead) {
+		t.Fatalf("密钥认证失败: %v", err)
+	}
+	tampered := []byte(raw)
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err := s.Authenticate(string(tampered)); err == nil {
+		t.Error("错误的密钥应认证失败")
+	}
+	s.Authenticate(raw)
+
+	// 使用统计在刷新前也可见，刷新后写入数据库
+	if listed, _ := s.GetKey(key.ID); listed.RequestCount != 2 || listed.LastUsedAt == nil {
+		t.Errorf("期望请求次数为2, got %d", listed.RequestCount)
+	}
+	s.FlushUsage()
+	if stored, _ := keyRepo.FindByID(key.ID); stored.RequestCount != 2 {
+		t.Errorf("刷新后数据库中的请求次数应为2, got %d", stored.RequestCount)
+	}
+
+	if err := s.RevokeKey(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Authenticate(raw); err == nil {
+		t.Error("吊销后的密钥应认证失败")
+	}
+}
+
+// TestAPIKeyCloseFlushesUsage 测试关闭服务时写入尚未刷新的使用统计
+func TestAPIKeyCloseFlushesUsage(t *testing.T) {
+	db, alice := newTestDB(t)
+	if err := db.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatal(err)
+	}
+	userRepo := repository.NewUserRepository(db)
+	keyRepo := repository.NewAPIKeyRepository(db)
+	s := NewAPIKeyService(keyRepo, userRepo, NewRoleService(repository.NewRoleRepository(db), userRepo))
+
+	key, raw, err := s.CreateKey(alice, APIKeyInput{Name: "ci", Permissions: []string{models.PermTasksRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Authenticate(raw); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if stored, _ := keyRepo.FindByID(key.ID); stored.RequestCount != 1 {
+		t.Errorf("关闭后数据库中的请求次数应为1, got %d", stored.RequestCount)
+	}
+}
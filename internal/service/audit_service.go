@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"log"
+	"reflect"
+	"sort"
+)
+
+// 计算差异时忽略的字段 (每次更新都会变化)
+var auditIgnoredFields = map[string]bool{"created_at": true, "updated_at": true}
+
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record 保存审计记录 (失败时只记录日志，不影响请求结果)
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if err := s.auditRepo.Create(entry); err != nil {
+		log.Printf("⚠️  保存审计记录失败 (%s %s): %v", entry.Method, entry.Path, err)
+	}
+}
+
+// ListLogs 按条件分页查询审计记录
+func (s *AuditService) ListLogs(current, size int, filter repository.AuditFilter) ([]models.AuditLog, int64, error) {
+	return s.auditRepo.List((current-1)*size, size, filter)
+}
+
+// Diff 计算实体变更前后的字段差异 (按JSON字段名比较，before或after为nil时表示创建或删除)
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	for key := range beforeFields {
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, exists := beforeFields[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make(map[string]models.FieldChange)
+	for _, key := range keys {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if !reflect.DeepEqual(beforeFields[key], afterFields[key]) {
+			changes[key] = models.FieldChange{Before: beforeFields[key], After: afterFields[key]}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changes)
+}
+
+// jsonFields 将实体转换为 JSON 字段映射
+func jsonFields(entity interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if entity == nil || reflect.ValueOf(entity).Kind() == reflect.Ptr && reflect.ValueOf(entity).IsNil() {
+		return fields, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"encoding/json"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := &models.Node{ID: 1, Name: "uav-1", X: 10, Y: 20, UpdatedAt: time.Now()}
+	after := &models.Node{ID: 1, Name: "uav-1", X: 15, Y: 20, UpdatedAt: time.Now().Add(time.Second)}
+
+	data, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes map[string]models.FieldChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes["x"].Before != 10.0 || changes["x"].After != 15.0 {
+		t.Errorf("只应记录x的变化 (忽略更新时间): %s", data)
+	}
+
+	// 删除时after为nil，记录所有字段的原值
+	var deleted *models.Node
+	if data, err := Diff(before, deleted); err != nil || len(data) == 0 {
+		t.Errorf("删除操作应记录原值: %s, %v", data, err)
+	}
+	if data, _ := Diff(before, before); data != nil {
+		t.Errorf("没有变化时不应记录差异: %s", data)
+	}
+}
+
+func TestAuditListFilter(t *testing.T) {
+	db, _ := newTestDB(t)
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewAuditService(repository.NewAuditRepository(db))
+	s.Record(&models.AuditLog{UserID: 1, Method: "PUT", Route: "/api/v1/network/nodes/:id", TargetID: "3", Success: true})
+	s.Record(&models.AuditLog{UserID: 2, Method: "POST", Route: "/api/v1/alarms/:id/resolve", TargetID: "7", Success: false})
+
+	failed := false
+	logs, total, err := s.ListLogs(1, 10, repository.AuditFilter{Route: "alarms", Success: &failed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(logs) != 1 || logs[0].TargetID != "7" {
+		t.Errorf("按路由和结果过滤失败: total=%d", total)
+	}
+}
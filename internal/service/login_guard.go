@@ -0,0 +1,173 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginPolicy 登录失败锁定策略
+type LoginPolicy struct {
+	MaxFailures        int           // 同一客户端IP连续失败多少次后锁定该IP对账号的登录 (0表示不锁定)
+	MaxAccountFailures int           // 所有客户端IP合计连续失败多少次后锁定账号 (0表示不锁定)
+	Lockout            time.Duration // 首次锁定时长
+	MaxLockout         time.Duration // 最长锁定时长 (每次再锁定时长翻倍，不超过该值)
+}
+
+// DefaultLoginPolicy 默认策略: 同一IP连续失败5次、所有IP合计失败20次锁定30秒，
+// 之后每次失败锁定时长翻倍，最长1小时
+func DefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{MaxFailures: 5, MaxAccountFailures: 20, Lockout: 30 * time.Second, MaxLockout: time.Hour}
+}
+
+// loginAttempts 连续登录失败记录 (账号在某个客户端IP上，或账号在所有IP上合计)
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// loginKey 登录失败记录的键: 用户名 (不区分大小写和首尾空白) 和客户端IP
+type loginKey struct {
+	username string
+	ip       string
+}
+
+func newLoginKey(username, ip string) loginKey {
+	return loginKey{username: normalizeLoginName(username), ip: ip}
+}
+
+// normalizeLoginName 用户名不区分大小写和首尾空白
+func normalizeLoginName(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// LoginGuard 登录暴力破解防护
+// 按用户名和客户端IP记录连续失败次数 (不存在的用户名同样处理，避免泄露账号是否存在)，
+// 达到阈值后锁定该IP对账号的登录，锁定期间再失败则锁定时长指数增长；
+// 其他IP不受影响，攻击者无法通过反复失败锁定他人的账号。
+// 同时按用户名合计所有IP的失败次数，达到更高的阈值后锁定账号，防止轮换IP的分布式猜测
+type LoginGuard struct {
+	policy   LoginPolicy
+	attempts map[loginKey]*loginAttempts
+	accounts map[string]*loginAttempts // 用户名 -> 所有IP合计的失败记录
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+func NewLoginGuard(policy LoginPolicy) *LoginGuard {
+	return &LoginGuard{
+		policy:   policy,
+		attempts: make(map[loginKey]*loginAttempts),
+		accounts: make(map[string]*loginAttempts),
+		now:      time.Now,
+	}
+}
+
+// Locked 返回账号在该客户端IP上剩余的锁定时长 (未锁定时为0)
+func (g *LoginGuard) Locked(username, ip string) time.Duration {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	var wait time.Duration
+	if a := g.attempts[newLoginKey(username, ip)]; a != nil {
+		wait = max(wait, a.lockedUntil.Sub(now))
+	}
+	if a := g.accounts[normalizeLoginName(username)]; a != nil {
+		wait = max(wait, a.lockedUntil.Sub(now))
+	}
+	return wait
+}
+
+// Fail 记录一次登录失败，返回本次失败导致的锁定时长 (未锁定时为0)
+func (g *LoginGuard) Fail(username, ip string) time.Duration {
+	if g.policy.MaxFailures <= 0 && g.policy.MaxAccountFailures <= 0 {
+		return 0
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	g.sweepLocked(now)
+
+	var lockout time.Duration
+	if g.policy.MaxFailures > 0 {
+		key := newLoginKey(username, ip)
+		a := g.attempts[key]
+		if a == nil {
+			a = &loginAttempts{}
+			g.attempts[key] = a
+		}
+		lockout = g.record(a, g.policy.MaxFailures, now)
+	}
+	if g.policy.MaxAccountFailures > 0 {
+		name := normalizeLoginName(username)
+		a := g.accounts[name]
+		if a == nil {
+			a = &loginAttempts{}
+			g.accounts[name] = a
+		}
+		lockout = max(lockout, g.record(a, g.policy.MaxAccountFailures, now))
+	}
+	return lockout
+}
+
+// record 累加一次失败，达到阈值后按超出次数指数增长锁定时长，返回本次锁定时长
+func (g *LoginGuard) record(a *loginAttempts, threshold int, now time.Time) time.Duration {
+	a.failures++
+	a.lastFailure = now
+	if a.failures < threshold {
+		return 0
+	}
+
+	lockout := g.policy.Lockout
+	for i := threshold; i < a.failures && lockout < g.policy.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > g.policy.MaxLockout {
+		lockout = g.policy.MaxLockout
+	}
+	a.lockedUntil = now.Add(lockout)
+	return lockout
+}
+
+// Succeed 登录成功，清除该客户端IP的失败记录
+// 账号合计的失败记录保留到过期，避免攻击者穿插一次成功登录来重置计数
+func (g *LoginGuard) Succeed(username, ip string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.attempts, newLoginKey(username, ip))
+}
+
+// Unlock 解除账号在所有客户端IP上的锁定 (管理员重置密码等场景)
+func (g *LoginGuard) Unlock(username string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	name := normalizeLoginName(username)
+	for key := range g.attempts {
+		if key.username == name {
+			delete(g.attempts, key)
+		}
+	}
+	delete(g.accounts, name)
+}
+
+// sweepLocked 清理长时间没有失败的记录 (超过最长锁定时长后重新计数)
+func (g *LoginGuard) sweepLocked(now time.Time) {
+	for key, a := range g.attempts {
+		if g.expired(a, now) {
+			delete(g.attempts, key)
+		}
+	}
+	for name, a := range g.accounts {
+		if g.expired(a, now) {
+			delete(g.accounts, name)
+		}
+	}
+}
+
+func (g *LoginGuard) expired(a *loginAttempts, now time.Time) bool {
+	return now.After(a.lockedUntil) && now.Sub(a.lastFailure) > g.policy.MaxLockout
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginGuard(t *testing.T) {
+	now := time.Unix(1000, 0)
+	g := NewLoginGuard(LoginPolicy{MaxFailures: 3, Lockout: 10 * time.Second, MaxLockout: 35 * time.Second})
+	g.now = func() time.Time { return now }
+	const ip = "10.0.0.1"
+
+	for i := 0; i < 2; i++ {
+		if lockout := g.Fail("alice", ip); lockout != 0 {
+			t.Fatalf("第%d次失败不应锁定", i+1)
+		}
+	}
+	if lockout := g.Fail("Alice ", ip); lockout != 10*time.Second {
+		t.Fatalf("达到阈值应锁定10秒 (用户名不区分大小写), got %v", lockout)
+	}
+	if wait := g.Locked("alice", ip); wait != 10*time.Second {
+		t.Errorf("锁定剩余时长应为10秒, got %v", wait)
+	}
+
+	// 锁定时长指数增长，不超过最长锁定时长
+	now = now.Add(11 * time.Second)
+	if g.Locked("alice", ip) != 0 {
+		t.Error("锁定到期后应解除")
+	}
+	if lockout := g.Fail("alice", ip); lockout != 20*time.Second {
+		t.Errorf("再次失败应锁定20秒, got %v", lockout)
+	}
+	if lockout := g.Fail("alice", ip); lockout != 35*time.Second {
+		t.Errorf("锁定时长不应超过35秒, got %v", lockout)
+	}
+
+	// 锁定只针对失败的客户端IP，其他IP仍可登录
+	if g.Locked("alice", "10.0.0.2") != 0 {
+		t.Error("其他IP不应被锁定")
+	}
+
+	g.Succeed("alice", ip)
+	if g.Locked("alice", ip) != 0 || g.Fail("alice", ip) != 0 {
+		t.Error("登录成功后应清除失败记录")
+	}
+
+	// 管理员解锁清除所有IP上的记录
+	g.Fail("alice", ip)
+	g.Fail("alice", ip)
+	g.Fail("alice", "10.0.0.2")
+	g.Unlock("Alice")
+	if g.Fail("alice", ip) != 0 || len(g.attempts) != 1 {
+		t.Error("解锁后应清除该账号所有IP的失败记录")
+	}
+}
+
+// TestLoginGuardAccountLockout 测试轮换客户端IP时按账号合计的失败次数锁定
+func TestLoginGuardAccountLockout(t *testing.T) {
+	now := time.Unix(1000, 0)
+	g := NewLoginGuard(LoginPolicy{MaxFailures: 3, MaxAccountFailures: 5, Lockout: 10 * time.Second, MaxLockout: 35 * time.Second})
+	g.now = func() time.Time { return now }
+	ips := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}
+
+	// 每个IP只失败一次，不会触发单IP锁定
+	for i, ip := range ips[:4] {
+		if lockout := g.Fail("alice", ip); lockout != 0 {
+			t.Fatalf("第%d次失败不应锁定", i+1)
+		}
+	}
+	if lockout := g.Fail("Alice", ips[4]); lockout != 10*time.Second {
+		t.Fatalf("所有IP合计达到阈值应锁定账号10秒, got %v", lockout)
+	}
+	if wait := g.Locked("alice", ips[5]); wait != 10*time.Second {
+		t.Errorf("账号锁定对未失败过的IP同样生效, got %v", wait)
+	}
+	if g.Locked("bob", ips[0]) != 0 {
+		t.Error("其他账号不应被锁定")
+	}
+
+	// 锁定到期后继续从新IP失败，锁定时长指数增长
+	now = now.Add(11 * time.Second)
+	if g.Locked("alice", ips[5]) != 0 {
+		t.Error("锁定到期后应解除")
+	}
+	if lockout := g.Fail("alice", ips[5]); lockout != 20*time.Second {
+		t.Errorf("再次失败应锁定20秒, got %v", lockout)
+	}
+
+	// 某个IP登录成功不重置账号合计的失败次数
+	g.Succeed("alice", ips[0])
+	if g.Locked("alice", ips[0]) == 0 {
+		t.Error("登录成功不应解除账号锁定")
+	}
+
+	g.Unlock("alice")
+	if g.Locked("alice", ips[5]) != 0 || g.Fail("alice", ips[5]) != 0 {
+		t.Error("管理员解锁后应清除账号合计的失败记录")
+	}
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-backend/internal/models"
 	"go-backend/internal/repository"
+	"go-backend/pkg/utils"
+	"math"
 )
 
 type NetworkService struct {
@@ -28,8 +31,36 @@ func (s *NetworkService) GetNode(id uint) (*models.Node, error) {
 	return s.nodeRepo.GetByID(id)
 }
 
+// ListOwnedUserNodeIDs 获取账号名下的用户设备节点ID (用于限定任务访问范围)
+func (s *NetworkService) ListOwnedUserNodeIDs(userID uint) ([]uint, error) {
+	return s.nodeRepo.ListUserNodeIDsByOwner(userID)
+}
+
+// validateNodeProperties 校验节点属性
+// 覆盖半径需为正数或 "400m"/"5km" 形式，能耗预算需为非负数 (单位W)
+func (s *NetworkService) validateNodeProperties(node *models.Node) error {
+	if node.Properties == nil {
+		return nil
+	}
+	if radius, exists := node.Properties["coverage_radius"]; exists {
+		if _, ok := utils.ParseDistance(radius); !ok {
+			return errors.New("无效的覆盖半径 coverage_radius")
+		}
+	}
+	if _, exists := node.Properties["energy_budget"]; exists {
+		if budget, ok := utils.PropertyFloat(node.Properties, "energy_budget"); !ok || budget < 0 {
+			return errors.New("无效的能耗预算 energy_budget")
+		}
+	}
+	return nil
+}
+
 // CreateNode 创建节点
 func (s *NetworkService) CreateNode(node *models.Node) error {
+	if err := s.validateNodeProperties(node); err != nil {
+		return err
+	}
+
 	// 如果设置了设备ID，检查是否重复
 	if node.DeviceID != nil {
 		existingNode, err := s.nodeRepo.GetByDeviceID(*node.DeviceID)
@@ -48,6 +79,10 @@ func (s *NetworkService) UpdateNode(node *models.Node) error {
 		return errors.New("节点不存在")
 	}
 
+	if err := s.validateNodeProperties(node); err != nil {
+		return err
+	}
+
 	// 如果设备ID发生变化且不为空，检查新设备ID是否已被其他节点使用
 	if node.DeviceID != nil && (existingNode.DeviceID == nil || *existingNode.DeviceID != *node.DeviceID) {
 		nodeWithDevice, err := s.nodeRepo.GetByDeviceID(*node.DeviceID)
@@ -56,6 +91,11 @@ func (s *NetworkService) UpdateNode(node *models.Node) error {
 		}
 	}
 
+	// 移动通信设备后，已有的通信设备链路不能超过最大距离
+	if err := s.validateMovedLinks([]models.Node{*node}); err != nil {
+		return err
+	}
+
 	return s.nodeRepo.Update(node)
 }
 
@@ -85,6 +125,50 @@ func (s *NetworkService) GetLink(id uint) (*models.Link, error) {
 	return s.linkRepo.GetByID(id)
 }
 
+// Radius UAV之间建立链路的最大距离，单位：米 (不同于用户接入的覆盖半径)
+const Radius = 1000
+
+// validateLinkDistance 校验链路两端距离，通信设备之间的链路不能超过 Radius
+func (s *NetworkService) validateLinkDistance(source, target *models.Node) error {
+	if source.NodeType != models.NodeTypeComm || target.NodeType != models.NodeTypeComm {
+		return nil
+	}
+	if distance := math.Hypot(source.X-target.X, source.Y-target.Y); distance > Radius {
+		return fmt.Errorf("通信设备之间的链路距离 %.0fm 超过最大值 %dm", distance, Radius)
+	}
+	return nil
+}
+
+// validateMovedLinks 按节点的新位置校验与其相连的链路距离
+func (s *NetworkService) validateMovedLinks(moved []models.Node) error {
+	nodes := make(map[uint]*models.Node, len(moved))
+	for i := range moved {
+		nodes[moved[i].ID] = &moved[i]
+	}
+
+	links, err := s.linkRepo.List(nil)
+	if err != nil {
+		return err
+	}
+	for i := range links {
+		source, sourceMoved := nodes[links[i].SourceID]
+		target, targetMoved := nodes[links[i].TargetID]
+		if !sourceMoved && !targetMoved {
+			continue
+		}
+		if !sourceMoved {
+			source = &links[i].Source
+		}
+		if !targetMoved {
+			target = &links[i].Target
+		}
+		if err := s.validateLinkDistance(source, target); err != nil {
+			return fmt.Errorf("节点 %d 与 %d 之间的链路: %w", source.ID, target.ID, err)
+		}
+	}
+	return nil
+}
+
 // CreateLink 创建链路
 func (s *NetworkService) CreateLink(link *models.Link) error {
 	// 检查链路名称是否已存在
@@ -93,14 +177,17 @@ func (s *NetworkService) CreateLink(link *models.Link) error {
 	}
 
 	// 检查源节点和目标节点是否存在
-	_, err := s.nodeRepo.GetByID(link.SourceID)
+	source, err := s.nodeRepo.GetByID(link.SourceID)
 	if err != nil {
 		return errors.New("源节点不存在")
 	}
-	_, err = s.nodeRepo.GetByID(link.TargetID)
+	target, err := s.nodeRepo.GetByID(link.TargetID)
 	if err != nil {
 		return errors.New("目标节点不存在")
 	}
+	if err := s.validateLinkDistance(source, target); err != nil {
+		return err
+	}
 
 	// 检查是否已存在相同的链路
 	existingLink, _ := s.linkRepo.GetByNodes(link.SourceID, link.TargetID)
@@ -114,21 +201,21 @@ func (s *NetworkService) CreateLink(link *models.Link) error {
 // UpdateLink 更新链路
 func (s *NetworkService) UpdateLink(link *models.Link) error {
 	// 检查链路是否存在
-	existing, err := s.linkRepo.GetByID(link.ID)
-	if err != nil {
+	if _, err := s.linkRepo.GetByID(link.ID); err != nil {
 		return errors.New("链路不存在")
 	}
 
-	// 如果更改了源节点或目标节点，需要验证节点是否存在
-	if existing.SourceID != link.SourceID {
-		if _, err := s.nodeRepo.GetByID(link.SourceID); err != nil {
-			return errors.New("源节点不存在")
-		}
+	// 验证源节点和目标节点是否存在，并校验链路距离
+	source, err := s.nodeRepo.GetByID(link.SourceID)
+	if err != nil {
+		return errors.New("源节点不存在")
 	}
-	if existing.TargetID != link.TargetID {
-		if _, err := s.nodeRepo.GetByID(link.TargetID); err != nil {
-			return errors.New("目标节点不存在")
-		}
+	target, err := s.nodeRepo.GetByID(link.TargetID)
+	if err != nil {
+		return errors.New("目标节点不存在")
+	}
+	if err := s.validateLinkDistance(source, target); err != nil {
+		return err
 	}
 
 	return s.linkRepo.Update(link)
@@ -207,16 +294,28 @@ func (s *NetworkService) BatchUpdateNodesPosition(nodes []models.Node) error {
 		}
 	}
 
+	// 按新位置校验已有链路的距离
+	moved := make([]models.Node, 0, len(existingNodes))
+	positions := make(map[uint]models.Node, len(nodes))
+	for _, node := range nodes {
+		positions[node.ID] = node
+	}
+	for _, node := range existingNodes {
+		node.X, node.Y = positions[node.ID].X, positions[node.ID].Y
+		moved = append(moved, node)
+	}
+	if err := s.validateMovedLinks(moved); err != nil {
+		return err
+	}
+
 	// 执行批量更新
 	return s.nodeRepo.BatchUpdatePositions(nodes)
 }
 
 // 依据拓扑连接规则，对网络结构进行更新：
-// 1. 用户节点只连接距离最近的UAV
+// 1. 用户节点只连接覆盖范围内距离最近的UAV (覆盖半径见节点属性 coverage_radius)
 // 2. UAV只连接距离在给定范围内的其他UAV
 
-const Radius = 1000
-
 // func (s *NetworkService) RefreshNetworkTopo(nodes []models.Node) error {
 // 	links, err := s.linkRepo.List(nil)
 // 	if err != nil {
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+)
+
+func TestLinkDistanceLimit(t *testing.T) {
+	db, _ := newTestDB(t)
+	if err := db.AutoMigrate(&models.Device{}, &models.Node{}, &models.Link{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewNetworkService(repository.NewNodeRepository(db), repository.NewLinkRepository(db))
+
+	near := &models.Node{Name: "uav-1", NodeType: models.NodeTypeComm}
+	far := &models.Node{Name: "uav-2", NodeType: models.NodeTypeComm, X: Radius + 1}
+	mid := &models.Node{Name: "uav-3", NodeType: models.NodeTypeComm, X: 600, Y: 800}
+	user := &models.Node{Name: "user-1", NodeType: models.NodeTypeUser, X: 2 * Radius}
+	for _, node := range []*models.Node{near, far, mid, user} {
+		if err := s.CreateNode(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.CreateLink(&models.Link{Name: "too-far", SourceID: near.ID, TargetID: far.ID, Status: models.LinkStatusUp}); err == nil {
+		t.Error("超过最大距离的UAV链路应被拒绝")
+	}
+	link := &models.Link{Name: "edge", SourceID: near.ID, TargetID: mid.ID, Status: models.LinkStatusUp}
+	if err := s.CreateLink(link); err != nil {
+		t.Fatalf("恰好位于最大距离的链路应允许创建: %v", err)
+	}
+	if err := s.CreateLink(&models.Link{Name: "access", SourceID: far.ID, TargetID: user.ID, Status: models.LinkStatusUp}); err != nil {
+		t.Errorf("用户接入链路不受UAV距离限制: %v", err)
+	}
+
+	link.TargetID = far.ID
+	if err := s.UpdateLink(link); err == nil {
+		t.Error("更新后超过最大距离的UAV链路应被拒绝")
+	}
+
+	// 移动节点使已有链路超过最大距离时拒绝移动
+	moved := *mid
+	moved.X += 1
+	if err := s.UpdateNode(&moved); err == nil {
+		t.Error("移动后已有UAV链路超过最大距离，应拒绝更新节点")
+	}
+	if err := s.BatchUpdateNodesPosition([]models.Node{{ID: near.ID, X: -1}}); err == nil {
+		t.Error("批量移动后已有UAV链路超过最大距离，应被拒绝")
+	}
+	if err := s.BatchUpdateNodesPosition([]models.Node{{ID: near.ID, X: 1}, {ID: mid.ID, X: 601, Y: 800}}); err != nil {
+		t.Errorf("链路两端一起移动且距离不变时应允许: %v", err)
+	}
+	if err := s.BatchUpdateNodesPosition([]models.Node{{ID: user.ID, X: 5000}}); err != nil {
+		t.Errorf("用户接入链路不受UAV距离限制: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/pkg/notify"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrChannelNotFound = errors.New("通知渠道不存在")
+
+const (
+	defaultNotifyBackoff = time.Second      // 默认首次重试间隔
+	defaultNotifyTimeout = 10 * time.Second // 默认单次发送超时
+	maxNotifyBackoff     = time.Minute      // 重试间隔上限
+	notifyQueueSize      = 256              // 每个渠道的待发送队列长度 (队列满时丢弃并记录失败)
+)
+
+// ChannelInfo 通知渠道信息 (不含密钥)
+type ChannelInfo struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Target       string   `json:"target"`
+	EventTypes   []string `json:"event_types"`
+	Severities   []string `json:"severities"`
+	SendResolved bool     `json:"send_resolved"`
+	Retries      int      `json:"retries"`
+}
+
+// notificationChannel 已创建的通知渠道及其投递策略
+type notificationChannel struct {
+	config  notify.ChannelConfig
+	channel notify.Channel
+	backoff time.Duration
+	timeout time.Duration
+	queue   chan *notify.Notification // 由该渠道的投递协程按顺序发送
+}
+
+// NotificationService 告警通知
+// 告警产生和解决时按各渠道的路由规则放入渠道的有界队列，每个渠道一个投递协程，
+// 失败按指数退避重试，每次投递的结果记录到数据库
+type NotificationService struct {
+	deliveryRepo *repository.AlarmDeliveryRepository
+	channels     []*notificationChannel
+	pending      sync.WaitGroup // 已入队未投递完成的通知
+	workers      sync.WaitGroup // 各渠道的投递协程
+	mutex        sync.RWMutex   // 保护closed与入队
+	closed       bool
+}
+
+// NewNotificationService 按配置创建通知渠道 (配置无效的渠道记录日志后跳过)
+func NewNotificationService(deliveryRepo *repository.AlarmDeliveryRepository, configs []notify.ChannelConfig) *NotificationService {
+	s := &NotificationService{deliveryRepo: deliveryRepo}
+	seen := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		if seen[config.Name] {
+			log.Printf("⚠️  通知渠道名称重复，已跳过: %s", config.Name)
+			continue
+		}
+		channel, err := notify.New(config)
+		if err != nil {
+			log.Printf("⚠️  通知渠道配置无效，已跳过: %v", err)
+			continue
+		}
+		seen[config.Name] = true
+		s.channels = append(s.channels, &notificationChannel{
+			config:  config,
+			channel: channel,
+			backoff: parseNotifyDuration(config.Backoff, defaultNotifyBackoff),
+			timeout: parseNotifyDuration(config.Timeout, defaultNotifyTimeout),
+			queue:   make(chan *notify.Notification, notifyQueueSize),
+		})
+		log.Printf("✓ 已启用告警通知渠道: %s (%s)", config.Name, config.Type)
+	}
+	for _, ch := range s.channels {
+		s.workers.Add(1)
+		go s.worker(ch)
+	}
+	return s
+}
+
+// worker 按顺序投递渠道队列中的通知，队列关闭并清空后退出
+func (s *NotificationService) worker(ch *notificationChannel) {
+	defer s.workers.Done()
+	for n := range ch.queue {
+		s.deliver(ch, n, ch.config.Retries)
+		s.pending.Done()
+	}
+}
+
+func parseNotifyDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  无效的通知时长 %q，使用默认值 %s", value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// Channels 获取所有通知渠道
+func (s *NotificationService) Channels() []ChannelInfo {
+	infos := make([]ChannelInfo, 0, len(s.channels))
+	for _, ch := range s.channels {
+		infos = append(infos, ChannelInfo{
+			Name:         ch.config.Name,
+			Type:         ch.config.Type,
+			Target:       ch.config.Target(),
+			EventTypes:   ch.config.EventTypes,
+			Severities:   ch.config.Severities,
+			SendResolved: ch.config.SendResolved,
+			Retries:      ch.config.Retries,
+		})
+	}
+	return infos
+}
+
+// NotifyAlarm 将告警事件放入匹配路由规则的所有渠道的队列 (不阻塞告警处理)
+func (s *NotificationService) NotifyAlarm(alarm *models.Alarm, event string) {
+	n := &notify.Notification{
+		Event:       event,
+		AlarmID:     alarm.ID,
+		Name:        alarm.Name,
+		EventType:   string(alarm.EventType),
+		Severity:    string(alarm.Severity),
+		Status:      string(alarm.Status),
+		Description: alarm.Description,
+		Timestamp:   time.Now(),
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		log.Printf("⚠️  通知服务已关闭，丢弃告警通知: 告警=%d", n.AlarmID)
+		return
+	}
+	for _, ch := range s.channels {
+		if !ch.config.Matches(n) {
+			continue
+		}
+		s.pending.Add(1)
+		select {
+		case ch.queue <- n:
+		default:
+			s.pending.Done()
+			s.drop(ch, n)
+		}
+	}
+}
+
+// drop 渠道队列已满时丢弃通知并记录失败的投递
+func (s *NotificationService) drop(ch *notificationChannel, n *notify.Notification) {
+	delivery := &models.AlarmDelivery{
+		AlarmID:     n.AlarmID,
+		Channel:     ch.config.Name,
+		ChannelType: ch.config.Type,
+		Event:       n.Event,
+		Error:       "待发送队列已满，通知已丢弃",
+	}
+	log.Printf("❌ 告警通知队列已满: 渠道=%s, 告警=%d", delivery.Channel, delivery.AlarmID)
+	if err := s.deliveryRepo.Create(delivery); err != nil {
+		log.Printf("❌ 保存通知投递记录失败: %v", err)
+	}
+}
+
+// TestChannel 向指定渠道同步发送一条测试通知并记录投递结果 (不重试，最长等待一次发送超时)
+func (s *NotificationService) TestChannel(name string) (*models.AlarmDelivery, error) {
+	for _, ch := range s.channels {
+		if ch.config.Name != name {
+			continue
+		}
+		return s.deliver(ch, &notify.Notification{
+			Event:       notify.EventTest,
+			Name:        "测试通知",
+			Severity:    string(models.AlarmSeverityInfo),
+			Description: "这是一条测试通知，用于验证通知渠道配置",
+			Timestamp:   time.Now(),
+		}, 0), nil
+	}
+	return nil, ErrChannelNotFound
+}
+
+// Wait 等待所有已入队的通知投递完成
+func (s *NotificationService) Wait() {
+	s.pending.Wait()
+}
+
+// Shutdown 停止接收新通知，等待队列中的通知投递完成 (ctx到期时放弃等待)
+func (s *NotificationService) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		for _, ch := range s.channels {
+			close(ch.queue)
+		}
+	}
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListDeliveries 分页查询投递记录
+func (s *NotificationService) ListDeliveries(current, size int, filter repository.AlarmDeliveryFilter) ([]models.AlarmDelivery, int64, error) {
+	return s.deliveryRepo.List((current-1)*size, size, filter)
+}
+
+// deliver 发送通知，失败时按指数退避重试retries次，完成后记录投递结果
+func (s *NotificationService) deliver(ch *notificationChannel, n *notify.Notification, retries int) *models.AlarmDelivery {
+	start := time.Now()
+	delivery := &models.AlarmDelivery{
+		AlarmID:     n.AlarmID,
+		Channel:     ch.config.Name,
+		ChannelType: ch.config.Type,
+		Event:       n.Event,
+	}
+
+	backoff := ch.backoff
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxNotifyBackoff {
+				backoff = maxNotifyBackoff
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ch.timeout)
+		err := ch.channel.Send(ctx, n)
+		cancel()
+		delivery.Attempts++
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+	}
+	delivery.Duration = time.Since(start).Milliseconds()
+
+	if !delivery.Success {
+		log.Printf("❌ 告警通知发送失败: 渠道=%s, 告警=%d, 尝试%d次: %s",
+			delivery.Channel, delivery.AlarmID, delivery.Attempts, delivery.Error)
+	}
+	if err := s.deliveryRepo.Create(delivery); err != nil {
+		log.Printf("❌ 保存通知投递记录失败: %v", err)
+	}
+	return delivery
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/pkg/notify"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotificationDelivery(t *testing.T) {
+	db, _ := newTestDB(t)
+	if err := db.AutoMigrate(&models.Alarm{}, &models.AlarmNote{}, &models.AlarmDelivery{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟webhook接收方: 前两次返回503
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	deliveryRepo := repository.NewAlarmDeliveryRepository(db)
+	notifier := NewNotificationService(deliveryRepo, []notify.ChannelConfig{
+		{Name: "hook", Type: notify.TypeWebhook, URL: server.URL, Secret: "x", Retries: 3, Backoff: "1ms"},
+		{Name: "critical-only", Type: notify.TypeWebhook, URL: server.URL, Severities: []string{"critical"}},
+		{Name: "invalid", Type: notify.TypeWebhook}, // 缺少url，跳过
+	})
+	if len(notifier.Channels()) != 2 {
+		t.Fatalf("应只创建2个有效渠道: %+v", notifier.Channels())
+	}
+
+	alarms := NewAlarmService(repository.NewAlarmRepository(db), repository.NewUserRepository(db))
+	alarms.SetNotifier(notifier)
+	alarm := &models.Alarm{Name: "延迟过高", EventType: models.AlarmEventPerformance, Severity: models.AlarmSeverityWarning}
+	if err := alarms.CreateAlarm(alarm); err != nil {
+		t.Fatal(err)
+	}
+	// 未开启send_resolved，解决时不通知
+	if err := alarms.ResolveAlarm(alarm.ID, SystemActor); err != nil {
+		t.Fatal(err)
+	}
+	notifier.Wait()
+
+	deliveries, total, err := notifier.ListDeliveries(1, 10, repository.AlarmDeliveryFilter{AlarmID: alarm.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("应只投递到hook渠道一次: %+v", deliveries)
+	}
+	if d := deliveries[0]; d.Channel != "hook" || !d.Success || d.Attempts != 3 || d.Event != notify.EventAlarmCreated {
+		t.Errorf("投递记录不正确: %+v", d)
+	}
+
+	// 测试通知忽略路由规则且不重试，失败即记录
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	delivery, err := notifier.TestChannel("hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Success || delivery.Attempts != 1 || delivery.Error == "" {
+		t.Errorf("测试通知应失败且只发送一次: %+v", delivery)
+	}
+	if _, err := notifier.TestChannel("missing"); err != ErrChannelNotFound {
+		t.Errorf("不存在的渠道应返回ErrChannelNotFound: %v", err)
+	}
+
+	// 关闭时等待队列中的通知投递完成，之后的通知被丢弃
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	second := &models.Alarm{Name: "队列积压", EventType: models.AlarmEventPerformance, Severity: models.AlarmSeverityWarning}
+	if err := alarms.CreateAlarm(second); err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := notifier.ListDeliveries(1, 10, repository.AlarmDeliveryFilter{AlarmID: second.ID}); total != 1 {
+		t.Errorf("关闭前入队的通知应投递完成, got %d", total)
+	}
+	notifier.NotifyAlarm(second, notify.EventAlarmCreated)
+	if _, total, _ := notifier.ListDeliveries(1, 10, repository.AlarmDeliveryFilter{AlarmID: second.ID}); total != 1 {
+		t.Errorf("关闭后的通知应被丢弃, got %d", total)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrRoleNotFound = errors.New("角色不存在")
+	ErrRoleBuiltIn  = errors.New("内置管理员角色不可修改")
+	ErrRoleInUse    = errors.New("角色仍有用户使用，不能删除")
+)
+
+// 角色名称只允许小写字母、数字、下划线和连字符
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// RoleInput 创建或更新角色 (更新时nil字段保持不变)
+type RoleInput struct {
+	Name        models.UserRole
+	Description *string
+	Permissions []string
+}
+
+// RoleService 角色与权限管理
+// 角色的权限集合缓存在内存中，鉴权时无需查询数据库
+type RoleService struct {
+	roleRepo    *repository.RoleRepository
+	userRepo    *repository.UserRepository
+	permissions map[models.UserRole]map[string]bool
+	mutex       sync.RWMutex
+}
+
+// NewRoleService 创建角色服务，并创建缺失的内置角色
+func NewRoleService(roleRepo *repository.RoleRepository, userRepo *repository.UserRepository) *RoleService {
+	s := &RoleService{
+		roleRepo:    roleRepo,
+		userRepo:    userRepo,
+		permissions: make(map[models.UserRole]map[string]bool),
+	}
+
+	for _, role := range models.BuiltInRoles() {
+		if _, err := roleRepo.FindByName(role.Name); err == nil {
+			continue
+		}
+		role := role
+		if err := roleRepo.Create(&role); err != nil {
+			log.Printf("⚠️  创建内置角色 %s 失败: %v", role.Name, err)
+			continue
+		}
+		log.Printf("✓ 已创建内置角色: %s", role.Name)
+	}
+
+	if err := s.reload(); err != nil {
+		log.Printf("⚠️  加载角色权限失败: %v", err)
+	}
+	return s
+}
+
+// reload 从数据库重新加载角色权限缓存
+func (s *RoleService) reload() error {
+	roles, err := s.roleRepo.List()
+	if err != nil {
+		return err
+	}
+	permissions := make(map[models.UserRole]map[string]bool, len(roles))
+	for _, role := range roles {
+		set := make(map[string]bool, len(role.Permissions))
+		for _, perm := range role.Permissions {
+			set[perm] = true
+		}
+		permissions[role.Name] = set
+	}
+
+	s.mutex.Lock()
+	s.permissions = permissions
+	s.mutex.Unlock()
+	return nil
+}
+
+// Exists 检查角色是否存在
+func (s *RoleService) Exists(name models.UserRole) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, ok := s.permissions[name]
+	return ok
+}
+
+// HasPermission 检查角色是否拥有指定权限 ("*" 拥有所有权限)
+func (s *RoleService) HasPermission(name models.UserRole, perm string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	set := s.permissions[name]
+	return set[models.PermAll] || set[perm]
+}
+
+// Permissions 获取角色的权限列表 (按名称排序)
+func (s *RoleService) Permissions(name models.UserRole) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	perms := make([]string, 0, len(s.permissions[name]))
+	for perm := range s.permissions[name] {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// ListRoles 获取所有角色
+func (s *RoleService) ListRoles() ([]models.Role, error) {
+	return s.roleRepo.List()
+}
+
+// GetRole 根据ID获取角色
+func (s *RoleService) GetRole(id uint) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole 创建自定义角色
+func (s *RoleService) CreateRole(input RoleInput) (*models.Role, error) {
+	name := models.UserRole(strings.TrimSpace(string(input.Name)))
+	if !roleNamePattern.MatchString(string(name)) {
+		return nil, errors.New("角色名称只能包含小写字母、数字、下划线和连字符 (2-50位，以字母开头)")
+	}
+	if s.Exists(name) {
+		return nil, fmt.Errorf("角色已存在: %s", name)
+	}
+	perms, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{Name: name, Permissions: perms}
+	if input.Description != nil {
+		role.Description = strings.TrimSpace(*input.Description)
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, s.reload()
+}
+
+// UpdateRole 更新角色描述和权限 (角色名称不可修改，内置管理员角色不可修改)
+func (s *RoleService) UpdateRole(id uint, input RoleInput) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if role.BuiltIn && role.Name == models.RoleAdmin {
+		return nil, ErrRoleBuiltIn
+	}
+
+	if input.Description != nil {
+		role.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Permissions != nil {
+		perms, err := normalizePermissions(input.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = perms
+	}
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+	return role, s.reload()
+}
+
+// DeleteRole 删除自定义角色 (内置角色和仍有用户使用的角色不可删除)
+func (s *RoleService) DeleteRole(id uint) error {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return ErrRoleNotFound
+	}
+	if role.BuiltIn {
+		return errors.New("内置角色不可删除")
+	}
+	count, err := s.userRepo.Count(map[string]interface{}{"role": role.Name})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// normalizePermissions 校验权限名称并去重排序
+func normalizePermissions(perms []string) (models.StringList, error) {
+	seen := make(map[string]bool, len(perms))
+	result := make(models.StringList, 0, len(perms))
+	for _, perm := range perms {
+		perm = strings.TrimSpace(perm)
+		if !models.IsValidPermission(perm) {
+			return nil, fmt.Errorf("无效的权限: %s", perm)
+		}
+		if seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		result = append(result, perm)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"testing"
+)
+
+func TestRolePermissions(t *testing.T) {
+	db, alice := newTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	roles := NewRoleService(repository.NewRoleRepository(db), userRepo)
+
+	// 内置角色
+	if !roles.HasPermission(models.RoleAdmin, models.PermRolesManage) {
+		t.Error("管理员应拥有所有权限")
+	}
+	if !roles.HasPermission(models.RoleOperator, models.PermAlarmsResolve) || roles.HasPermission(models.RoleOperator, models.PermTopologyWrite) {
+		t.Error("运维人员应能解决告警但不能修改拓扑")
+	}
+	if roles.HasPermission("unknown", models.PermTasksRead) {
+		t.Error("不存在的角色不应拥有权限")
+	}
+
+	// 自定义角色
+	if _, err := roles.CreateRole(RoleInput{Name: "auditor", Permissions: []string{"alarms:fly"}}); err == nil {
+		t.Error("无效的权限应被拒绝")
+	}
+	role, err := roles.CreateRole(RoleInput{Name: "auditor", Permissions: []string{models.PermAlarmsRead, models.PermAlarmsRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(role.Permissions) != 1 || !roles.HasPermission("auditor", models.PermAlarmsRead) {
+		t.Errorf("权限应去重并生效: %v", role.Permissions)
+	}
+	if _, err := roles.UpdateRole(role.ID, RoleInput{Permissions: []string{models.PermTopologyRead}}); err != nil {
+		t.Fatal(err)
+	}
+	if roles.HasPermission("auditor", models.PermAlarmsRead) || !roles.HasPermission("auditor", models.PermTopologyRead) {
+		t.Error("更新后的权限应立即生效")
+	}
+
+	// 内置管理员角色不可修改，使用中的角色不可删除
+	admin, _ := repository.NewRoleRepository(db).FindByName(models.RoleAdmin)
+	if _, err := roles.UpdateRole(admin.ID, RoleInput{Permissions: []string{}}); !errors.Is(err, ErrRoleBuiltIn) {
+		t.Errorf("内置管理员角色应不可修改, got %v", err)
+	}
+	alice.Role = "auditor"
+	if err := userRepo.Update(alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.DeleteRole(role.ID); !errors.Is(err, ErrRoleInUse) {
+		t.Errorf("使用中的角色应不可删除, got %v", err)
+	}
+}
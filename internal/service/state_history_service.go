@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"go-backend/internal/algorithm/define"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+// 单次查询最多读取的记录数
+const maxStateRecords = 100000
+
+type StateHistoryService struct {
+	stateRepo *repository.StateRecordRepository
+}
+
+func NewStateHistoryService(stateRepo *repository.StateRecordRepository) *StateHistoryService {
+	return &StateHistoryService{
+		stateRepo: stateRepo,
+	}
+}
+
+// SaveStates 批量保存时隙状态指标
+func (s *StateHistoryService) SaveStates(states []*define.StateMetrics) error {
+	records := make([]models.StateRecord, 0, len(states))
+	for _, state := range states {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		records = append(records, models.StateRecord{
+			TimeSlot:  state.TimeSlot,
+			Timestamp: state.Timestamp,
+			Data:      string(data),
+		})
+	}
+	return s.stateRepo.CreateBatch(records)
+}
+
+// QueryStates 按时隙/时间范围查询时隙状态指标
+func (s *StateHistoryService) QueryStates(query define.HistoryQuery) ([]*define.StateMetrics, error) {
+	filters := map[string]interface{}{}
+	if query.FromSlot != nil {
+		filters["from_slot"] = *query.FromSlot
+	}
+	if query.ToSlot != nil {
+		filters["to_slot"] = *query.ToSlot
+	}
+	if query.From != nil {
+		filters["from"] = *query.From
+	}
+	if query.To != nil {
+		filters["to"] = *query.To
+	}
+
+	records, err := s.stateRepo.List(filters, maxStateRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*define.StateMetrics, 0, len(records))
+	for _, record := range records {
+		state := define.NewStateMetrics()
+		if err := json.Unmarshal([]byte(record.Data), state); err != nil {
+			continue // 跳过损坏的记录
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ClearStates 清除所有持久化的状态记录
+func (s *StateHistoryService) ClearStates() error {
+	return s.stateRepo.DeleteAll()
+}
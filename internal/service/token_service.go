@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/pkg/utils"
+	"log"
+	"sync"
+	"time"
+)
+
+// 过期吊销记录的清理间隔
+const tokenCleanupInterval = time.Hour
+
+var (
+	ErrTokenInvalid = errors.New("无效的令牌")
+	ErrTokenRevoked = errors.New("令牌已失效，请重新登录")
+	ErrTokenReused  = errors.New("刷新令牌已被使用，该会话已注销，请重新登录")
+)
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // 访问令牌过期时间（秒）
+}
+
+// TokenService 令牌签发与吊销
+// 吊销记录和用户的令牌版本持久化到数据库，并在内存中缓存，认证时无需查询数据库
+type TokenService struct {
+	tokenRepo *repository.TokenRepository
+	userRepo  *repository.UserRepository
+	revoked   map[models.RevocationKind]map[string]models.TokenRevocation
+	versions  map[uint]uint // 用户ID -> 当前令牌版本 (只缓存大于0的版本)
+	mutex     sync.RWMutex
+	done      chan struct{}
+}
+
+func NewTokenService(tokenRepo *repository.TokenRepository, userRepo *repository.UserRepository) *TokenService {
+	s := &TokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		revoked: map[models.RevocationKind]map[string]models.TokenRevocation{
+			models.RevokeToken:  {},
+			models.RevokeFamily: {},
+		},
+		done: make(chan struct{}),
+	}
+
+	revocations, err := tokenRepo.ListActive(time.Now())
+	if err != nil {
+		log.Printf("⚠️  加载令牌吊销记录失败: %v", err)
+	}
+	for _, revocation := range revocations {
+		if cache, ok := s.revoked[revocation.Kind]; ok {
+			cache[revocation.Value] = revocation
+		}
+	}
+	if s.versions, err = userRepo.ListTokenVersions(); err != nil {
+		log.Printf("⚠️  加载用户令牌版本失败: %v", err)
+		s.versions = make(map[uint]uint)
+	}
+
+	go s.cleanupLoop()
+	return s
+}
+
+// Close 停止过期吊销记录的清理协程
+func (s *TokenService) Close() {
+	close(s.done)
+}
+
+// IssueTokens 为用户签发新会话的令牌对 (登录)
+func (s *TokenService) IssueTokens(user *models.User) (*TokenPair, error) {
+	s.mutex.RLock()
+	version := s.versions[user.ID]
+	s.mutex.RUnlock()
+	return s.issue(user, utils.NewTokenID(), version)
+}
+
+// issue 在指定会话中签发令牌对，令牌携带用户当前的令牌版本
+func (s *TokenService) issue(user *models.User, family string, version uint) (*TokenPair, error) {
+	accessToken, _, err := utils.IssueToken(user.ID, user.Username, string(user.Role), utils.TokenTypeAccess, family, version)
+	if err != nil {
+		return nil, errors.New("生成访问令牌失败")
+	}
+	refreshToken, _, err := utils.IssueToken(user.ID, user.Username, string(user.Role), utils.TokenTypeRefresh, family, version)
+	if err != nil {
+		return nil, errors.New("生成刷新令牌失败")
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// Validate 检查令牌是否已被吊销
+func (s *TokenService) Validate(claims *utils.Claims) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.checkLocked(claims)
+}
+
+// checkLocked 检查令牌本身和所属会话的吊销记录，以及令牌版本是否低于用户当前版本 (调用方持有锁)
+func (s *TokenService) checkLocked(claims *utils.Claims) error {
+	if _, revoked := s.revoked[models.RevokeToken][claims.ID]; revoked && claims.ID != "" {
+		return ErrTokenRevoked
+	}
+	if _, revoked := s.revoked[models.RevokeFamily][claims.Family]; revoked && claims.Family != "" {
+		return ErrTokenRevoked
+	}
+	if claims.Version < s.versions[claims.UserID] {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌对 (刷新令牌只能使用一次)
+// 已轮换的刷新令牌再次出现说明令牌可能已泄露，吊销整个会话
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	claims, err := utils.ParseToken(refreshToken)
+	if err != nil || claims.TokenType != utils.TokenTypeRefresh {
+		return nil, ErrTokenInvalid
+	}
+	if claims.ID == "" || claims.Family == "" {
+		return nil, ErrTokenRevoked // 旧版本签发的令牌不支持轮换
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if revocation, used := s.revoked[models.RevokeToken][claims.ID]; used && revocation.Reason == models.RevokeReasonRotated {
+		log.Printf("⚠️  检测到刷新令牌重复使用 (用户:%d, 会话:%s)，吊销该会话", claims.UserID, claims.Family)
+		if err := s.revokeLocked(models.RevokeFamily, claims.Family, claims.UserID, models.RevokeReasonReused, claims.ExpiresAt.Time); err != nil {
+			log.Printf("❌ 吊销会话失败: %v", err)
+		}
+		return nil, ErrTokenReused
+	}
+	if err := s.checkLocked(claims); err != nil {
+		return nil, err
+	}
+
+	// 重新读取用户，角色变更后签发的令牌使用新角色
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, ErrTokenRevoked
+	}
+
+	if err := s.revokeLocked(models.RevokeToken, claims.ID, claims.UserID, models.RevokeReasonRotated, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	return s.issue(user, claims.Family, s.versions[user.ID])
+}
+
+// Logout 退出当前会话 (吊销同一会话签发的访问令牌和刷新令牌)
+func (s *TokenService) Logout(claims *utils.Claims) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiresAt := time.Now().Add(utils.RefreshTokenTTL())
+	if claims.Family == "" {
+		// 旧版本签发的令牌没有会话ID，只吊销该令牌
+		return s.revokeLocked(models.RevokeToken, claims.ID, claims.UserID, models.RevokeReasonLogout, expiresAt)
+	}
+	return s.revokeLocked(models.RevokeFamily, claims.Family, claims.UserID, models.RevokeReasonLogout, expiresAt)
+}
+
+// LogoutAll 退出用户的所有会话 (递增令牌版本，此前签发的所有令牌失效)
+func (s *TokenService) LogoutAll(userID uint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	version, err := s.userRepo.IncrementTokenVersion(userID)
+	if err != nil {
+		return errors.New("更新令牌版本失败")
+	}
+	s.versions[userID] = version
+	return nil
+}
+
+// revokeLocked 保存吊销记录并更新缓存 (调用方持有写锁)
+func (s *TokenService) revokeLocked(kind models.RevocationKind, value string, userID uint, reason string, expiresAt time.Time) error {
+	revocation := models.TokenRevocation{
+		CreatedAt: time.Now(),
+		Kind:      kind,
+		Value:     value,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.tokenRepo.Save(&revocation); err != nil {
+		return errors.New("保存令牌吊销记录失败")
+	}
+	s.revoked[kind][value] = revocation
+	return nil
+}
+
+// Cleanup 清理已过期的吊销记录
+func (s *TokenService) Cleanup(now time.Time) {
+	s.mutex.Lock()
+	for _, revocations := range s.revoked {
+		for value, revocation := range revocations {
+			if !revocation.ExpiresAt.After(now) {
+				delete(revocations, value)
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	if count, err := s.tokenRepo.DeleteExpired(now); err != nil {
+		log.Printf("⚠️  清理令牌吊销记录失败: %v", err)
+	} else if count > 0 {
+		log.Printf("✓ 已清理 %d 条过期的令牌吊销记录", count)
+	}
+}
+
+func (s *TokenService) cleanupLoop() {
+	ticker := time.NewTicker(tokenCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.Cleanup(now)
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/pkg/utils"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建内存数据库并创建一个普通用户
+func newTestDB(t *testing.T) (*gorm.DB, *models.User) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // 内存数据库每个连接独立
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.User{}, &models.TokenRevocation{}, &models.Role{}); err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, user
+}
+
+// newTestTokenService 使用内存数据库创建令牌服务
+func newTestTokenService(t *testing.T) (*TokenService, *models.User) {
+	t.Helper()
+	db, user := newTestDB(t)
+	utils.InitJWTSecret("test-secret")
+	s := NewTokenService(repository.NewTokenRepository(db), repository.NewUserRepository(db))
+	t.Cleanup(s.Close)
+	return s, user
+}
+
+func parseClaims(t *testing.T, token string) *utils.Claims {
+	t.Helper()
+	claims, err := utils.ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestTokenRefreshRotation(t *testing.T) {
+	s, user := newTestTokenService(t)
+
+	first, err := s.IssueTokens(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parseClaims(t, second.RefreshToken).Family != parseClaims(t, first.RefreshToken).Family {
+		t.Error("刷新后的令牌应属于同一会话")
+	}
+
+	// 重复使用已轮换的刷新令牌: 拒绝并吊销整个会话
+	if _, err := s.Refresh(first.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("重复使用刷新令牌应被检测, got %v", err)
+	}
+	if _, err := s.Refresh(second.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("会话吊销后最新的刷新令牌也应失效, got %v", err)
+	}
+	if err := s.Validate(parseClaims(t, second.AccessToken)); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("会话吊销后访问令牌应失效, got %v", err)
+	}
+
+	// 其他会话不受影响
+	other, err := s.IssueTokens(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(parseClaims(t, other.AccessToken)); err != nil {
+		t.Errorf("其他会话不应受影响: %v", err)
+	}
+}
+
+func TestTokenLogout(t *testing.T) {
+	s, user := newTestTokenService(t)
+
+	a, _ := s.IssueTokens(user)
+	b, _ := s.IssueTokens(user)
+	if err := s.Logout(parseClaims(t, a.AccessToken)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(parseClaims(t, a.AccessToken)); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("退出登录后访问令牌应失效, got %v", err)
+	}
+	if _, err := s.Refresh(a.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("退出登录后刷新令牌应失效, got %v", err)
+	}
+	if err := s.Validate(parseClaims(t, b.AccessToken)); err != nil {
+		t.Errorf("退出登录不应影响其他会话: %v", err)
+	}
+
+	if err := s.LogoutAll(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(parseClaims(t, b.AccessToken)); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("退出所有设备后之前的令牌应失效, got %v", err)
+	}
+	// 随即签发的令牌 (可能与吊销在同一时刻) 使用新版本，不受影响
+	c, _ := s.IssueTokens(user)
+	if err := s.Validate(parseClaims(t, c.AccessToken)); err != nil {
+		t.Errorf("退出所有设备后新签发的令牌应有效: %v", err)
+	}
+
+	// 吊销记录和令牌版本持久化: 重新创建服务后仍然有效
+	restarted := NewTokenService(s.tokenRepo, s.userRepo)
+	defer restarted.Close()
+	if err := restarted.Validate(parseClaims(t, b.AccessToken)); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("重启后吊销记录应保留, got %v", err)
+	}
+	if _, err := restarted.Refresh(c.RefreshToken); err != nil {
+		t.Errorf("重启后新版本的刷新令牌应有效: %v", err)
+	}
+
+	// 过期的吊销记录被清理
+	restarted.Cleanup(time.Now().Add(utils.RefreshTokenTTL() + time.Minute))
+	if active, _ := restarted.tokenRepo.ListActive(time.Now()); len(active) != 0 {
+		t.Errorf("过期的吊销记录应被清理, 剩余 %d 条", len(active))
+	}
+}
@@ -1,27 +1,112 @@
 package service
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"go-backend/internal/models"
 	"go-backend/internal/repository"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserNotFound  = errors.New("用户不存在")
+	ErrUserDisabled  = errors.New("账号已禁用")
+	ErrWrongPassword = errors.New("原密码错误")
+)
+
+// bcrypt 只使用密码的前72字节
+const maxPasswordLength = 72
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength     int  // 最小长度
+	RequireLetter bool // 必须包含字母
+	RequireDigit  bool // 必须包含数字
+	RequireSymbol bool // 必须包含特殊字符
+}
+
+// DefaultPasswordPolicy 默认密码策略: 至少8位，包含字母和数字
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 8, RequireLetter: true, RequireDigit: true}
+}
+
+// Validate 检查密码是否符合策略
+func (p PasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", p.MinLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("密码长度不能超过%d字节", maxPasswordLength)
+	}
+
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsSpace(r):
+			return errors.New("密码不能包含空白字符")
+		default:
+			hasSymbol = true
+		}
+	}
+	if p.RequireLetter && !hasLetter {
+		return errors.New("密码必须包含字母")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errors.New("密码必须包含数字")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return errors.New("密码必须包含特殊字符")
+	}
+	return nil
+}
+
+// UpdateUserInput 用户信息更新 (nil字段保持不变)
+type UpdateUserInput struct {
+	Email *string
+	Role  *models.UserRole
+}
+
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo    *repository.UserRepository
+	roleService *RoleService
+	policy      PasswordPolicy
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, roleService *RoleService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+		policy:      DefaultPasswordPolicy(),
 	}
 }
 
+// SetPasswordPolicy 设置密码策略
+func (s *UserService) SetPasswordPolicy(policy PasswordPolicy) {
+	s.policy = policy
+}
+
 func (s *UserService) CreateUser(user *models.User) error {
 	if user == nil {
 		return errors.New("user cannot be nil")
 	}
+	if user.Role != "" && !s.roleService.Exists(user.Role) {
+		return fmt.Errorf("无效的用户角色: %s", user.Role)
+	}
+
+	// 校验密码策略
+	if err := s.policy.Validate(user.Password); err != nil {
+		return err
+	}
 
 	// 对密码进行加密
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -46,6 +131,10 @@ func (s *UserService) ValidateUser(username, password string) (*models.User, err
 		return nil, errors.New("用户名或密码错误")
 	}
 
+	if !user.IsActive() {
+		return nil, ErrUserDisabled
+	}
+
 	return user, nil
 }
 
@@ -68,3 +157,132 @@ func (s *UserService) IsAdmin(userID uint) (bool, error) {
 	}
 	return user.Role == models.RoleAdmin, nil
 }
+
+// UpdateUser 更新用户邮箱和角色
+func (s *UserService) UpdateUser(id uint, input UpdateUserInput) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		if email == "" {
+			return nil, errors.New("邮箱不能为空")
+		}
+		exists, err := s.userRepo.ExistsEmail(email, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("邮箱已被使用")
+		}
+		user.Email = email
+	}
+	if input.Role != nil {
+		if !s.roleService.Exists(*input.Role) {
+			return nil, fmt.Errorf("无效的用户角色: %s", *input.Role)
+		}
+		user.Role = *input.Role
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserStatus 启用或禁用用户
+func (s *UserService) SetUserStatus(id uint, status models.UserStatus) (*models.User, error) {
+	if status != models.UserStatusActive && status != models.UserStatusDisabled {
+		return nil, fmt.Errorf("无效的用户状态: %s", status)
+	}
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.Status = status
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser 软删除用户
+func (s *UserService) DeleteUser(id uint) error {
+	if _, err := s.userRepo.FindByID(id); err != nil {
+		return ErrUserNotFound
+	}
+	return s.userRepo.SoftDelete(id, time.Now())
+}
+
+// ChangePassword 用户修改自己的密码 (需验证原密码)
+func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) error {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return ErrWrongPassword
+	}
+	if oldPassword == newPassword {
+		return errors.New("新密码不能与原密码相同")
+	}
+	return s.setPassword(id, newPassword)
+}
+
+// ResetPassword 管理员重置用户密码，newPassword为空时生成符合策略的随机密码并返回
+func (s *UserService) ResetPassword(id uint, newPassword string) (string, error) {
+	if _, err := s.userRepo.FindByID(id); err != nil {
+		return "", ErrUserNotFound
+	}
+	if newPassword == "" {
+		newPassword = s.generatePassword()
+	}
+	if err := s.setPassword(id, newPassword); err != nil {
+		return "", err
+	}
+	return newPassword, nil
+}
+
+// setPassword 校验密码策略并保存密码哈希
+func (s *UserService) setPassword(id uint, password string) error {
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdatePassword(id, string(hashedPassword), time.Now())
+}
+
+// generatePassword 生成符合密码策略的随机密码 (包含字母、数字和特殊字符)
+func (s *UserService) generatePassword() string {
+	const (
+		letters = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+		digits  = "23456789"
+		symbols = "!@#$%^&*-_+="
+	)
+	length := s.policy.MinLength
+	if length < 12 {
+		length = 12
+	}
+
+	pick := func(charset string) byte {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		return charset[n.Int64()]
+	}
+	password := []byte{pick(letters), pick(digits), pick(symbols)}
+	all := letters + digits + symbols
+	for len(password) < length {
+		password = append(password, pick(all))
+	}
+	// 打乱顺序，避免固定的字符类别位置
+	for i := len(password) - 1; i > 0; i-- {
+		j, _ := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	for password, valid := range map[string]bool{
+		"abc123":                 false, // 太短
+		"abcdefgh":               false, // 没有数字
+		"12345678":               false, // 没有字母
+		"abcd 1234":              false, // 包含空白
+		"abcd1234":               true,
+		strings.Repeat("a1", 37): false, // 超过bcrypt上限
+	} {
+		if err := policy.Validate(password); (err == nil) != valid {
+			t.Errorf("密码 %q: 期望有效=%v, got %v", password, valid, err)
+		}
+	}
+
+	policy.RequireSymbol = true
+	if err := policy.Validate("abcd1234"); err == nil {
+		t.Error("要求特殊字符时应拒绝不含特殊字符的密码")
+	}
+}
+
+func TestUserPasswordLifecycle(t *testing.T) {
+	db, _ := newTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	s := NewUserService(userRepo, NewRoleService(repository.NewRoleRepository(db), userRepo))
+
+	if err := s.CreateUser(&models.User{Username: "bob", Email: "bob@example.com", Password: "short"}); err == nil {
+		t.Fatal("不符合密码策略的用户应创建失败")
+	}
+	user := &models.User{Username: "bob", Email: "bob@example.com", Password: "bobpass123"}
+	if err := s.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	// 密码哈希不出现在序列化结果中
+	data, _ := json.Marshal(user)
+	if strings.Contains(string(data), user.Password) || strings.Contains(string(data), "password\"") {
+		t.Errorf("序列化结果不应包含密码哈希: %s", data)
+	}
+
+	if err := s.ChangePassword(user.ID, "wrong-pass1", "newpass456"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("原密码错误时应拒绝, got %v", err)
+	}
+	if err := s.ChangePassword(user.ID, "bobpass123", "newpass456"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateUser("bob", "newpass456"); err != nil {
+		t.Errorf("修改后应使用新密码登录: %v", err)
+	}
+
+	password, err := s.ResetPassword(user.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateUser("bob", password); err != nil {
+		t.Errorf("应使用生成的随机密码登录: %v", err)
+	}
+
+	if _, err := s.SetUserStatus(user.ID, models.UserStatusDisabled); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateUser("bob", password); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("禁用的用户不能登录, got %v", err)
+	}
+
+	if err := s.DeleteUser(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetUserByID(user.ID); err == nil {
+		t.Error("删除的用户不应再被查询到")
+	}
+}
@@ -45,6 +45,15 @@ func migrateDB() {
 		&models.Node{},
 		&models.Link{},
 		&models.Alarm{},
+		&models.AlarmRule{},
+		&models.AlarmNote{},
+		&models.AlarmDelivery{},
+		&models.AlarmSilence{},
+		&models.StateRecord{},
+		&models.TokenRevocation{},
+		&models.Role{},
+		&models.APIKey{},
+		&models.AuditLog{},
 	)
 	if err != nil {
 		log.Fatalf("数据库迁移失败: %v", err)
@@ -134,7 +143,12 @@ func importTestData() {
 
 	// 导入用户数据，需要特殊处理密码加密
 	var userData struct {
-		Users []models.User `json:"users"`
+		Users []struct {
+			Username string          `json:"username"`
+			Email    string          `json:"email"`
+			Role     models.UserRole `json:"role"`
+			Password string          `json:"password"`
+		} `json:"users"`
 	}
 	if err := loadJSONFile("users.json", &userData); err != nil {
 		log.Printf("加载用户数据失败: %v", err)
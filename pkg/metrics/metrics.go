@@ -0,0 +1,35 @@
+// Package metrics Prometheus监控指标注册表 (基于 prometheus/client_golang)
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 本服务自定义指标的命名空间前缀
+const Namespace = "gobackend"
+
+// SlotBuckets 时隙调度耗时的直方图桶 (秒)
+var SlotBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Default 默认注册表 (包含Go运行时和进程指标)
+var Default = prometheus.NewRegistry()
+
+// Factory 在默认注册表中创建并注册指标
+var Factory = promauto.With(Default)
+
+func init() {
+	Default.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler 导出默认注册表中指标的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
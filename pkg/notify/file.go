@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File 将通知以JSONL格式追加到本地文件 (每条通知一行)
+type File struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFile 创建文件渠道，目录不存在时在首次写入时创建
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Send 追加一行JSON
+func (f *File) Send(ctx context.Context, n *Notification) error {
+	line, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
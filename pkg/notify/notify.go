@@ -0,0 +1,146 @@
+// Package notify 告警通知渠道 (HTTP webhook、SMTP邮件、JSONL文件)
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 通知事件
+const (
+	EventAlarmCreated  = "alarm.created"  // 产生告警
+	EventAlarmResolved = "alarm.resolved" // 告警已解决
+	EventTest          = "test"           // 测试通知 (管理接口手动发送)
+)
+
+// 渠道类型
+const (
+	TypeWebhook = "webhook"
+	TypeSMTP    = "smtp"
+	TypeFile    = "file"
+)
+
+// Notification 告警通知内容 (webhook和文件渠道以JSON格式发送)
+type Notification struct {
+	Event       string    `json:"event"`
+	AlarmID     uint      `json:"alarm_id"`
+	Name        string    `json:"name"`
+	EventType   string    `json:"event_type"`
+	Severity    string    `json:"severity"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Channel 通知渠道
+type Channel interface {
+	Send(ctx context.Context, n *Notification) error
+}
+
+// SMTPConfig SMTP邮件服务器配置
+type SMTPConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`     // 默认25
+	Username string   `yaml:"username"` // 为空时不认证
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// ChannelConfig 通知渠道配置
+type ChannelConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // webhook | smtp | file
+
+	// 路由规则 (为空表示全部)
+	EventTypes   []string `yaml:"event_types"`   // 告警事件类型
+	Severities   []string `yaml:"severities"`    // 告警级别
+	SendResolved bool     `yaml:"send_resolved"` // 告警解决时是否通知
+
+	// 投递策略
+	Retries int    `yaml:"retries"` // 失败后的重试次数
+	Backoff string `yaml:"backoff"` // 首次重试间隔 (之后每次翻倍)，默认1s
+	Timeout string `yaml:"timeout"` // 单次发送超时，默认10s
+
+	// webhook
+	URL     string            `yaml:"url"`
+	Secret  string            `yaml:"secret"`  // HMAC-SHA256签名密钥 (为空时不签名)
+	Headers map[string]string `yaml:"headers"` // 附加请求头
+
+	// smtp
+	SMTP SMTPConfig `yaml:"smtp"`
+
+	// file
+	Path string `yaml:"path"` // JSONL文件路径 (每条通知一行)
+}
+
+// Matches 检查通知是否应发送到该渠道
+func (c *ChannelConfig) Matches(n *Notification) bool {
+	if n.Event == EventTest {
+		return true
+	}
+	if n.Event == EventAlarmResolved && !c.SendResolved {
+		return false
+	}
+	return matchList(c.EventTypes, n.EventType) && matchList(c.Severities, n.Severity)
+}
+
+func matchList(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Target 渠道的发送目标 (用于展示，不含密钥)
+func (c *ChannelConfig) Target() string {
+	switch c.Type {
+	case TypeWebhook:
+		return c.URL
+	case TypeSMTP:
+		return fmt.Sprintf("%s:%d -> %v", c.SMTP.Host, c.smtpPort(), c.SMTP.To)
+	case TypeFile:
+		return c.Path
+	}
+	return ""
+}
+
+func (c *ChannelConfig) smtpPort() int {
+	if c.SMTP.Port == 0 {
+		return 25
+	}
+	return c.SMTP.Port
+}
+
+// New 按配置创建通知渠道
+func New(c ChannelConfig) (Channel, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("通知渠道名称不能为空")
+	}
+	switch c.Type {
+	case TypeWebhook:
+		if c.URL == "" {
+			return nil, fmt.Errorf("webhook渠道 %s 未配置url", c.Name)
+		}
+		return NewWebhook(c.URL, c.Secret, c.Headers), nil
+	case TypeSMTP:
+		if c.SMTP.Host == "" || c.SMTP.From == "" || len(c.SMTP.To) == 0 {
+			return nil, fmt.Errorf("smtp渠道 %s 需要配置host、from和to", c.Name)
+		}
+		smtp := c.SMTP
+		smtp.Port = c.smtpPort()
+		return NewSMTP(smtp), nil
+	case TypeFile:
+		if c.Path == "" {
+			return nil, fmt.Errorf("file渠道 %s 未配置path", c.Name)
+		}
+		return NewFile(c.Path), nil
+	}
+	return nil, fmt.Errorf("通知渠道 %s 的类型无效: %q", c.Name, c.Type)
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	received := make(chan Notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Sign("s3cret", r.Header.Get(HeaderTimestamp), body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Team") != "ops" {
+			http.Error(w, "missing header", http.StatusBadRequest)
+			return
+		}
+		var n Notification
+		json.Unmarshal(body, &n)
+		received <- n
+	}))
+	defer server.Close()
+
+	n := &Notification{Event: EventAlarmCreated, AlarmID: 7, Name: "队列积压", Severity: "critical", Timestamp: time.Now()}
+	hook := NewWebhook(server.URL, "s3cret", map[string]string{"X-Team": "ops"})
+	if err := hook.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got.AlarmID != 7 || got.Name != "队列积压" {
+		t.Errorf("收到的通知不正确: %+v", got)
+	}
+
+	// 密钥不一致时接收方拒绝，非2xx视为发送失败
+	if err := NewWebhook(server.URL, "wrong", map[string]string{"X-Team": "ops"}).Send(context.Background(), n); err == nil {
+		t.Error("签名错误时应返回错误")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "alarms.jsonl")
+	sink := NewFile(path)
+	for _, event := range []string{EventAlarmCreated, EventAlarmResolved} {
+		if err := sink.Send(context.Background(), &Notification{Event: event, AlarmID: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var events []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var n Notification
+		if err := json.Unmarshal(scanner.Bytes(), &n); err != nil {
+			t.Fatalf("每行应为一条JSON: %v", err)
+		}
+		events = append(events, n.Event)
+	}
+	if len(events) != 2 || events[1] != EventAlarmResolved {
+		t.Errorf("文件内容不正确: %v", events)
+	}
+}
+
+func TestChannelMatches(t *testing.T) {
+	c := ChannelConfig{EventTypes: []string{"network"}, Severities: []string{"warning", "critical"}}
+	cases := []struct {
+		n    Notification
+		want bool
+	}{
+		{Notification{Event: EventAlarmCreated, EventType: "network", Severity: "critical"}, true},
+		{Notification{Event: EventAlarmCreated, EventType: "network", Severity: "info"}, false},
+		{Notification{Event: EventAlarmCreated, EventType: "hardware", Severity: "critical"}, false},
+		{Notification{Event: EventAlarmResolved, EventType: "network", Severity: "critical"}, false},
+		{Notification{Event: EventTest}, true},
+	}
+	for _, tc := range cases {
+		if got := c.Matches(&tc.n); got != tc.want {
+			t.Errorf("Matches(%+v) = %v, 期望 %v", tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP 通过SMTP服务器发送邮件通知 (服务器支持时使用STARTTLS)
+type SMTP struct {
+	config SMTPConfig
+}
+
+// NewSMTP 创建邮件渠道
+func NewSMTP(config SMTPConfig) *SMTP {
+	return &SMTP{config: config}
+}
+
+// Send 发送一封邮件到所有收件人
+func (s *SMTP) Send(ctx context.Context, n *Notification) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+			return err
+		}
+	}
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.config.From); err != nil {
+		return err
+	}
+	for _, to := range s.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.message(n)); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message 构造邮件内容 (UTF-8纯文本)
+func (s *SMTP) message(n *Notification) []byte {
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(n.Severity), n.Name)
+	switch n.Event {
+	case EventAlarmResolved:
+		subject = "[已解决] " + n.Name
+	case EventTest:
+		subject = "[测试] " + n.Name
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	fmt.Fprintf(&buf, "告警: %s (ID: %d)\r\n", n.Name, n.AlarmID)
+	fmt.Fprintf(&buf, "事件: %s\r\n", n.Event)
+	fmt.Fprintf(&buf, "类型: %s\r\n级别: %s\r\n状态: %s\r\n", n.EventType, n.Severity, n.Status)
+	fmt.Fprintf(&buf, "时间: %s\r\n\r\n", n.Timestamp.Format(time.RFC3339))
+	buf.WriteString(strings.ReplaceAll(n.Description, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook请求头
+const (
+	HeaderTimestamp = "X-Alarm-Timestamp" // 发送时间 (Unix秒)
+	HeaderSignature = "X-Alarm-Signature" // "sha256=" + hex(HMAC-SHA256(secret, 时间戳 + "." + 请求体))
+)
+
+// Webhook 以JSON POST通知到HTTP地址，配置密钥时附带HMAC签名
+type Webhook struct {
+	url     string
+	secret  string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhook 创建webhook渠道 (超时由Send的ctx控制)
+func NewWebhook(url, secret string, headers map[string]string) *Webhook {
+	return &Webhook{
+		url:     url,
+		secret:  secret,
+		headers: headers,
+		client:  &http.Client{},
+	}
+}
+
+// Sign 计算请求签名，接收方用相同密钥重新计算并比较 (应同时校验时间戳防止重放)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send 发送通知，非2xx响应视为失败
+func (w *Webhook) Send(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-backend-notifier")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("webhook返回 %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return nil
+}
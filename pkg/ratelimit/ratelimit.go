@@ -0,0 +1,101 @@
+// Package ratelimit 提供按键隔离的令牌桶限流器
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 清理已补满令牌的空闲桶的间隔
+const sweepInterval = time.Minute
+
+// Limit 令牌桶参数
+type Limit struct {
+	Rate  float64 `yaml:"rate" json:"rate"`   // 每秒补充的令牌数 (持续速率)
+	Burst int     `yaml:"burst" json:"burst"` // 桶容量 (允许的突发请求数)
+}
+
+// Enabled 是否启用限流 (速率和容量均大于0)
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// bucket 单个键的令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter 按键 (IP、用户、接口等) 维护独立令牌桶的限流器，并发安全
+type Limiter struct {
+	limit     Limit
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mutex     sync.Mutex
+	now       func() time.Time
+}
+
+// New 创建限流器
+func New(limit Limit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Limit 限流参数
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Allow 为键消耗一个令牌，令牌不足时返回false和需要等待的时间
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if !l.limit.Enabled() {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweepLocked(now)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+// Len 当前维护的令牌桶数量
+func (l *Limiter) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.buckets)
+}
+
+// sweepLocked 定期删除已补满的桶 (与新建的桶等价，避免键无限增长)
+func (l *Limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(float64(l.limit.Burst) / l.limit.Rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
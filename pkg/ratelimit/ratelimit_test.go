@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := New(Limit{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	// 突发容量内全部放行，之后拒绝并给出等待时间
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("第%d个请求应在突发容量内", i+1)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("令牌耗尽时应拒绝并等待500ms, got ok=%v wait=%v", ok, wait)
+	}
+
+	// 不同的键互不影响
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("其他键应有独立的令牌桶")
+	}
+
+	// 按速率补充令牌
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("补充令牌后应放行")
+	}
+
+	// 补满后的空闲桶被清理
+	now = now.Add(2 * time.Minute)
+	l.Allow("c")
+	if l.Len() != 1 {
+		t.Errorf("空闲桶应被清理, got %d", l.Len())
+	}
+
+	if ok, _ := New(Limit{}).Allow("a"); !ok {
+		t.Error("未配置限流时应全部放行")
+	}
+}
@@ -1,60 +1,109 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret []byte
+// 令牌类型
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+var (
+	jwtSecret []byte
+
+	// 令牌有效期 (启动时由配置文件覆盖)
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
 
 // InitJWTSecret 初始化 JWT 密钥
 func InitJWTSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
+// SetTokenExpiration 设置访问令牌和刷新令牌的有效期
+func SetTokenExpiration(access, refresh time.Duration) error {
+	if access <= 0 || refresh <= 0 {
+		return errors.New("令牌有效期必须为正数")
+	}
+	if refresh < access {
+		return errors.New("刷新令牌有效期不能短于访问令牌")
+	}
+	accessTokenTTL, refreshTokenTTL = access, refresh
+	return nil
+}
+
+// AccessTokenTTL 访问令牌有效期
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// RefreshTokenTTL 刷新令牌有效期
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
+}
+
+// Claims 令牌声明，RegisteredClaims.ID 为令牌唯一标识 (JTI，用于吊销)
 type Claims struct {
 	UserID    uint   `json:"user_id"`
 	Username  string `json:"username"`
 	Role      string `json:"role"`
-	TokenType string `json:"token_type"` // 新增：标识令牌类型
+	TokenType string `json:"token_type"`       // 新增：标识令牌类型
+	Family    string `json:"family,omitempty"` // 会话ID: 同一次登录及其后续刷新签发的令牌相同
+	Version   uint   `json:"ver,omitempty"`    // 签发时用户的令牌版本 (退出所有设备后递增)
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成访问令牌
-func GenerateToken(userID uint, username, role string) (string, error) {
-	return generateTokenWithType(userID, username, role, "access", 24*time.Hour)
-}
-
-// GenerateRefreshToken 生成刷新令牌
-func GenerateRefreshToken(userID uint, username, role string) (string, error) {
-	return generateTokenWithType(userID, username, role, "refresh", 7*24*time.Hour)
-}
+// IssueToken 签发指定类型的令牌，返回令牌及其声明
+func IssueToken(userID uint, username, role, tokenType, family string, version uint) (string, *Claims, error) {
+	expiration := accessTokenTTL
+	if tokenType == TokenTypeRefresh {
+		expiration = refreshTokenTTL
+	}
 
-// generateTokenWithType 根据类型生成令牌
-func generateTokenWithType(userID uint, username, role, tokenType string, expiration time.Duration) (string, error) {
-	claims := Claims{
+	now := time.Now()
+	claims := &Claims{
 		UserID:    userID,
 		Username:  username,
 		Role:      role,
 		TokenType: tokenType,
+		Family:    family,
+		Version:   version,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        NewTokenID(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// NewTokenID 生成随机的令牌/会话ID
+func NewTokenID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 // ParseToken 解析JWT token
 func ParseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PropertyFloat 读取节点/链路属性中的数值 (兼容JSON数字和数字字符串)
+func PropertyFloat(props map[string]interface{}, key string) (float64, bool) {
+	if props == nil {
+		return 0, false
+	}
+	switch v := props[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// PropertyBool 读取节点/链路属性中的布尔值 (兼容 true/"true"/1)
+func PropertyBool(props map[string]interface{}, key string) bool {
+	if props == nil {
+		return false
+	}
+	switch v := props[key].(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return b
+	}
+	return false
+}
+
+// ParseDistance 解析距离属性，统一换算为米
+// 支持数字 (默认单位米) 以及 "400m"、"5km" 形式的字符串
+func ParseDistance(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, v > 0
+	case int:
+		return float64(v), v > 0
+	case string:
+		s := strings.ToLower(strings.TrimSpace(v))
+		scale := 1.0
+		switch {
+		case strings.HasSuffix(s, "km"):
+			s, scale = strings.TrimSuffix(s, "km"), 1000
+		case strings.HasSuffix(s, "m"):
+			s = strings.TrimSuffix(s, "m")
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || f <= 0 {
+			return 0, false
+		}
+		return f * scale, true
+	}
+	return 0, false
+}
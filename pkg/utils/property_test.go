@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestParseDistance(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  float64
+		ok    bool
+	}{
+		{400.0, 400, true},
+		{250, 250, true},
+		{"400", 400, true},
+		{"400m", 400, true},
+		{" 1.5KM ", 1500, true},
+		{"2 km", 2000, true},
+		{0.0, 0, false},
+		{-10, 0, false},
+		{"-5m", 0, false},
+		{"abc", 0, false},
+		{"m", 0, false},
+		{"", 0, false},
+		{nil, 0, false},
+		{true, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDistance(tt.value)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseDistance(%#v) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
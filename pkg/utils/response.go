@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,22 +31,24 @@ type PageResult struct {
 
 // 预定义业务状态码
 const (
-	SUCCESS          = 0
-	ERROR            = -1
-	UNAUTHORIZED     = 40100
-	FORBIDDEN        = 40300
-	NOT_FOUND        = 40400
-	VALIDATION_ERROR = 40001
+	SUCCESS           = 0
+	ERROR             = -1
+	UNAUTHORIZED      = 40100
+	FORBIDDEN         = 40300
+	NOT_FOUND         = 40400
+	VALIDATION_ERROR  = 40001
+	TOO_MANY_REQUESTS = 42900
 )
 
 // 状态码对应的默认消息
 var codeMessages = map[int]string{
-	SUCCESS:          "操作成功",
-	ERROR:            "操作失败",
-	UNAUTHORIZED:     "未授权",
-	FORBIDDEN:        "权限不足",
-	NOT_FOUND:        "资源不存在",
-	VALIDATION_ERROR: "参数验证失败",
+	SUCCESS:           "操作成功",
+	ERROR:             "操作失败",
+	UNAUTHORIZED:      "未授权",
+	FORBIDDEN:         "权限不足",
+	NOT_FOUND:         "资源不存在",
+	VALIDATION_ERROR:  "参数验证失败",
+	TOO_MANY_REQUESTS: "请求过于频繁",
 }
 
 // Success 成功响应
@@ -100,6 +105,16 @@ func ErrorWithData(c *gin.Context, code int, msg string, data interface{}) {
 	})
 }
 
+// TooManyRequests 限流响应 (HTTP 429)，Retry-After 头为需要等待的秒数 (向上取整，至少1秒)
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, msg string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	Error(c, TOO_MANY_REQUESTS, msg)
+}
+
 // getHttpStatus 根据业务码获取对应的 HTTP 状态码
 func getHttpStatus(code int) int {
 	switch code {
@@ -111,6 +126,8 @@ func getHttpStatus(code int) int {
 		return http.StatusNotFound
 	case VALIDATION_ERROR:
 		return http.StatusBadRequest
+	case TOO_MANY_REQUESTS:
+		return http.StatusTooManyRequests
 	case SUCCESS:
 		return http.StatusOK
 	default:
@@ -8,8 +8,11 @@
 
 const BASE_URL = 'http://localhost:8080/api/v1';
 
-// 获取token
+// 获取token (设置 API_KEY 环境变量时直接使用API密钥，无需登录)
 async function getToken() {
+  if (process.env.API_KEY) {
+    return process.env.API_KEY;
+  }
   const resp = await fetch(`${BASE_URL}/auth/login`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },